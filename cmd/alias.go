@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// AliasCmd groups the alias subcommands
+type AliasCmd struct {
+	Add    AliasAddCmd    `cmd:"add" help:"Define an alias for an issue key, checking the issue exists"`
+	List   AliasListCmd   `cmd:"list" help:"List the defined aliases"`
+	Remove AliasRemoveCmd `cmd:"remove" help:"Delete an alias"`
+}
+
+// AliasAddCmd represents the alias add command
+type AliasAddCmd struct {
+	Name     string `arg:"" help:"Alias name (e.g., standup)"`
+	IssueKey string `arg:"" help:"Jira issue key the alias stands for (e.g., PROJ-123)"`
+}
+
+// AliasListCmd represents the alias list command
+type AliasListCmd struct{}
+
+// AliasRemoveCmd represents the alias remove command
+type AliasRemoveCmd struct {
+	Name string `arg:"" help:"Alias name"`
+}
+
+// resolveIssueKey returns the issue key an alias from the config file stands for, or key itself if it is not an alias
+func resolveIssueKey(key string) (string, error) {
+	if key == "" {
+		return key, nil
+	}
+
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return "", err
+	}
+
+	issueKey := config.ResolveIssueKey(key)
+	if issueKey != key {
+		log.Debugf("Alias %s stands for %s", key, issueKey)
+	}
+	return issueKey, nil
+}
+
+// Run executes the alias add command
+func (cmd *AliasAddCmd) Run() error {
+	// fail on a malformed alias before asking Jira about the issue
+	if err := internal.ValidateAlias(cmd.Name, cmd.IssueKey); err != nil {
+		return err
+	}
+
+	factory, err := getFactory()
+	if err != nil {
+		return err
+	}
+	issue, err := factory.GetService().GetIssue(context.Background(), cmd.IssueKey)
+	if err != nil {
+		return err
+	}
+
+	path, err := internal.ConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := internal.SetAlias(path, cmd.Name, issue.Key); err != nil {
+		return err
+	}
+	log.Infof("Alias %s now stands for %s", cmd.Name, issue.Key)
+	return printResult(internal.Alias{Name: cmd.Name, IssueKey: issue.Key, Summary: issue.Summary})
+}
+
+// Run executes the alias list command
+func (cmd *AliasListCmd) Run() error {
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	aliases := config.AliasList()
+	if len(aliases) == 0 {
+		log.Info("No aliases defined")
+	}
+	return printResult(aliases)
+}
+
+// Run executes the alias remove command
+func (cmd *AliasRemoveCmd) Run() error {
+	path, err := internal.ConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := internal.RemoveAlias(path, cmd.Name); err != nil {
+		return err
+	}
+	log.Infof("Removed alias %s", cmd.Name)
+	return nil
+}
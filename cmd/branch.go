@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// branchIssueKey returns the issue key in the name of the git branch checked out in the working directory,
+// or an empty key outside a git repository or when the branch names no issue
+func branchIssueKey() (string, error) {
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	branch, err := internal.CurrentBranch(dir)
+	if err != nil || branch == "" {
+		return "", err
+	}
+	issueKey, ok := config.Git.IssueKeyFromBranch(branch)
+	if !ok {
+		log.Debugf("No issue key in git branch %s", branch)
+		return "", nil
+	}
+	log.Infof("Using issue key %s from git branch %s", issueKey, branch)
+	return issueKey, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// CacheCmd groups the cache subcommands
+type CacheCmd struct {
+	Clear CacheClearCmd `cmd:"clear" help:"Forget the cached user and issues"`
+}
+
+// CacheClearCmd represents the cache clear command
+type CacheClearCmd struct{}
+
+// getCache returns the identity and issue cache in the tempoo home directory
+func getCache() (*internal.Cache, error) {
+	dir, err := internal.HomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return internal.NewCache(dir, internal.CacheTTL), nil
+}
+
+// Run executes the cache clear command
+func (cmd *CacheClearCmd) Run() error {
+	cache, err := getCache()
+	if err != nil {
+		return err
+	}
+	if err := cache.Clear(); err != nil {
+		return err
+	}
+	log.Info("Cache cleared")
+	return nil
+}
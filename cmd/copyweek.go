@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// CopyWeekCmd represents the copy-week command
+type CopyWeekCmd struct {
+	Source string  `help:"Any day of the week to copy, in DD.MM.YYYY format (defaults to last week)"`
+	Target string  `help:"Any day of the week to log the copies in, in DD.MM.YYYY format (defaults to this week)"`
+	Scale  float64 `help:"Multiply the duration of every worklog by this factor, rounding the result" default:"1"`
+	Edit   bool    `help:"Edit the worklogs in $EDITOR before logging them"`
+	DryRun bool    `help:"Show the worklogs without logging them"`
+	Yes    bool    `help:"Log without asking for confirmation" short:"y"`
+}
+
+// Run executes the copy-week command
+func (cmd *CopyWeekCmd) Run() error {
+	if cmd.Scale <= 0 {
+		return &internal.TempooError{Message: "Scale must be positive"}
+	}
+	source := cmd.Source
+	if source == "" {
+		source = time.Now().AddDate(0, 0, -7).Format("02.01.2006")
+	}
+	sourceWeek, err := internal.ParseWeek(source, time.Now())
+	if err != nil {
+		return err
+	}
+	targetWeek, err := internal.ParseWeek(cmd.Target, time.Now())
+	if err != nil {
+		return err
+	}
+	if sourceWeek == targetWeek {
+		return &internal.TempooError{Message: "The source and target weeks are the same"}
+	}
+
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return err
+	}
+	factory, err := getFactory()
+	if err != nil {
+		return err
+	}
+	service := factory.GetService()
+
+	worklogs, err := internal.WorklogsBetween(context.Background(), service, sourceWeek)
+	if err != nil {
+		return err
+	}
+	log.Infof("Found %d worklog(s) from %s to %s", len(worklogs), sourceWeek.From.Format("02.01.2006"), sourceWeek.To.Format("02.01.2006"))
+
+	draft := internal.CopyWorklogs(worklogs, targetWeek, cmd.Scale, config.Rounding)
+	if cmd.Edit && len(draft.Worklogs) > 0 {
+		if draft, err = editDraft(draft); err != nil {
+			return err
+		}
+		// aliases written in the editor are resolved before looking for worklogs already logged to the issues
+		for i := range draft.Worklogs {
+			draft.Worklogs[i].IssueKey = config.ResolveIssueKey(draft.Worklogs[i].IssueKey)
+		}
+	}
+
+	// copying again leaves the worklogs already copied alone
+	draft, err = internal.WithoutExisting(context.Background(), service, draft)
+	if err != nil {
+		return err
+	}
+	return importDraft(draft, cmd.DryRun, cmd.Yes)
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"tempoo/internal"
+	"tempoo/internal/fakejira"
+
+	"github.com/alecthomas/kong"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"github.com/stretchr/testify/require"
+	"github.com/tj/assert"
+)
+
+// startFakeJira points the CLI at a fake Jira server with a test user and issue
+func startFakeJira(t *testing.T) *fakejira.Server {
+	server := fakejira.New()
+	t.Cleanup(server.Close)
+	server.AddUser(fakejira.User{AccountID: "user-1", DisplayName: "Test User", Email: "test@example.com", APIToken: "test-token"})
+	server.AddIssue(fakejira.Issue{Key: "TEST-1", Summary: "Test issue", Status: "In Progress"})
+
+	t.Setenv("JIRA_EMAIL", "test@example.com")
+	t.Setenv("JIRA_API_TOKEN", "test-token")
+	t.Setenv(internal.JiraBaseURLEnvVar, server.URL)
+	tempooFactory = nil
+	t.Cleanup(func() { tempooFactory = nil })
+	return server
+}
+
+// runCLI parses and runs a command line, returning what it printed to stdout
+func runCLI(t *testing.T, args ...string) (string, error) {
+	parser := kong.Must(&CLI, kong.Name("tempoo"), kong.Exit(func(int) { t.Fatalf("tempoo %v exited", args) }))
+	ctx, err := parser.Parse(args)
+	require.NoError(t, err)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err = ctx.Run()
+	w.Close()
+	os.Stdout = oldStdout
+
+	output, _ := io.ReadAll(r)
+	return string(output), err
+}
+
+func TestCLI_EndToEnd(t *testing.T) {
+	server := startFakeJira(t)
+	defer func() { CLI.Output = "" }()
+
+	output, err := runCLI(t, "-o", "json", "add-worklog", "-i", "TEST-1", "-t", "1.5", "-D", "01.07.2025")
+	require.NoError(t, err)
+	var added internal.Worklog
+	require.NoError(t, json.Unmarshal([]byte(output), &added))
+	assert.Equal(t, 5400, added.TimeSpentSeconds)
+	assert.Equal(t, time.July, added.Started.Month())
+
+	output, err = runCLI(t, "-o", "json", "list-worklogs", "-i", "TEST-1")
+	require.NoError(t, err)
+	var listed internal.Worklogs
+	require.NoError(t, json.Unmarshal([]byte(output), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, added.ID, listed[0].ID)
+
+	_, err = runCLI(t, "edit-worklog", "-i", "TEST-1", "-w", added.ID, "-t", "2")
+	require.NoError(t, err)
+	assert.Equal(t, 7200, server.Worklogs("TEST-1")[0].TimeSpentSeconds)
+
+	output, err = runCLI(t, "-o", "json", "whoami")
+	require.NoError(t, err)
+	assert.Contains(t, output, `"account_id": "user-1"`)
+
+	_, err = runCLI(t, "remove-worklogs", "-i", "TEST-1")
+	require.NoError(t, err)
+	assert.Empty(t, server.Worklogs("TEST-1"))
+}
+
+func TestCLI_EndToEnd_Errors(t *testing.T) {
+	startFakeJira(t)
+
+	_, err := runCLI(t, "list-worklogs", "-i", "NOPE-1")
+	require.Error(t, err)
+	assert.Equal(t, exitNotFound, exitCode(err))
+
+	tempooFactory = nil
+	t.Setenv("JIRA_API_TOKEN", "wrong-token")
+	_, err = runCLI(t, "whoami")
+	require.Error(t, err)
+	assert.Equal(t, exitAuth, exitCode(err))
+}
+
+func TestCLI_RecordAndReplay(t *testing.T) {
+	server := startFakeJira(t)
+	dir := t.TempDir()
+	defer func() { CLI.Output, CLI.Record, CLI.Replay = "", "", "" }()
+
+	_, err := runCLI(t, "--record", dir, "add-worklog", "-i", "TEST-1", "-t", "1")
+	require.NoError(t, err)
+	recorded, err := runCLI(t, "--record", dir, "-o", "json", "list-worklogs", "-i", "TEST-1")
+	require.NoError(t, err)
+	server.Close()
+
+	// replay needs neither the server nor credentials
+	tempooFactory = nil
+	t.Setenv("JIRA_API_TOKEN", "")
+	replayed, err := runCLI(t, "--replay", dir, "-o", "json", "list-worklogs", "-i", "TEST-1")
+	require.NoError(t, err)
+	assert.JSONEq(t, recorded, replayed)
+}
+
+func TestSetupLogging_JSONFile(t *testing.T) {
+	path := t.TempDir() + "/tempoo.log"
+	CLI.LogFormat, CLI.LogFile, CLI.Verbose = "json", path, true
+	defer func() {
+		CLI.LogFormat, CLI.LogFile, CLI.Verbose = "", "", false
+		log.SetHandler(discard.New())
+	}()
+
+	closeLog, err := setupLogging()
+	require.NoError(t, err)
+	log.WithField("issue_key", "TEST-1").Debug("Logged to file")
+	closeLog()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &entry))
+	assert.Equal(t, "Logged to file", entry["message"])
+	assert.Equal(t, "debug", entry["level"])
+}
+
+func TestCLI_Cache(t *testing.T) {
+	startFakeJira(t)
+	defer func() { CLI.NoCache = false }()
+	home, _ := internal.HomeDir()
+	cachePath := home + "/" + internal.CacheFileName
+
+	_, err := runCLI(t, "whoami")
+	require.NoError(t, err)
+	assert.FileExists(t, cachePath)
+
+	_, err = runCLI(t, "cache", "clear")
+	require.NoError(t, err)
+	assert.NoFileExists(t, cachePath)
+
+	tempooFactory = nil
+	_, err = runCLI(t, "--no-cache", "whoami")
+	require.NoError(t, err)
+	assert.NoFileExists(t, cachePath)
+}
+
+func TestCLI_Alias(t *testing.T) {
+	server := startFakeJira(t)
+	t.Setenv(internal.HomeEnvVar, t.TempDir())
+	defer func() { CLI.Output = "" }()
+
+	_, err := runCLI(t, "alias", "add", "standup", "NOPE-1")
+	require.Error(t, err)
+	assert.Equal(t, exitNotFound, exitCode(err))
+
+	output, err := runCLI(t, "alias", "add", "standup", "TEST-1")
+	require.NoError(t, err)
+	assert.Equal(t, "standup = TEST-1 (Test issue)\n", output)
+
+	_, err = runCLI(t, "add-worklog", "-i", "standup", "-t", "1")
+	require.NoError(t, err)
+	assert.Len(t, server.Worklogs("TEST-1"), 1)
+
+	output, err = runCLI(t, "-o", "json", "alias", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, `"issue_key": "TEST-1"`)
+
+	_, err = runCLI(t, "alias", "remove", "standup")
+	require.NoError(t, err)
+	_, err = runCLI(t, "alias", "remove", "standup")
+	assert.Error(t, err)
+}
+
+func TestCLI_IssueKeyFromBranch(t *testing.T) {
+	server := startFakeJira(t)
+	repo := t.TempDir()
+	require.NoError(t, os.MkdirAll(repo+"/.git", 0o755))
+	require.NoError(t, os.WriteFile(repo+"/.git/HEAD", []byte("ref: refs/heads/feature/TEST-1-fix-thing\n"), 0o600))
+	t.Chdir(repo)
+
+	_, err := runCLI(t, "add-worklog", "-t", "2")
+	require.NoError(t, err)
+	require.Len(t, server.Worklogs("TEST-1"), 1)
+	assert.Equal(t, 7200, server.Worklogs("TEST-1")[0].TimeSpentSeconds)
+}
+
+func TestCLI_SuggestAndImport(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	server := startFakeJira(t)
+	defer func() { CLI.Output = "" }()
+
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+		{"commit", "--allow-empty", "-q", "-m", "TEST-1 first", "--date", "2025-07-01T09:00:00"},
+		{"commit", "--allow-empty", "-q", "-m", "TEST-1 second", "--date", "2025-07-01T10:00:00"},
+	} {
+		output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	draft, err := runCLI(t, "-o", "yaml", "suggest", repo, "--from", "01.07.2025", "--to", "01.07.2025")
+	require.NoError(t, err)
+	assert.Contains(t, draft, "issue_key: TEST-1")
+	assert.Contains(t, draft, "hours: 1.5")
+	path := t.TempDir() + "/draft.yaml"
+	require.NoError(t, os.WriteFile(path, []byte(draft), 0o600))
+
+	_, err = runCLI(t, "import", path, "--dry-run")
+	require.NoError(t, err)
+	assert.Empty(t, server.Worklogs("TEST-1"))
+
+	_, err = runCLI(t, "import", path, "--yes")
+	require.NoError(t, err)
+	require.Len(t, server.Worklogs("TEST-1"), 1)
+	assert.Equal(t, 5400, server.Worklogs("TEST-1")[0].TimeSpentSeconds)
+}
+
+// writeConfig points the tempoo home directory at a new directory holding a config file with content
+func writeConfig(t *testing.T, content string) {
+	home := t.TempDir()
+	t.Setenv(internal.HomeEnvVar, home)
+	require.NoError(t, os.WriteFile(home+"/"+internal.ConfigFileName, []byte(content), 0o600))
+}
+
+func TestCLI_ImportICS(t *testing.T) {
+	server := startFakeJira(t)
+	defer func() { CLI.Output = "" }()
+
+	config := "calendar:\n  rules:\n    - title: (?i)standup\n      issue_key: standup\naliases:\n  standup: TEST-1\n"
+	writeConfig(t, config)
+	calendar := t.TempDir() + "/calendar.ics"
+	require.NoError(t, os.WriteFile(calendar, []byte("BEGIN:VCALENDAR\n"+
+		"BEGIN:VEVENT\nSUMMARY:Standup\nDTSTART:20250701T090000\nDURATION:PT20M\nRRULE:FREQ=DAILY;COUNT=3\nEND:VEVENT\n"+
+		"BEGIN:VEVENT\nSUMMARY:Lunch\nDTSTART:20250701T120000\nDURATION:PT1H\nEND:VEVENT\n"+
+		"END:VCALENDAR\n"), 0o600))
+
+	preview, err := runCLI(t, "-o", "json", "import-ics", calendar, "--from", "01.07.2025", "--to", "02.07.2025", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, preview, `"issue_key": "TEST-1"`)
+	assert.Empty(t, server.Worklogs("TEST-1"))
+
+	_, err = runCLI(t, "import-ics", calendar, "--from", "01.07.2025", "--to", "02.07.2025", "--yes")
+	require.NoError(t, err)
+	worklogs := server.Worklogs("TEST-1")
+	require.Len(t, worklogs, 2)
+	// 20 minutes rounded to the default 30 minute granularity
+	assert.Equal(t, 1800, worklogs[0].TimeSpentSeconds)
+}
+
+func TestCLI_ImportCSV(t *testing.T) {
+	server := startFakeJira(t)
+	defer func() { CLI.Output = "" }()
+
+	config := "import:\n  rules:\n    - project: ^Internal$\n      issue_key: TEST-1\n"
+	writeConfig(t, config)
+	export := t.TempDir() + "/harvest.csv"
+	require.NoError(t, os.WriteFile(export, []byte("Date,Client,Project,Task,Notes,Hours\n"+
+		"2025-07-01,Acme,Internal,Meetings,Planning,1.4\n"+
+		"2025-07-01,Acme,Internal,Development,Reviews,0.5\n"+
+		"2025-07-02,Acme,Website,Design,Mockups,3\n"), 0o600))
+
+	preview, err := runCLI(t, "-o", "json", "import-csv", export, "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, preview, `"issue_key": "TEST-1"`)
+	assert.Empty(t, server.Worklogs("TEST-1"))
+
+	_, err = runCLI(t, "import-csv", export, "--format", "harvest", "--yes")
+	require.NoError(t, err)
+	worklogs := server.Worklogs("TEST-1")
+	require.Len(t, worklogs, 1)
+	// 1.9 hours on the same day and issue, rounded to the default 30 minute granularity
+	assert.Equal(t, 7200, worklogs[0].TimeSpentSeconds)
+}
+
+func TestCLI_ApplyTemplate(t *testing.T) {
+	server := startFakeJira(t)
+	defer func() { CLI.Output = "" }()
+
+	config := "templates:\n  week:\n    worklogs:\n      - issue_key: standup\n        hours: 0.5\n        comment: Standup\n        days: [mon, wed]\naliases:\n  standup: TEST-1\n"
+	writeConfig(t, config)
+
+	preview, err := runCLI(t, "-o", "json", "apply-template", "week", "--week", "03.07.2025", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, preview, `"date": "30.06.2025"`)
+	assert.Contains(t, preview, `"date": "02.07.2025"`)
+	assert.Empty(t, server.Worklogs("TEST-1"))
+
+	_, err = runCLI(t, "apply-template", "week", "--week", "03.07.2025", "--yes")
+	require.NoError(t, err)
+	require.Len(t, server.Worklogs("TEST-1"), 2)
+	assert.Contains(t, string(server.Worklogs("TEST-1")[0].Comment), "Standup")
+
+	// applying again logs nothing twice
+	_, err = runCLI(t, "apply-template", "week", "--week", "30.06.2025", "--yes")
+	require.NoError(t, err)
+	assert.Len(t, server.Worklogs("TEST-1"), 2)
+
+	_, err = runCLI(t, "apply-template", "holiday", "--dry-run")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Template 'holiday' is not defined")
+}
+
+func TestCLI_CopyWeek(t *testing.T) {
+	server := startFakeJira(t)
+	writeConfig(t, "")
+	defer func() { CLI.Output = "" }()
+
+	server.AddWorklog("TEST-1", "user-1", time.Date(2025, time.July, 1, 9, 0, 0, 0, time.Local), 5400)
+	server.AddWorklog("TEST-1", "user-1", time.Date(2025, time.July, 3, 14, 30, 0, 0, time.Local), 3600)
+
+	preview, err := runCLI(t, "-o", "json", "copy-week", "--source", "01.07.2025", "--target", "07.07.2025", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, preview, `"date": "08.07.2025"`)
+	assert.Contains(t, preview, `"start": "14:30"`)
+	assert.Len(t, server.Worklogs("TEST-1"), 2)
+
+	// the editor halves the first worklog
+	editor := t.TempDir() + "/editor.sh"
+	require.NoError(t, os.WriteFile(editor, []byte("#!/bin/sh\nsed -i 's/hours: 1.5/hours: 0.75/' \"$1\"\n"), 0o700))
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	_, err = runCLI(t, "copy-week", "--source", "01.07.2025", "--target", "07.07.2025", "--edit", "--yes")
+	require.NoError(t, err)
+	worklogs := server.Worklogs("TEST-1")
+	require.Len(t, worklogs, 4)
+	assert.Equal(t, 2700, worklogs[2].TimeSpentSeconds)
+	assert.True(t, worklogs[3].Started.Equal(time.Date(2025, time.July, 10, 14, 30, 0, 0, time.Local)))
+
+	// copying again logs only the worklog that was changed in the editor
+	_, err = runCLI(t, "copy-week", "--source", "01.07.2025", "--target", "07.07.2025", "--yes")
+	require.NoError(t, err)
+	worklogs = server.Worklogs("TEST-1")
+	require.Len(t, worklogs, 5)
+	assert.Equal(t, 5400, worklogs[4].TimeSpentSeconds)
+}
+
+func TestCLI_CopyWeekEditedAlias(t *testing.T) {
+	server := startFakeJira(t)
+	writeConfig(t, "aliases:\n  standup: TEST-1\n")
+	defer func() { CLI.Output = "" }()
+
+	server.AddWorklog("TEST-1", "user-1", time.Date(2025, time.July, 1, 9, 0, 0, 0, time.Local), 1800)
+
+	// the editor replaces the issue key with an alias
+	editor := t.TempDir() + "/editor.sh"
+	require.NoError(t, os.WriteFile(editor, []byte("#!/bin/sh\nsed -i 's/issue_key: TEST-1/issue_key: standup/' \"$1\"\n"), 0o700))
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	_, err := runCLI(t, "copy-week", "--source", "01.07.2025", "--target", "07.07.2025", "--edit", "--yes")
+	require.NoError(t, err)
+	worklogs := server.Worklogs("TEST-1")
+	require.Len(t, worklogs, 2)
+	assert.True(t, worklogs[1].Started.Equal(time.Date(2025, time.July, 8, 9, 0, 0, 0, time.Local)))
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// UndoCmd represents the undo command
+type UndoCmd struct {
+	Count int `help:"Number of changes to reverse" short:"n" default:"1"`
+}
+
+// HistoryCmd represents the history command
+type HistoryCmd struct {
+	Count int `help:"Number of changes to show, 0 for all" short:"n" default:"20"`
+}
+
+// getHistory returns the history journal in the tempoo home directory
+func getHistory() (*internal.History, error) {
+	dir, err := internal.HomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return internal.NewHistory(dir), nil
+}
+
+// Run executes the undo command
+func (cmd *UndoCmd) Run() error {
+	if cmd.Count < 1 {
+		return &internal.TempooError{Message: "Count must be at least 1"}
+	}
+
+	history, err := getHistory()
+	if err != nil {
+		return err
+	}
+
+	factory, err := getFactory()
+	if err != nil {
+		return err
+	}
+	// changes made through another backend are not journaled, so undo would reverse older Jira changes instead
+	if !usesJira(factory) {
+		return &internal.TempooError{Message: "undo only works with the jira backend, changes made with the selected profile's backend are not recorded"}
+	}
+	tempoo := factory.GetClient()
+
+	undone, err := tempoo.Undo(context.Background(), history, cmd.Count)
+	if printErr := printResult(undone); printErr != nil && err == nil {
+		return printErr
+	}
+	return err
+}
+
+// Run executes the history command
+func (cmd *HistoryCmd) Run() error {
+	history, err := getHistory()
+	if err != nil {
+		return err
+	}
+
+	entries, err := history.List(cmd.Count)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		log.Info("No worklog changes recorded")
+	}
+	return printResult(entries)
+}
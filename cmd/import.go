@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// ImportCmd represents the import command
+type ImportCmd struct {
+	File   string `arg:"" help:"Draft to log, a YAML or JSON file as written by suggest -o yaml" type:"existingfile"`
+	DryRun bool   `help:"Show the worklogs without logging them"`
+	Yes    bool   `help:"Log without asking for confirmation" short:"y"`
+}
+
+// Run executes the import command
+func (cmd *ImportCmd) Run() error {
+	draft, err := internal.LoadDraft(cmd.File)
+	if err != nil {
+		return err
+	}
+	return importDraft(draft, cmd.DryRun, cmd.Yes)
+}
+
+// importDraft previews a draft and logs it once confirmed. Aliases in the draft are resolved first.
+func importDraft(draft *internal.Draft, dryRun, yes bool) error {
+	if len(draft.Worklogs) == 0 {
+		log.Info("Nothing to log")
+		return nil
+	}
+
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return err
+	}
+	for i := range draft.Worklogs {
+		draft.Worklogs[i].IssueKey = config.ResolveIssueKey(draft.Worklogs[i].IssueKey)
+	}
+
+	if dryRun {
+		return printResult(draft)
+	}
+
+	// show what is about to be logged
+	fmt.Fprintln(os.Stderr, draft.Text())
+	if !yes && !confirm(fmt.Sprintf("Log these %d worklog(s)?", len(draft.Worklogs))) {
+		log.Info("Nothing logged")
+		return nil
+	}
+
+	factory, err := getFactory()
+	if err != nil {
+		return err
+	}
+	result := internal.ImportDraft(context.Background(), factory.GetService(), draft)
+	log.Infof("Logged %d of %d worklog(s)", len(result.Added), len(draft.Worklogs))
+
+	if err := printResult(result); err != nil {
+		return err
+	}
+	return result.Err()
+}
+
+// editDraft opens a draft as YAML in the user's editor, taken from $VISUAL or $EDITOR, and returns it as saved
+func editDraft(draft *internal.Draft) (*internal.Draft, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		return nil, &internal.TempooError{Message: "Set $EDITOR to edit the worklogs"}
+	}
+
+	file, err := os.CreateTemp("", "tempoo-draft-*.yaml")
+	if err != nil {
+		return nil, &internal.TempooError{Message: "Failed to create a file to edit", Cause: err}
+	}
+	file.Close()
+	path := file.Name()
+	defer os.Remove(path)
+	if err := internal.SaveDraft(path, draft); err != nil {
+		return nil, err
+	}
+
+	// the editor may come with arguments, such as "code --wait"
+	args := strings.Fields(editor)
+	command := exec.Command(args[0], append(args[1:], path)...)
+	command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := command.Run(); err != nil {
+		return nil, &internal.TempooError{Message: fmt.Sprintf("Editor %s failed", editor), Cause: err}
+	}
+	return internal.LoadDraft(path)
+}
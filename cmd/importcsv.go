@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// ImportCSVCmd represents the import-csv command
+type ImportCSVCmd struct {
+	File       string `arg:"" help:"Detailed CSV export of a time tracker" type:"existingfile"`
+	Format     string `help:"Time tracker the export comes from: toggl, clockify or harvest (detected from the header when omitted)"`
+	DateFormat string `help:"Date format of the export: YYYY-MM-DD, DD.MM.YYYY, MM/DD/YYYY or DD/MM/YYYY (detected from the dates when omitted)"`
+	DryRun     bool   `help:"Show the worklogs without logging them"`
+	Yes        bool   `help:"Log without asking for confirmation" short:"y"`
+}
+
+// Run executes the import-csv command
+func (cmd *ImportCSVCmd) Run() error {
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(cmd.File)
+	if err != nil {
+		return &internal.TempooError{Message: "Failed to open CSV export", Cause: err}
+	}
+	defer file.Close()
+	dateFormat := cmd.DateFormat
+	if dateFormat == "" {
+		dateFormat = config.Import.DateFormat
+	}
+	entries, format, err := internal.ParseTimeEntries(file, cmd.Format, dateFormat)
+	if err != nil {
+		return err
+	}
+	log.Infof("Read %d %s time entries", len(entries), format)
+
+	draft := internal.TrackerWorklogs(entries, config.Import, config.Rounding)
+	return importDraft(draft, cmd.DryRun, cmd.Yes)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"tempoo/internal"
+)
+
+// ImportICSCmd represents the import-ics command
+type ImportICSCmd struct {
+	File   string `arg:"" help:"Exported iCalendar file (.ics)" type:"existingfile"`
+	From   string `help:"First day in DD.MM.YYYY format (defaults to Monday of this week)"`
+	To     string `help:"Last day in DD.MM.YYYY format (defaults to today)"`
+	Email  string `help:"Your email among event attendees, to leave out declined events (defaults to calendar.email in the config, then JIRA_EMAIL)"`
+	DryRun bool   `help:"Show the worklogs without logging them"`
+	Yes    bool   `help:"Log without asking for confirmation" short:"y"`
+}
+
+// Run executes the import-ics command
+func (cmd *ImportICSCmd) Run() error {
+	days, err := internal.ParseDateRange(cmd.From, cmd.To, time.Now())
+	if err != nil {
+		return err
+	}
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(cmd.File)
+	if err != nil {
+		return &internal.TempooError{Message: "Failed to open calendar", Cause: err}
+	}
+	defer file.Close()
+	events, err := internal.ParseICS(file)
+	if err != nil {
+		return err
+	}
+
+	email := cmd.Email
+	if email == "" {
+		email = config.Calendar.Email
+	}
+	if email == "" {
+		email = os.Getenv("JIRA_EMAIL")
+	}
+
+	draft := internal.CalendarWorklogs(events, days, config.Calendar, email, config.Rounding)
+	return importDraft(draft, cmd.DryRun, cmd.Yes)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"tempoo/internal"
+
+	"github.com/mattn/go-isatty"
+)
+
+// pickerSize is how many issues the picker shows at once
+const pickerSize = 15
+
+// stdinIsTerminal reports whether stdin is an interactive terminal, a variable so tests can pretend it is
+var stdinIsTerminal = func() bool {
+	return isatty.IsTerminal(os.Stdin.Fd())
+}
+
+// IssuesCmd represents the issues command
+type IssuesCmd struct {
+	Mine    bool   `help:"Only issues assigned to you"`
+	Sprint  bool   `help:"Only issues in open sprints"`
+	Project string `help:"Only issues in this project (e.g., PROJ)" short:"p"`
+	Text    string `help:"Search the summary, description and comments for this text" short:"s"`
+	JQL     string `name:"jql" help:"Additional JQL condition"`
+	Max     int    `help:"Maximum number of issues to list" default:"20" short:"n"`
+}
+
+// Run executes the issues command
+func (cmd *IssuesCmd) Run() error {
+	factory, err := getFactory()
+	if err != nil {
+		return err
+	}
+
+	query := internal.IssueQuery{Mine: cmd.Mine, Sprint: cmd.Sprint, Project: cmd.Project, Text: cmd.Text, Condition: cmd.JQL}
+	issues, err := factory.GetService().SearchIssues(context.Background(), query.JQL(), cmd.Max)
+	if err != nil {
+		return err
+	}
+	return printResult(issues)
+}
+
+// pickIssue lets the user choose one of their recent and assigned issues on the terminal,
+// narrowing the list down by typing part of a key or summary
+func pickIssue(service internal.WorklogService) (string, error) {
+	issues, err := service.SearchIssues(context.Background(), internal.RecentAndAssignedJQL, 50)
+	if err != nil {
+		return "", err
+	}
+	if len(issues) == 0 {
+		return "", &internal.TempooError{Message: "No recent or assigned issues to pick from. Pass --issue-key"}
+	}
+	return runPicker(issues, stdin, os.Stderr)
+}
+
+// runPicker shows issues on out and reads choices from in until one issue is picked
+func runPicker(issues internal.Issues, in io.Reader, out io.Writer) (string, error) {
+	reader := bufio.NewReader(in)
+	candidates := issues
+	for {
+		for i, issue := range candidates {
+			if i == pickerSize {
+				fmt.Fprintf(out, "    ... %d more, type to filter\n", len(candidates)-pickerSize)
+				break
+			}
+			fmt.Fprintf(out, "%3d) %s %s\n", i+1, issue.Key, issue.Summary)
+		}
+		fmt.Fprint(out, "Pick an issue by number, or type to filter: ")
+
+		line, err := reader.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "" && err != nil {
+			return "", &internal.TempooError{Message: "No issue picked"}
+		}
+
+		if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= min(len(candidates), pickerSize) {
+			return candidates[n-1].Key, nil
+		}
+		if answer == "" && len(candidates) == 1 {
+			return candidates[0].Key, nil
+		}
+
+		filtered := internal.FilterIssues(issues, answer)
+		if len(filtered) == 0 {
+			fmt.Fprintf(out, "No issue matches '%s'\n", answer)
+			filtered = issues
+		}
+		candidates = filtered
+	}
+}
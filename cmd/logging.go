@@ -0,0 +1,38 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/cli"
+	jsonhandler "github.com/apex/log/handlers/json"
+)
+
+// setupLogging sends log messages to stderr or the --log-file, in the --log-format, at the level
+// --verbose selects. It returns a function closing the log file.
+func setupLogging() (func(), error) {
+	var w io.Writer = os.Stderr
+	closeLog := func() {}
+	if CLI.LogFile != "" {
+		f, err := os.OpenFile(CLI.LogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		w = f
+		closeLog = func() { f.Close() }
+	}
+
+	if CLI.LogFormat == "json" {
+		log.SetHandler(jsonhandler.New(w))
+	} else {
+		log.SetHandler(cli.New(w))
+	}
+
+	if CLI.Verbose {
+		log.SetLevel(log.DebugLevel)
+	} else {
+		log.SetLevel(log.InfoLevel)
+	}
+	return closeLog, nil
+}
@@ -1,13 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"tempoo/internal"
 
 	"github.com/alecthomas/kong"
 	"github.com/apex/log"
-	"github.com/apex/log/handlers/cli"
 	"github.com/willabides/kongplete"
 )
 
@@ -22,33 +23,164 @@ type VersionCmd struct{}
 
 // Run executes the version command
 func (cmd *VersionCmd) Run() error {
-	fmt.Println(version)
-	return nil
+	return printResult(VersionResult{Version: version})
+}
+
+// WhoAmICmd represents the whoami command
+type WhoAmICmd struct{}
+
+// Run executes the whoami command
+func (cmd *WhoAmICmd) Run() error {
+	factory, err := getFactory()
+	if err != nil {
+		return err
+	}
+
+	user, err := factory.GetService().WhoAmI(context.Background())
+	if err != nil {
+		return err
+	}
+	return printResult(user)
 }
 
 // AddWorklogCmd represents the add worklog command
 type AddWorklogCmd struct {
-	IssueKey string  `help:"Jira issue key (e.g., PROJ-123)" short:"i"`
+	IssueKey string  `help:"Jira issue key (e.g., PROJ-123) or alias. Defaults to the key in the git branch name, or is picked from recent and assigned issues on a terminal" short:"i"`
 	Hours    string  `help:"Hours to log (e.g., 1, 2.5, 8). Accepts whole numbers or .5 increments between 0.5 and 8 hours" short:"t"`
 	Date     *string `help:"Date for the worklog in DD.MM.YYYY format (defaults to today)" short:"D"`
+
+	Account    string            `help:"Tempo account key to bill the time to (defaults to the issue's account, Tempo backend only)"`
+	Attributes map[string]string `name:"attribute" help:"Tempo work attribute as name=value, repeatable (Tempo backend only)"`
 }
 
 // getFactory initializes and returns the tempoo factory
 func getFactory() (*internal.TempooFactory, error) {
-	if tempooFactory == nil {
-		var err error
-		tempooFactory, err = internal.NewTempooFactory()
+	if tempooFactory != nil {
+		return tempooFactory, nil
+	}
+
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// keep worklogs in the backend of the selected profile
+	profile, err := config.ActiveProfile(CLI.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if CLI.Demo {
+		profile.Backend = internal.BackendMemory
+	}
+
+	if CLI.Replay != "" {
+		// replayed responses need no real credentials
+		for _, name := range []string{"JIRA_EMAIL", "JIRA_API_TOKEN", "TEMPO_API_TOKEN"} {
+			if os.Getenv(name) == "" {
+				os.Setenv(name, "replay")
+			}
+		}
+	}
+
+	factory, err := internal.NewBackendFactory(profile.Backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Tempoo factory: %w", err)
+	}
+
+	if err := useCassettes(factory); err != nil {
+		return nil, err
+	}
+
+	// cassettes must hold every request, so skip the cache while recording or replaying
+	if client := factory.GetClient(); client != nil && !CLI.NoCache && CLI.Record == "" && CLI.Replay == "" {
+		cache, err := getCache()
 		if err != nil {
-			return nil, fmt.Errorf("failed to initialize Tempoo factory: %w", err)
+			return nil, err
 		}
+		client.EnableCache(cache)
 	}
+
+	// replayed changes never happened, so keep them out of the history and queue
+	if client := factory.GetClient(); client != nil && CLI.Replay == "" {
+		// journal every change so it can be undone
+		history, err := getHistory()
+		if err != nil {
+			return nil, err
+		}
+		client.EnableHistory(history)
+
+		// queue changes locally when Jira is unreachable, if asked to
+		if (CLI.Offline || config.Offline) && !usesJira(factory) {
+			if CLI.Offline {
+				return nil, &internal.TempooError{Message: fmt.Sprintf("--offline only works with the jira backend, the selected profile uses %s", profile.Backend)}
+			}
+			log.Warnf("Ignoring offline in the config file, the offline queue only works with the jira backend")
+		} else if CLI.Offline || config.Offline {
+			queue, err := getQueue()
+			if err != nil {
+				return nil, err
+			}
+			client.EnableOfflineQueue(queue)
+		}
+	}
+
+	tempooFactory = factory
 	return tempooFactory, nil
 }
 
+// useCassettes records the factory's API traffic to, or replays it from, the directory given with --record or --replay
+func useCassettes(factory *internal.TempooFactory) error {
+	switch {
+	case CLI.Record != "":
+		transport, err := internal.NewRecordingTransport(CLI.Record, nil)
+		if err != nil {
+			return err
+		}
+		factory.UseTransport(transport)
+	case CLI.Replay != "":
+		transport, err := internal.NewReplayTransport(CLI.Replay)
+		if err != nil {
+			return err
+		}
+		factory.UseTransport(transport)
+	}
+	return nil
+}
+
+// usesJira reports whether the factory's worklog service is its Jira client, which is the only one that
+// journals changes for undo and queues them offline
+func usesJira(factory *internal.TempooFactory) bool {
+	client := factory.GetClient()
+	return client != nil && factory.GetService() == internal.WorklogService(client)
+}
+
+// getJiraClient returns the Jira client for commands that only work against Jira
+func getJiraClient() (*internal.Tempoo, error) {
+	factory, err := getFactory()
+	if err != nil {
+		return nil, err
+	}
+
+	client := factory.GetClient()
+	if client == nil {
+		return nil, &internal.TempooError{Message: "This command needs Jira and is not available with the memory backend"}
+	}
+	return client, nil
+}
+
 // Run executes the add worklog command
 func (cmd *AddWorklogCmd) Run(ctx *kong.Context) error {
-	// Check if required parameters are provided
-	if cmd.IssueKey == "" || cmd.Hours == "" {
+	// default to the issue named by the current git branch
+	if cmd.IssueKey == "" && cmd.Hours != "" {
+		issueKey, err := branchIssueKey()
+		if err != nil {
+			return err
+		}
+		cmd.IssueKey = issueKey
+	}
+
+	// Check if required parameters are provided, the issue key can be picked on a terminal
+	if cmd.Hours == "" || (cmd.IssueKey == "" && !stdinIsTerminal()) {
 		fmt.Fprintf(ctx.Stderr, "Usage: %s\n", ctx.Command())
 		ctx.PrintUsage(false)
 		return nil
@@ -58,13 +190,35 @@ func (cmd *AddWorklogCmd) Run(ctx *kong.Context) error {
 	if err != nil {
 		return err
 	}
-	tempoo := factory.GetClient()
-	return tempoo.AddWorklog(cmd.IssueKey, cmd.Hours, cmd.Date)
+	tempoo := factory.GetService()
+
+	if cmd.IssueKey == "" {
+		if cmd.IssueKey, err = pickIssue(tempoo); err != nil {
+			return err
+		}
+	} else if cmd.IssueKey, err = resolveIssueKey(cmd.IssueKey); err != nil {
+		return err
+	}
+
+	var worklog *internal.Worklog
+	if cmd.Account != "" || len(cmd.Attributes) > 0 {
+		tempo, ok := tempoo.(*internal.TempoClient)
+		if !ok {
+			return &internal.TempooError{Message: "Accounts and work attributes need a profile using the tempo backend"}
+		}
+		worklog, err = tempo.AddWorklogWithAttributes(context.Background(), cmd.IssueKey, cmd.Hours, cmd.Date, cmd.Account, cmd.Attributes)
+	} else {
+		worklog, err = tempoo.AddWorklog(context.Background(), cmd.IssueKey, cmd.Hours, cmd.Date)
+	}
+	if err != nil {
+		return err
+	}
+	return printResult(worklog)
 }
 
 // RemoveWorklogsCmd represents the remove worklog command
 type RemoveWorklogsCmd struct {
-	IssueKey string `help:"Jira issue key (e.g., PROJ-123)" short:"i"`
+	IssueKey string `help:"Jira issue key (e.g., PROJ-123) or alias" short:"i"`
 }
 
 // Run executes the remove worklog command
@@ -80,17 +234,20 @@ func (cmd *RemoveWorklogsCmd) Run(ctx *kong.Context) error {
 	if err != nil {
 		return err
 	}
-	tempoo := factory.GetClient()
+	if cmd.IssueKey, err = resolveIssueKey(cmd.IssueKey); err != nil {
+		return err
+	}
+	tempoo := factory.GetService()
 
 	// get current user's account ID
-	userID, err := tempoo.GetUserAccountID()
+	userID, err := tempoo.GetUserAccountID(context.Background())
 	if err != nil {
 		return fmt.Errorf("failed to get user account ID: %w", err)
 	}
 	log.Debugf("User ID: %s", userID)
 
 	// get worklogs for the user
-	worklogIDs, err := tempoo.GetWorklogs(cmd.IssueKey, userID)
+	worklogIDs, err := tempoo.GetWorklogs(context.Background(), cmd.IssueKey, userID)
 	if err != nil {
 		return fmt.Errorf("failed to get worklogs: %w", err)
 	}
@@ -99,28 +256,71 @@ func (cmd *RemoveWorklogsCmd) Run(ctx *kong.Context) error {
 	// check if there are worklogs to remove
 	if len(worklogIDs) == 0 {
 		log.Infof("No worklogs found for issue %s", cmd.IssueKey)
+		return printResult(&internal.DeleteResult{IssueKey: cmd.IssueKey, Deleted: []string{}})
+	}
+
+	// delete all worklogs for the user, carrying on past individual failures
+	result := internal.DeleteWorklogs(context.Background(), tempoo, cmd.IssueKey, worklogIDs)
+	log.Infof("Deleted %d of %d worklog(s) from %s", len(result.Deleted), len(worklogIDs), cmd.IssueKey)
+	if len(result.Queued) > 0 {
+		log.Infof("Queued %d deletion(s) until Jira is reachable, run sync to apply them", len(result.Queued))
+	}
+
+	if err := printResult(result); err != nil {
+		return err
+	}
+	return result.Err()
+}
+
+// EditWorklogCmd represents the edit worklog command
+type EditWorklogCmd struct {
+	IssueKey  string  `help:"Jira issue key (e.g., PROJ-123) or alias" short:"i"`
+	WorklogID string  `help:"ID of the worklog to edit" short:"w"`
+	Hours     string  `help:"New hours for the worklog (e.g., 1, 2.5, 8)" short:"t"`
+	Date      *string `help:"New date for the worklog in DD.MM.YYYY format" short:"D"`
+}
+
+// Run executes the edit worklog command
+func (cmd *EditWorklogCmd) Run(ctx *kong.Context) error {
+	// Check if required parameters are provided
+	if cmd.IssueKey == "" || cmd.WorklogID == "" || (cmd.Hours == "" && cmd.Date == nil) {
+		fmt.Fprintf(ctx.Stderr, "Usage: %s\n", ctx.Command())
+		ctx.PrintUsage(false)
 		return nil
 	}
 
-	// delete all worklogs for the user
-	for _, worklogID := range worklogIDs {
-		log.Debugf("Deleting worklog ID: %s", worklogID)
-		if err := tempoo.DeleteWorklog(cmd.IssueKey, worklogID); err != nil {
-			return fmt.Errorf("failed to delete worklog %s: %w", worklogID, err)
-		}
-		log.Infof("Worklog ID %s deleted", worklogID)
+	factory, err := getFactory()
+	if err != nil {
+		return err
 	}
+	if cmd.IssueKey, err = resolveIssueKey(cmd.IssueKey); err != nil {
+		return err
+	}
+	tempoo := factory.GetService()
 
-	return nil
+	worklog, err := tempoo.UpdateWorklog(context.Background(), cmd.IssueKey, cmd.WorklogID, cmd.Hours, cmd.Date)
+	if err != nil {
+		return err
+	}
+	return printResult(worklog)
 }
 
 // ListWorklogsCmd represents the list worklogs command
 type ListWorklogsCmd struct {
-	IssueKey string `help:"Jira issue key (e.g., PROJ-123)" short:"i"`
+	IssueKey string `help:"Jira issue key (e.g., PROJ-123) or alias. Defaults to the key in the git branch name" short:"i"`
 }
 
 // Run executes the list worklogs command
 func (cmd *ListWorklogsCmd) Run(ctx *kong.Context) error {
+	// default to the issue named by the current git branch
+	if cmd.IssueKey == "" {
+		issueKey, err := branchIssueKey()
+		if err != nil {
+			return err
+		}
+		cmd.IssueKey = issueKey
+	}
+
 	// Check if required parameters are provided
 	if cmd.IssueKey == "" {
 		fmt.Fprintf(ctx.Stderr, "Usage: %s\n", ctx.Command())
@@ -132,21 +332,99 @@ func (cmd *ListWorklogsCmd) Run(ctx *kong.Context) error {
 	if err != nil {
 		return err
 	}
-	tempoo := factory.GetClient()
-	return tempoo.ListWorklogs(cmd.IssueKey)
+	if cmd.IssueKey, err = resolveIssueKey(cmd.IssueKey); err != nil {
+		return err
+	}
+	tempoo := factory.GetService()
+
+	worklogs, err := tempoo.ListWorklogs(context.Background(), cmd.IssueKey)
+	if err != nil {
+		return err
+	}
+	return printResult(worklogs)
+}
+
+// process exit codes, so scripts can tell failure classes apart
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitAuth       = 3
+	exitPermission = 4
+	exitNotFound   = 5
+	exitRateLimit  = 6
+	exitValidation = 7
+	exitServer     = 8
+)
+
+// exitCode maps an error returned by a command to the process exit code
+func exitCode(err error) int {
+	var (
+		authErr       *internal.AuthError
+		permissionErr *internal.PermissionError
+		notFoundErr   *internal.NotFoundError
+		invalidKeyErr *internal.InvalidIssueKeyError
+		rateLimitErr  *internal.RateLimitError
+		validationErr *internal.ValidationError
+		serverErr     *internal.ServerError
+	)
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &authErr):
+		return exitAuth
+	case errors.As(err, &permissionErr):
+		return exitPermission
+	case errors.As(err, &notFoundErr), errors.As(err, &invalidKeyErr):
+		return exitNotFound
+	case errors.As(err, &rateLimitErr):
+		return exitRateLimit
+	case errors.As(err, &validationErr):
+		return exitValidation
+	case errors.As(err, &serverErr):
+		return exitServer
+	}
+	return exitError
 }
 
 // Kong CLI struct
 var CLI struct {
 	AddWorklog     AddWorklogCmd     `cmd:"add-worklog" help:"Add a worklog to a Jira issue"`
 	RemoveWorklogs RemoveWorklogsCmd `cmd:"remove-worklogs" help:"Remove all user worklogs from a Jira issue"`
+	EditWorklog    EditWorklogCmd    `cmd:"edit-worklog" help:"Change the hours or date of a worklog"`
 	ListWorklogs   ListWorklogsCmd   `cmd:"list-worklogs" help:"List all worklogs for a Jira issue"`
+	Timer          TimerCmd          `cmd:"timer" help:"Track time with a local timer and log it when stopped"`
+	Sync           SyncCmd           `cmd:"sync" help:"Replay worklog changes queued while Jira was unreachable"`
+	Queue          QueueCmd          `cmd:"queue" help:"Inspect worklog changes queued while Jira was unreachable"`
+	Undo           UndoCmd           `cmd:"undo" help:"Reverse the most recent worklog changes"`
+	History        HistoryCmd        `cmd:"history" help:"Show recent worklog changes made with tempoo"`
+	Cache          CacheCmd          `cmd:"cache" help:"Manage the cache of the current user and issues"`
+	Timesheet      TimesheetCmd      `cmd:"timesheet" help:"Check, submit and reopen Tempo timesheets for approval"`
+	Issues         IssuesCmd         `cmd:"issues" help:"Search Jira issues"`
+	Alias          AliasCmd          `cmd:"alias" help:"Manage short names for issue keys"`
+	Suggest        SuggestCmd        `cmd:"suggest" help:"Draft worklogs from your commits in local git repositories"`
+	Import         ImportCmd         `cmd:"import" help:"Log the worklogs of a reviewed draft"`
+	ImportICS      ImportICSCmd      `cmd:"import-ics" name:"import-ics" help:"Log calendar events from an iCalendar file"`
+	ImportCSV      ImportCSVCmd      `cmd:"import-csv" name:"import-csv" help:"Log time tracked in Toggl, Clockify or Harvest from a CSV export"`
+	ApplyTemplate  ApplyTemplateCmd  `cmd:"apply-template" name:"apply-template" help:"Log the recurring worklogs of a template for a week"`
+	CopyWeek       CopyWeekCmd       `cmd:"copy-week" name:"copy-week" help:"Log the worklogs of a previous week again in another week"`
+	TUI            TUICmd            `cmd:"tui" name:"tui" help:"Edit a week of worklogs in a full-screen grid"`
+	WhoAmI         WhoAmICmd         `cmd:"whoami" name:"whoami" help:"Show the user the credentials belong to"`
 	Version        VersionCmd        `cmd:"version" help:"Print the version of the CLI"`
 
 	// Add the completion installation command
 	InstallCompletions kongplete.InstallCompletions `cmd:"install-completions" help:"Install shell completions"`
 
-	Verbose bool `help:"Enable debug logging"`
+	Verbose   bool   `help:"Enable debug logging, including every API request"`
+	LogFormat string `help:"Format of log messages: text or json" enum:"text,json" default:"text"`
+	LogFile   string `help:"Append log messages to this file instead of stderr" type:"path" placeholder:"FILE"`
+	Output    string `help:"Output format for results on stdout: text, json, yaml, table or csv" enum:"text,json,yaml,table,csv" default:"text" short:"o"`
+	Offline   bool   `help:"Queue worklog changes locally when Jira is unreachable"`
+	NoCache   bool   `help:"Ask Jira for the current user and issues instead of using cached answers"`
+	Profile   string `help:"Config profile to use, overriding the default profile" env:"TEMPOO_PROFILE"`
+	Demo      bool   `help:"Try tempoo against sample data kept in memory, without a Jira account"`
+	Record    string `help:"Record API requests and responses as cassettes in this directory, with credentials redacted" type:"path" xor:"cassette" placeholder:"DIR"`
+	Replay    string `help:"Answer API requests from cassettes recorded with --record instead of the network" type:"existingdir" xor:"cassette" placeholder:"DIR"`
 }
 
 // main function
@@ -172,15 +450,15 @@ func main() {
 		parser.FatalIfErrorf(err)
 	}
 
-	// set up apex/log with CLI handler
-	log.SetHandler(cli.New(os.Stderr))
-	if CLI.Verbose {
-		log.SetLevel(log.DebugLevel)
-	} else {
-		log.SetLevel(log.InfoLevel)
-	}
+	// set up apex/log
+	closeLog, err := setupLogging()
+	ctx.FatalIfErrorf(err)
 
 	// execute kong
 	err = ctx.Run()
-	ctx.FatalIfErrorf(err)
+	closeLog()
+	if err != nil {
+		ctx.Errorf("%s", err)
+		os.Exit(exitCode(err))
+	}
 }
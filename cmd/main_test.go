@@ -1,10 +1,17 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"tempoo/internal"
 
 	"github.com/alecthomas/kong"
 	"github.com/apex/log"
@@ -25,8 +32,16 @@ func TestMain(m *testing.M) {
 	os.Setenv("JIRA_EMAIL", "test@example.com")
 	os.Setenv("JIRA_API_TOKEN", "test-token")
 
+	// Keep config and state files out of the real home directory
+	home, err := os.MkdirTemp("", "tempoo-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("TEMPOO_HOME", home)
+
 	// Run tests
 	code := m.Run()
+	os.RemoveAll(home)
 
 	// Restore original environment variables
 	if originalEmail != "" {
@@ -68,8 +83,33 @@ func TestVersionCmd_Run(t *testing.T) {
 	assert.Contains(t, output, version)
 }
 
+func TestVersionCmd_Run_JSONOutput(t *testing.T) {
+	CLI.Output = "json"
+	defer func() { CLI.Output = "" }()
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	cmd := &VersionCmd{}
+	err := cmd.Run()
+
+	// Restore stdout
+	w.Close()
+	os.Stdout = oldStdout
+
+	buf := make([]byte, 1024)
+	n, _ := r.Read(buf)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version": "`+version+`"}`, string(buf[:n]))
+}
+
 func TestAddWorklogCmd_Run_MissingIssueKey(t *testing.T) {
 	var stderr bytes.Buffer
+	// outside a git repository, so no issue key comes from a branch name
+	t.Chdir(t.TempDir())
 
 	cmd := &AddWorklogCmd{
 		IssueKey: "",
@@ -109,8 +149,8 @@ func TestAddWorklogCmd_Run_MissingHours(t *testing.T) {
 }
 
 func TestAddWorklogCmd_Run_ValidInput(t *testing.T) {
-	// Reset factory for clean test
-	tempooFactory = nil
+	// Reset factory for clean test, against a fake Jira without the issue
+	startFakeJira(t)
 
 	cmd := &AddWorklogCmd{
 		IssueKey: "TEST-123",
@@ -128,6 +168,98 @@ func TestAddWorklogCmd_Run_ValidInput(t *testing.T) {
 	assert.Contains(t, err.Error(), "Failed to add worklog")
 }
 
+func TestAddWorklogCmd_Run_AttributesNeedTempo(t *testing.T) {
+	tempooFactory = nil
+	defer func() { tempooFactory = nil }()
+	os.Setenv("JIRA_EMAIL", "test@example.com")
+	os.Setenv("JIRA_API_TOKEN", "test-token")
+
+	cmd := &AddWorklogCmd{IssueKey: "TEST-123", Hours: "1", Account: "ACC"}
+	err := cmd.Run(&kong.Context{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tempo backend")
+}
+
+func TestConfirm(t *testing.T) {
+	defer func() { stdin = os.Stdin }()
+
+	for answer, expected := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false} {
+		stdin = strings.NewReader(answer)
+		assert.Equal(t, expected, confirm("Submit?"), "answer %q", answer)
+	}
+}
+
+func TestTimesheetStatusCmd_Run_NeedsTempo(t *testing.T) {
+	tempooFactory = nil
+	defer func() { tempooFactory = nil }()
+	os.Setenv("JIRA_EMAIL", "test@example.com")
+	os.Setenv("JIRA_API_TOKEN", "test-token")
+
+	err := (&TimesheetStatusCmd{}).Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tempo backend")
+}
+
+func TestOfflineAndUndo_NeedJira(t *testing.T) {
+	startFakeJira(t)
+	home := t.TempDir()
+	t.Setenv(internal.HomeEnvVar, home)
+	t.Setenv("TEMPO_API_TOKEN", "tempo-token")
+	require.NoError(t, os.WriteFile(home+"/"+internal.ConfigFileName, []byte("profiles:\n  work:\n    backend: tempo\n"), 0o600))
+	defer func() { CLI.Offline, CLI.Profile = false, "" }()
+	CLI.Profile = "work"
+
+	// changes through Tempo are neither queued nor journaled
+	CLI.Offline = true
+	_, err := getFactory()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--offline only works with the jira backend")
+
+	CLI.Offline = false
+	err = (&UndoCmd{Count: 1}).Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "undo only works with the jira backend")
+}
+
+func TestWorklogCommands_MemoryBackend(t *testing.T) {
+	service := internal.NewMemoryService(internal.User{AccountID: "user-1", DisplayName: "Test User"}, internal.Issue{Key: "TEST-123"})
+	tempooFactory = internal.NewServiceFactory(service)
+	defer func() { tempooFactory = nil }()
+
+	ctx := &kong.Context{}
+	require.NoError(t, (&AddWorklogCmd{IssueKey: "TEST-123", Hours: "1"}).Run(ctx))
+	require.NoError(t, (&AddWorklogCmd{IssueKey: "TEST-123", Hours: "2"}).Run(ctx))
+	require.NoError(t, (&ListWorklogsCmd{IssueKey: "TEST-123"}).Run(ctx))
+	require.NoError(t, (&RemoveWorklogsCmd{IssueKey: "TEST-123"}).Run(ctx))
+
+	worklogs, err := service.ListWorklogs(t.Context(), "TEST-123")
+	require.NoError(t, err)
+	assert.Empty(t, worklogs)
+
+	// commands replaying against Jira are not available without a Jira client
+	err = (&SyncCmd{}).Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "memory backend")
+}
+
+func TestGetFactory_Demo(t *testing.T) {
+	tempooFactory = nil
+	CLI.Demo = true
+	defer func() {
+		tempooFactory = nil
+		CLI.Demo = false
+	}()
+	os.Unsetenv("JIRA_EMAIL")
+	os.Unsetenv("JIRA_API_TOKEN")
+
+	factory, err := getFactory()
+	require.NoError(t, err)
+
+	user, err := factory.GetService().WhoAmI(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "demo-user", user.AccountID)
+}
+
 func TestRemoveWorklogsCmd_Run_MissingIssueKey(t *testing.T) {
 	var stderr bytes.Buffer
 
@@ -148,6 +280,8 @@ func TestRemoveWorklogsCmd_Run_MissingIssueKey(t *testing.T) {
 
 func TestListWorklogsCmd_Run_MissingIssueKey(t *testing.T) {
 	var stderr bytes.Buffer
+	// outside a git repository, so no issue key comes from a branch name
+	t.Chdir(t.TempDir())
 
 	cmd := &ListWorklogsCmd{
 		IssueKey: "",
@@ -215,6 +349,11 @@ func TestCLI_Structure(t *testing.T) {
 			args:     []string{"add-worklog", "-i", "TEST-123", "-t", "1.5"},
 			expected: "add-worklog",
 		},
+		{
+			name:     "add-worklog command with Tempo attributes",
+			args:     []string{"add-worklog", "-i", "TEST-123", "-t", "1", "--account", "ACC", "--attribute", "Billable=true", "--attribute", "Activity type=QA"},
+			expected: "add-worklog",
+		},
 		{
 			name:     "remove-worklogs command",
 			args:     []string{"remove-worklogs", "-i", "TEST-123"},
@@ -225,6 +364,51 @@ func TestCLI_Structure(t *testing.T) {
 			args:     []string{"list-worklogs", "-i", "TEST-123"},
 			expected: "list-worklogs",
 		},
+		{
+			name:     "timer start command",
+			args:     []string{"timer", "start", "TEST-123"},
+			expected: "timer start",
+		},
+		{
+			name:     "timer stop command without issue key",
+			args:     []string{"timer", "stop"},
+			expected: "timer stop",
+		},
+		{
+			name:     "sync command",
+			args:     []string{"sync"},
+			expected: "sync",
+		},
+		{
+			name:     "queue drop command",
+			args:     []string{"queue", "drop", "--all"},
+			expected: "queue drop",
+		},
+		{
+			name:     "edit-worklog command",
+			args:     []string{"edit-worklog", "-i", "TEST-123", "-w", "10001", "-t", "2"},
+			expected: "edit-worklog",
+		},
+		{
+			name:     "undo command",
+			args:     []string{"undo", "-n", "2"},
+			expected: "undo",
+		},
+		{
+			name:     "history command",
+			args:     []string{"history"},
+			expected: "history",
+		},
+		{
+			name:     "timesheet submit command",
+			args:     []string{"timesheet", "submit", "--period", "2025-07", "-m", "July", "-y"},
+			expected: "timesheet submit",
+		},
+		{
+			name:     "whoami command in demo mode",
+			args:     []string{"whoami", "--demo"},
+			expected: "whoami",
+		},
 		{
 			name:     "version command",
 			args:     []string{"version"},
@@ -274,6 +458,44 @@ func BenchmarkGetFactory(b *testing.B) {
 	}
 }
 
+func TestCLI_InvalidOutputFormat(t *testing.T) {
+	parser := kong.Must(&CLI)
+	_, err := parser.Parse([]string{"-o", "xml", "version"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must be one of")
+	CLI.Output = ""
+}
+
+func TestCLI_RecordAndReplayExclusive(t *testing.T) {
+	parser := kong.Must(&CLI)
+	_, err := parser.Parse([]string{"--record", ".", "--replay", ".", "version"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "can't be used together")
+	CLI.Record, CLI.Replay = "", ""
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{nil, exitOK},
+		{errors.New("boom"), exitError},
+		{&internal.AuthError{}, exitAuth},
+		{&internal.PermissionError{}, exitPermission},
+		{&internal.NotFoundError{}, exitNotFound},
+		{&internal.InvalidIssueKeyError{IssueKey: "TEST-1"}, exitNotFound},
+		{&internal.RateLimitError{}, exitRateLimit},
+		{&internal.ValidationError{}, exitValidation},
+		{&internal.ServerError{}, exitServer},
+		{fmt.Errorf("failed to delete worklog 1: %w", &internal.ServerError{}), exitServer},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, exitCode(tt.err), "%v", tt.err)
+	}
+}
+
 // Integration test for CLI parsing
 func TestCLI_Integration(t *testing.T) {
 	testCases := []struct {
@@ -287,6 +509,11 @@ func TestCLI_Integration(t *testing.T) {
 			args:        []string{"add-worklog", "--issue-key", "TEST-123", "--hours", "1.5", "--date", "01.01.2024"},
 			expectError: false,
 		},
+		{
+			name:        "valid output format",
+			args:        []string{"--output", "json", "list-worklogs", "-i", "TEST-123"},
+			expectError: false,
+		},
 		{
 			name:        "valid add-worklog with short flags",
 			args:        []string{"add-worklog", "-i", "TEST-123", "-t", "2.5"},
@@ -310,3 +537,115 @@ func TestCLI_Integration(t *testing.T) {
 		})
 	}
 }
+
+func TestRunPicker(t *testing.T) {
+	issues := internal.Issues{
+		{Key: "PROJ-1", Summary: "Fix the login page"},
+		{Key: "PROJ-2", Summary: "Write release notes"},
+		{Key: "OPS-7", Summary: "Rotate certificates"},
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2\n", "PROJ-2"},
+		{"cert\n\n", "OPS-7"},
+		{"proj\n2\n", "PROJ-2"},
+		{"nothing\n3\n", "OPS-7"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		key, err := runPicker(issues, strings.NewReader(tt.input), &out)
+		require.NoError(t, err, "input %q", tt.input)
+		assert.Equal(t, tt.expected, key, "input %q", tt.input)
+	}
+
+	_, err := runPicker(issues, strings.NewReader(""), io.Discard)
+	assert.Error(t, err)
+}
+
+func TestAddWorklogCmd_Run_PicksIssue(t *testing.T) {
+	t.Chdir(t.TempDir())
+	service := internal.NewMemoryService(internal.User{AccountID: "user-1"}, internal.Issue{Key: "TEST-1", Summary: "First"}, internal.Issue{Key: "TEST-2", Summary: "Second"})
+	tempooFactory = internal.NewServiceFactory(service)
+	stdin = strings.NewReader("second\n\n")
+	stdinIsTerminal = func() bool { return true }
+	defer func() {
+		tempooFactory = nil
+		stdin = os.Stdin
+		stdinIsTerminal = func() bool { return false }
+	}()
+
+	require.NoError(t, (&AddWorklogCmd{Hours: "1"}).Run(&kong.Context{}))
+	worklogs, err := service.ListWorklogs(t.Context(), "TEST-2")
+	require.NoError(t, err)
+	assert.Len(t, worklogs, 1)
+}
+
+func TestReadKey(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("\x1b[A\x1b[3~1.\r\x7fé"))
+	var keys []string
+	for {
+		key, err := readKey(reader)
+		if err != nil {
+			break
+		}
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []string{"up", "delete", "1", ".", "enter", "backspace", "é"}, keys)
+}
+
+func TestTUIModel(t *testing.T) {
+	service := internal.NewMemoryService(internal.User{AccountID: "user-1"}, internal.Issue{Key: "TEST-1", Summary: "First"}, internal.Issue{Key: "TEST-2", Summary: "Second"})
+	date := "01.07.2025"
+	_, err := service.AddWorklog(t.Context(), "TEST-1", "1", &date)
+	require.NoError(t, err)
+	week, err := internal.ParseWeek(date, time.Now())
+	require.NoError(t, err)
+
+	model := &tuiModel{service: service, config: internal.DefaultConfig()}
+	require.NoError(t, model.load(week))
+	screen := model.render()
+	assert.Contains(t, screen, "TEST-1 First")
+	assert.Contains(t, screen, "Tue 01")
+
+	// set Tuesday to 2 hours, add TEST-2 with 1.5 hours on Wednesday, save and quit
+	keys := "\x1b[C2\r" + "asec\r" + "\x1b[C1.5\r" + "sy" + "q"
+	require.NoError(t, model.run(bufio.NewReader(strings.NewReader(keys)), io.Discard))
+
+	assert.True(t, model.done)
+	require.Len(t, model.applied, 2)
+	assert.Equal(t, internal.OpEditWorklog, model.applied[0].Op)
+	assert.Equal(t, internal.OpAddWorklog, model.applied[1].Op)
+	worklogs, _ := service.ListWorklogs(t.Context(), "TEST-2")
+	require.Len(t, worklogs, 1)
+	assert.Equal(t, 5400, worklogs[0].TimeSpentSeconds)
+	assert.Equal(t, 2, worklogs[0].Started.Day())
+}
+
+func TestTUIModel_InvalidHoursAndQuit(t *testing.T) {
+	service := internal.NewMemoryService(internal.User{AccountID: "user-1"}, internal.Issue{Key: "TEST-1", Summary: "First"})
+	date := "01.07.2025"
+	_, err := service.AddWorklog(t.Context(), "TEST-1", "1", &date)
+	require.NoError(t, err)
+	week, _ := internal.ParseWeek(date, time.Now())
+	model := &tuiModel{service: service, config: internal.DefaultConfig()}
+	require.NoError(t, model.load(week))
+
+	for _, key := range []string{"right", "9", "enter"} {
+		model.handleKey(key)
+	}
+	assert.Contains(t, model.message, "Hours cannot exceed 8")
+
+	// clearing the cell leaves a change, so quitting asks first
+	for _, key := range []string{"esc", "x", "q"} {
+		model.handleKey(key)
+	}
+	assert.False(t, model.done)
+	assert.Contains(t, model.render(), "Discard 1 unsaved change(s)?")
+	model.handleKey("y")
+	assert.True(t, model.done)
+	assert.Empty(t, model.applied)
+}
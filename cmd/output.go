@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"tempoo/internal"
+)
+
+// printResult writes a command result to stdout in the format selected with --output
+func printResult(result internal.Result) error {
+	printer, err := internal.NewPrinter(CLI.Output, os.Stdout)
+	if err != nil {
+		return err
+	}
+	return printer.Print(result)
+}
+
+// VersionResult is the output of the version command
+type VersionResult struct {
+	Version string `json:"version"`
+}
+
+// Columns implements internal.Result
+func (r VersionResult) Columns() []string { return []string{"version"} }
+
+// Rows implements internal.Result
+func (r VersionResult) Rows() [][]string { return [][]string{{r.Version}} }
+
+// Text implements internal.Texter
+func (r VersionResult) Text() string { return r.Version }
+
+// DroppedOperations is the output of the queue drop command
+type DroppedOperations struct {
+	Dropped int `json:"dropped"`
+}
+
+// Columns implements internal.Result
+func (r DroppedOperations) Columns() []string { return []string{"dropped"} }
+
+// Rows implements internal.Result
+func (r DroppedOperations) Rows() [][]string { return [][]string{{strconv.Itoa(r.Dropped)}} }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// SyncCmd represents the sync command
+type SyncCmd struct{}
+
+// QueueCmd groups the offline queue subcommands
+type QueueCmd struct {
+	List QueueListCmd `cmd:"list" help:"List queued worklog changes"`
+	Drop QueueDropCmd `cmd:"drop" help:"Discard queued worklog changes"`
+}
+
+// QueueListCmd represents the queue list command
+type QueueListCmd struct{}
+
+// QueueDropCmd represents the queue drop command
+type QueueDropCmd struct {
+	IDs []string `arg:"" optional:"" name:"id" help:"IDs of the queued changes to discard"`
+	All bool     `help:"Discard every queued change"`
+}
+
+// getQueue returns the offline queue in the tempoo home directory
+func getQueue() (*internal.Queue, error) {
+	dir, err := internal.HomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return internal.NewQueue(dir), nil
+}
+
+// Run executes the sync command
+func (cmd *SyncCmd) Run() error {
+	queue, err := getQueue()
+	if err != nil {
+		return err
+	}
+
+	tempoo, err := getJiraClient()
+	if err != nil {
+		return err
+	}
+
+	result, err := tempoo.SyncQueue(context.Background(), queue)
+	if result != nil {
+		log.Infof("Sync finished: %d applied, %d duplicate(s), %d conflict(s), %d pending",
+			result.Applied, result.Duplicates, result.Conflicts, result.Pending)
+		if printErr := printResult(result); printErr != nil && err == nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+// Run executes the queue list command
+func (cmd *QueueListCmd) Run() error {
+	queue, err := getQueue()
+	if err != nil {
+		return err
+	}
+
+	ops, err := queue.List()
+	if err != nil {
+		return err
+	}
+
+	if len(ops) == 0 {
+		log.Info("Offline queue is empty")
+		ops = internal.QueuedOperations{}
+	} else {
+		log.Infof("%d queued operation(s)", len(ops))
+	}
+	return printResult(ops)
+}
+
+// Run executes the queue drop command
+func (cmd *QueueDropCmd) Run() error {
+	if len(cmd.IDs) == 0 && !cmd.All {
+		return &internal.TempooError{Message: "Specify the IDs to drop or --all"}
+	}
+
+	queue, err := getQueue()
+	if err != nil {
+		return err
+	}
+
+	dropped, err := queue.Drop(cmd.IDs, cmd.All)
+	if err != nil {
+		return err
+	}
+	log.Infof("Dropped %d queued operation(s)", dropped)
+	return printResult(DroppedOperations{Dropped: dropped})
+}
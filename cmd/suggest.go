@@ -0,0 +1,55 @@
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// SuggestCmd represents the suggest command
+type SuggestCmd struct {
+	Repos  []string `arg:"" optional:"" help:"Local git repositories to scan (defaults to the current directory)" type:"existingdir"`
+	From   string   `help:"First day in DD.MM.YYYY format (defaults to Monday of this week)"`
+	To     string   `help:"Last day in DD.MM.YYYY format (defaults to today)"`
+	Author string   `help:"Commit author to look for (defaults to user.email of each repository)"`
+}
+
+// Run executes the suggest command
+func (cmd *SuggestCmd) Run() error {
+	days, err := internal.ParseDateRange(cmd.From, cmd.To, time.Now())
+	if err != nil {
+		return err
+	}
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	repos := cmd.Repos
+	if len(repos) == 0 {
+		repos = []string{"."}
+	}
+
+	var commits []internal.Commit
+	for _, repo := range repos {
+		// name repositories by their directory in the draft notes
+		if abs, err := filepath.Abs(repo); err == nil {
+			repo = abs
+		}
+		found, err := internal.ReadCommits(repo, cmd.Author, days)
+		if err != nil {
+			return err
+		}
+		log.Debugf("Found %d commit(s) in %s", len(found), repo)
+		commits = append(commits, found...)
+	}
+
+	draft := internal.SuggestWorklogs(commits, config.Git, config.Rounding)
+	if len(draft.Worklogs) == 0 {
+		log.Infof("No commits with issue keys found from %s to %s", days.From.Format("02.01.2006"), days.To.Format("02.01.2006"))
+	}
+	return printResult(draft)
+}
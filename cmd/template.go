@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"tempoo/internal"
+)
+
+// ApplyTemplateCmd represents the apply-template command
+type ApplyTemplateCmd struct {
+	Name   string `arg:"" help:"Name of the template in the config file"`
+	Week   string `help:"Any day of the week to log, in DD.MM.YYYY format (defaults to this week)"`
+	DryRun bool   `help:"Show the worklogs without logging them"`
+	Yes    bool   `help:"Log without asking for confirmation" short:"y"`
+}
+
+// Run executes the apply-template command
+func (cmd *ApplyTemplateCmd) Run() error {
+	days, err := internal.ParseWeek(cmd.Week, time.Now())
+	if err != nil {
+		return err
+	}
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return err
+	}
+	template, err := config.Template(cmd.Name)
+	if err != nil {
+		return err
+	}
+
+	// aliases are resolved before looking for worklogs already logged to the issues
+	draft := template.Draft(days)
+	for i := range draft.Worklogs {
+		draft.Worklogs[i].IssueKey = config.ResolveIssueKey(draft.Worklogs[i].IssueKey)
+	}
+
+	factory, err := getFactory()
+	if err != nil {
+		return err
+	}
+	draft, err = internal.WithoutExisting(context.Background(), factory.GetService(), draft)
+	if err != nil {
+		return err
+	}
+	return importDraft(draft, cmd.DryRun, cmd.Yes)
+}
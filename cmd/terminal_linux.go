@@ -0,0 +1,9 @@
+package main
+
+import "golang.org/x/sys/unix"
+
+// ioctl requests reading and changing the terminal mode
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package main
+
+import "tempoo/internal"
+
+// makeRaw is not supported on this platform
+func makeRaw(fd int) (func(), error) {
+	return nil, &internal.TempooError{Message: "The tui command needs a Unix terminal"}
+}
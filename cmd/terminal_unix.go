@@ -0,0 +1,32 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"golang.org/x/sys/unix"
+
+	"tempoo/internal"
+)
+
+// makeRaw puts the terminal into raw mode, so keys are read as they are pressed without being echoed,
+// and returns a function restoring the previous mode
+func makeRaw(fd int) (func(), error) {
+	termios, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, &internal.TempooError{Message: "Failed to read the terminal mode", Cause: err}
+	}
+	previous := *termios
+
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Oflag &^= unix.OPOST
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB
+	termios.Cflag |= unix.CS8
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, termios); err != nil {
+		return nil, &internal.TempooError{Message: "Failed to switch the terminal to raw mode", Cause: err}
+	}
+
+	return func() { unix.IoctlSetTermios(fd, ioctlSetTermios, &previous) }, nil
+}
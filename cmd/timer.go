@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// TimerCmd groups the timer subcommands
+type TimerCmd struct {
+	Start  TimerStartCmd  `cmd:"start" help:"Start a timer for a Jira issue"`
+	Stop   TimerStopCmd   `cmd:"stop" help:"Stop a timer and log the elapsed time to its Jira issue"`
+	Pause  TimerPauseCmd  `cmd:"pause" help:"Pause a running timer"`
+	Resume TimerResumeCmd `cmd:"resume" help:"Resume a paused timer"`
+	Status TimerStatusCmd `cmd:"status" help:"Show running and paused timers"`
+	Cancel TimerCancelCmd `cmd:"cancel" help:"Discard a timer without logging time"`
+}
+
+// TimerStartCmd represents the timer start command
+type TimerStartCmd struct {
+	IssueKey string `arg:"" optional:"" help:"Jira issue key (e.g., PROJ-123) or alias. Defaults to the key in the git branch name"`
+}
+
+// TimerStopCmd represents the timer stop command
+type TimerStopCmd struct {
+	IssueKey string `arg:"" optional:"" help:"Jira issue key or alias, required when more than one timer exists"`
+}
+
+// TimerPauseCmd represents the timer pause command
+type TimerPauseCmd struct {
+	IssueKey string `arg:"" optional:"" help:"Jira issue key or alias, required when more than one timer exists"`
+}
+
+// TimerResumeCmd represents the timer resume command
+type TimerResumeCmd struct {
+	IssueKey string `arg:"" optional:"" help:"Jira issue key or alias, required when more than one timer exists"`
+}
+
+// TimerStatusCmd represents the timer status command
+type TimerStatusCmd struct{}
+
+// TimerCancelCmd represents the timer cancel command
+type TimerCancelCmd struct {
+	IssueKey string `arg:"" optional:"" help:"Jira issue key or alias, required when more than one timer exists"`
+}
+
+// getTimerStore returns the timer store in the tempoo home directory
+func getTimerStore() (*internal.TimerStore, error) {
+	dir, err := internal.HomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return internal.NewTimerStore(dir), nil
+}
+
+// Run executes the timer start command
+func (cmd *TimerStartCmd) Run() error {
+	store, err := getTimerStore()
+	if err != nil {
+		return err
+	}
+	if cmd.IssueKey == "" {
+		if cmd.IssueKey, err = branchIssueKey(); err != nil {
+			return err
+		}
+		if cmd.IssueKey == "" {
+			return &internal.TempooError{Message: "No issue key given and none found in the git branch name"}
+		}
+	}
+	if cmd.IssueKey, err = resolveIssueKey(cmd.IssueKey); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	timer, err := store.Start(cmd.IssueKey, now)
+	if err != nil {
+		return err
+	}
+	log.Infof("Started timer for %s at %s", timer.IssueKey, timer.StartedAt.Format("15:04"))
+	return printResult(timer.Status(now))
+}
+
+// Run executes the timer stop command
+func (cmd *TimerStopCmd) Run() error {
+	store, err := getTimerStore()
+	if err != nil {
+		return err
+	}
+	if cmd.IssueKey, err = resolveIssueKey(cmd.IssueKey); err != nil {
+		return err
+	}
+
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	var worklog *internal.Worklog
+	_, err = store.Stop(cmd.IssueKey, time.Now(), func(timer *internal.Timer) error {
+		rounded := config.Rounding.Round(timer.Accumulated)
+		log.Infof("Timer for %s ran for %s, logging %s", timer.IssueKey, timer.Accumulated.Round(time.Second), rounded)
+
+		factory, err := getFactory()
+		if err != nil {
+			return err
+		}
+		tempoo := factory.GetService()
+		worklog, err = tempoo.AddWorklogAt(context.Background(), timer.IssueKey, timer.StartedAt, rounded)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return printResult(worklog)
+}
+
+// Run executes the timer pause command
+func (cmd *TimerPauseCmd) Run() error {
+	store, err := getTimerStore()
+	if err != nil {
+		return err
+	}
+	if cmd.IssueKey, err = resolveIssueKey(cmd.IssueKey); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	timer, err := store.Pause(cmd.IssueKey, now)
+	if err != nil {
+		return err
+	}
+	log.Infof("Paused timer for %s at %s", timer.IssueKey, timer.Accumulated.Round(time.Second))
+	return printResult(timer.Status(now))
+}
+
+// Run executes the timer resume command
+func (cmd *TimerResumeCmd) Run() error {
+	store, err := getTimerStore()
+	if err != nil {
+		return err
+	}
+	if cmd.IssueKey, err = resolveIssueKey(cmd.IssueKey); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	timer, err := store.Resume(cmd.IssueKey, now)
+	if err != nil {
+		return err
+	}
+	log.Infof("Resumed timer for %s", timer.IssueKey)
+	return printResult(timer.Status(now))
+}
+
+// Run executes the timer status command
+func (cmd *TimerStatusCmd) Run() error {
+	store, err := getTimerStore()
+	if err != nil {
+		return err
+	}
+
+	timers, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if len(timers) == 0 {
+		log.Info("No timers running")
+	}
+
+	now := time.Now()
+	statuses := internal.TimerStatuses{}
+	for _, timer := range timers {
+		statuses = append(statuses, timer.Status(now))
+	}
+	return printResult(statuses)
+}
+
+// Run executes the timer cancel command
+func (cmd *TimerCancelCmd) Run() error {
+	store, err := getTimerStore()
+	if err != nil {
+		return err
+	}
+	if cmd.IssueKey, err = resolveIssueKey(cmd.IssueKey); err != nil {
+		return err
+	}
+
+	timer, err := store.Cancel(cmd.IssueKey)
+	if err != nil {
+		return err
+	}
+	log.Infof("Cancelled timer for %s", timer.IssueKey)
+	return printResult(timer.Status(time.Now()))
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// stdin is where confirmations are read from
+var stdin io.Reader = os.Stdin
+
+// TimesheetCmd groups the timesheet approval subcommands
+type TimesheetCmd struct {
+	Status TimesheetStatusCmd `cmd:"status" help:"Show the approval status and logged hours of a timesheet"`
+	Submit TimesheetSubmitCmd `cmd:"submit" help:"Submit a timesheet for approval"`
+	Reopen TimesheetReopenCmd `cmd:"reopen" help:"Reopen a submitted or approved timesheet"`
+}
+
+// TimesheetStatusCmd represents the timesheet status command
+type TimesheetStatusCmd struct {
+	Period string `help:"Day in the approval period as DD.MM.YYYY, or a month as YYYY-MM (defaults to today)" short:"p"`
+}
+
+// TimesheetSubmitCmd represents the timesheet submit command
+type TimesheetSubmitCmd struct {
+	Period   string `help:"Day in the approval period as DD.MM.YYYY, or a month as YYYY-MM (defaults to today)" short:"p"`
+	Comment  string `help:"Comment for the reviewer" short:"m"`
+	Reviewer string `help:"Account ID of the reviewer (defaults to the assigned reviewer)"`
+	Yes      bool   `help:"Submit without asking for confirmation" short:"y"`
+}
+
+// TimesheetReopenCmd represents the timesheet reopen command
+type TimesheetReopenCmd struct {
+	Period  string `help:"Day in the approval period as DD.MM.YYYY, or a month as YYYY-MM (defaults to today)" short:"p"`
+	Comment string `help:"Reason for reopening" short:"m"`
+}
+
+// getTempoClient returns the Tempo client of the selected profile, as timesheets only exist in Tempo
+func getTempoClient() (*internal.TempoClient, error) {
+	factory, err := getFactory()
+	if err != nil {
+		return nil, err
+	}
+
+	tempo, ok := factory.GetService().(*internal.TempoClient)
+	if !ok {
+		return nil, &internal.TempooError{Message: "Timesheets need a profile using the tempo backend"}
+	}
+	return tempo, nil
+}
+
+// Run executes the timesheet status command
+func (cmd *TimesheetStatusCmd) Run() error {
+	date, err := internal.ParsePeriodDate(cmd.Period)
+	if err != nil {
+		return err
+	}
+
+	tempo, err := getTempoClient()
+	if err != nil {
+		return err
+	}
+
+	timesheet, err := tempo.Timesheet(context.Background(), date)
+	if err != nil {
+		return err
+	}
+	return printResult(timesheet)
+}
+
+// Run executes the timesheet submit command
+func (cmd *TimesheetSubmitCmd) Run() error {
+	date, err := internal.ParsePeriodDate(cmd.Period)
+	if err != nil {
+		return err
+	}
+
+	tempo, err := getTempoClient()
+	if err != nil {
+		return err
+	}
+
+	// show what is about to be submitted
+	timesheet, err := tempo.Timesheet(context.Background(), date)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, timesheet.Text())
+
+	if timesheet.MissingSeconds() > 0 {
+		log.Warnf("Logged time is below the required time for this period")
+	}
+
+	if !cmd.Yes && !confirm("Submit this timesheet for approval?") {
+		log.Info("Timesheet not submitted")
+		return nil
+	}
+
+	timesheet, err = tempo.SubmitTimesheet(context.Background(), date, cmd.Comment, cmd.Reviewer)
+	if err != nil {
+		return err
+	}
+	log.Infof("Submitted timesheet for %s to %s", timesheet.From, timesheet.To)
+	return printResult(timesheet)
+}
+
+// Run executes the timesheet reopen command
+func (cmd *TimesheetReopenCmd) Run() error {
+	date, err := internal.ParsePeriodDate(cmd.Period)
+	if err != nil {
+		return err
+	}
+
+	tempo, err := getTempoClient()
+	if err != nil {
+		return err
+	}
+
+	timesheet, err := tempo.ReopenTimesheet(context.Background(), date, cmd.Comment)
+	if err != nil {
+		return err
+	}
+	log.Infof("Reopened timesheet for %s to %s", timesheet.From, timesheet.To)
+	return printResult(timesheet)
+}
+
+// confirm asks a yes/no question on stderr, treating anything but yes as no
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,525 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tempoo/internal"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+)
+
+// terminal escape sequences drawing the tui
+const (
+	enterFullScreen = "\x1b[?1049h\x1b[?25l"
+	leaveFullScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen     = "\x1b[H\x1b[2J"
+	styleReverse    = "\x1b[7m"
+	styleBold       = "\x1b[1m"
+	styleGreen      = "\x1b[32m"
+	styleYellow     = "\x1b[33m"
+	styleRed        = "\x1b[31m"
+	styleReset      = "\x1b[0m"
+)
+
+// layout of the tui grid
+const (
+	issueColumnWidth = 32
+	dayColumnWidth   = 9
+)
+
+// what the tui is doing
+const (
+	tuiGrid = iota
+	tuiSearch
+	tuiReview
+	tuiQuit
+)
+
+// tuiHelp lists the keys of each mode
+var tuiHelp = map[int]string{
+	tuiGrid:   "arrows move  0-9 . type hours  enter set  x clear  a add issue  s save  q quit",
+	tuiSearch: "type to filter  up/down choose  enter add  esc cancel",
+	tuiReview: "y save  any other key goes back",
+	tuiQuit:   "y discard and quit  any other key goes back",
+}
+
+// TUICmd represents the tui command
+type TUICmd struct {
+	Week string `help:"Any day of the week to show, in DD.MM.YYYY format (defaults to this week)"`
+}
+
+// Run executes the tui command
+func (cmd *TUICmd) Run() error {
+	if !stdinIsTerminal() {
+		return &internal.TempooError{Message: "The tui command needs an interactive terminal"}
+	}
+	days, err := internal.ParseWeek(cmd.Week, time.Now())
+	if err != nil {
+		return err
+	}
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return err
+	}
+	factory, err := getFactory()
+	if err != nil {
+		return err
+	}
+
+	model := &tuiModel{service: factory.GetService(), config: config}
+	if err := model.load(days); err != nil {
+		return err
+	}
+
+	if err := runFullScreen(model); err != nil {
+		return err
+	}
+
+	if len(model.applied) == 0 {
+		return nil
+	}
+	if err := printResult(model.applied); err != nil {
+		return err
+	}
+	return model.applied.Err()
+}
+
+// runFullScreen runs the model on the terminal in raw mode and full screen. The terminal is restored
+// however run returns, including by a panic, so the shell is never left unusable.
+func runFullScreen(model *tuiModel) error {
+	restore, err := makeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer restore()
+	fmt.Fprint(os.Stdout, enterFullScreen)
+	defer fmt.Fprint(os.Stdout, leaveFullScreen)
+
+	// keep log messages from drawing over the screen, unless they go to a file
+	if logger, ok := log.Log.(*log.Logger); ok && CLI.LogFile == "" {
+		previous := logger.Handler
+		logger.Handler = discard.Default
+		defer func() { logger.Handler = previous }()
+	}
+	return model.run(bufio.NewReader(os.Stdin), os.Stdout)
+}
+
+// tuiModel is the state of the tui: the grid being edited, the cursor and what is being typed
+type tuiModel struct {
+	service internal.WorklogService
+	config  *internal.Config
+	grid    *internal.Grid
+
+	mode     int
+	row, col int
+	// input is the hours being typed into the current cell
+	input string
+
+	// query is the search text, matching the candidates, of which selected is highlighted
+	query    string
+	issues   internal.Issues
+	matches  internal.Issues
+	selected int
+
+	// message reports the outcome of the last action
+	message string
+	// applied collects the changes saved during the session
+	applied internal.GridChanges
+	done    bool
+}
+
+// load reads the worklogs of a week into a new grid, keeping the rows and summaries of the current one
+func (m *tuiModel) load(days internal.DateRange) error {
+	worklogs, err := internal.WorklogsBetween(context.Background(), m.service, days)
+	if err != nil {
+		return err
+	}
+	grid := internal.NewGrid(days, worklogs)
+	if m.grid != nil {
+		for _, row := range m.grid.Rows {
+			grid.AddRow(row.IssueKey, row.Summary)
+		}
+	}
+	for _, row := range grid.Rows {
+		if row.Summary == "" {
+			if issue, err := m.service.GetIssue(context.Background(), row.IssueKey); err == nil {
+				row.Summary = issue.Summary
+			}
+		}
+	}
+	m.grid = grid
+	m.row = min(m.row, max(len(grid.Rows)-1, 0))
+	return nil
+}
+
+// run draws the tui on out and handles keys read from in until the user quits
+func (m *tuiModel) run(in *bufio.Reader, out io.Writer) error {
+	for !m.done {
+		fmt.Fprint(out, clearScreen+m.render())
+		key, err := readKey(in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return &internal.TempooError{Message: "Failed to read from the terminal", Cause: err}
+		}
+		m.handleKey(key)
+	}
+	return nil
+}
+
+// readKey reads one key press, naming special keys such as up, enter or esc
+func readKey(in *bufio.Reader) (string, error) {
+	b, err := in.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch b {
+	case 0x1b:
+		// a lone escape is the esc key, otherwise it starts the sequence of a special key
+		if in.Buffered() == 0 {
+			return "esc", nil
+		}
+		if next, _ := in.ReadByte(); next != '[' && next != 'O' {
+			return "esc", nil
+		}
+		code, _ := in.ReadByte()
+		switch code {
+		case 'A':
+			return "up", nil
+		case 'B':
+			return "down", nil
+		case 'C':
+			return "right", nil
+		case 'D':
+			return "left", nil
+		case '3':
+			in.ReadByte() // the closing ~
+			return "delete", nil
+		}
+		return "", nil
+	case '\r', '\n':
+		return "enter", nil
+	case 0x7f, 0x08:
+		return "backspace", nil
+	case '\t':
+		return "tab", nil
+	case 0x03:
+		return "ctrl+c", nil
+	}
+	if b < 0x20 {
+		return "", nil
+	}
+	in.UnreadByte()
+	r, _, err := in.ReadRune()
+	return string(r), err
+}
+
+// handleKey applies a key press to the model
+func (m *tuiModel) handleKey(key string) {
+	m.message = ""
+	switch m.mode {
+	case tuiGrid:
+		m.handleGridKey(key)
+	case tuiSearch:
+		m.handleSearchKey(key)
+	case tuiReview:
+		m.mode = tuiGrid
+		if key == "y" {
+			m.save()
+		} else {
+			m.message = "Nothing saved"
+		}
+	case tuiQuit:
+		m.mode = tuiGrid
+		m.done = key == "y"
+	}
+}
+
+// handleGridKey moves around the grid and edits cells
+func (m *tuiModel) handleGridKey(key string) {
+	switch key {
+	case "up", "down", "left", "right", "tab":
+		if !m.setInput() {
+			return
+		}
+		m.move(key)
+	case "enter":
+		if m.setInput() {
+			m.move("down")
+		}
+	case "esc":
+		m.input = ""
+	case "backspace":
+		if m.input != "" {
+			m.input = m.input[:len(m.input)-1]
+		} else {
+			m.clearCell()
+		}
+	case "x", "delete":
+		m.input = ""
+		m.clearCell()
+	case "a":
+		m.input = ""
+		m.startSearch()
+	case "s":
+		if !m.setInput() {
+			return
+		}
+		if len(m.grid.Changes()) == 0 {
+			m.message = "Nothing to save"
+			return
+		}
+		m.mode = tuiReview
+	case "q", "ctrl+c":
+		m.input = ""
+		if len(m.grid.Changes()) == 0 {
+			m.done = true
+			return
+		}
+		m.mode = tuiQuit
+	default:
+		if len(key) == 1 && (key == "." || key >= "0" && key <= "9") && len(m.grid.Rows) > 0 && len(m.input) < 5 {
+			m.input += key
+		}
+	}
+}
+
+// move moves the cursor in a direction, staying inside the grid
+func (m *tuiModel) move(direction string) {
+	switch direction {
+	case "up":
+		m.row = max(m.row-1, 0)
+	case "down":
+		m.row = min(m.row+1, max(len(m.grid.Rows)-1, 0))
+	case "left":
+		m.col = max(m.col-1, 0)
+	case "right", "tab":
+		m.col = min(m.col+1, m.grid.DayCount()-1)
+	}
+}
+
+// setInput enters the hours typed into the current cell, reporting false if they are invalid
+func (m *tuiModel) setInput() bool {
+	if m.input == "" {
+		return true
+	}
+	if err := m.grid.SetHours(m.row, m.col, m.input); err != nil {
+		m.message = err.Error()
+		return false
+	}
+	m.input = ""
+	return true
+}
+
+// clearCell removes the hours of the current cell
+func (m *tuiModel) clearCell() {
+	if len(m.grid.Rows) > 0 {
+		m.grid.SetHours(m.row, m.col, "")
+	}
+}
+
+// startSearch opens the issue search on the recent and assigned issues
+func (m *tuiModel) startSearch() {
+	if m.issues == nil {
+		issues, err := m.service.SearchIssues(context.Background(), internal.RecentAndAssignedJQL, 50)
+		if err != nil {
+			m.message = err.Error()
+			return
+		}
+		m.issues = issues
+	}
+	m.mode, m.query, m.matches, m.selected = tuiSearch, "", m.issues, 0
+}
+
+// handleSearchKey narrows the issue search down and adds the chosen issue as a row
+func (m *tuiModel) handleSearchKey(key string) {
+	switch key {
+	case "esc", "ctrl+c":
+		m.mode = tuiGrid
+	case "up":
+		m.selected = max(m.selected-1, 0)
+	case "down":
+		m.selected = min(m.selected+1, max(min(len(m.matches), pickerSize)-1, 0))
+	case "backspace":
+		if m.query != "" {
+			m.query = m.query[:len(m.query)-1]
+			m.filter()
+		}
+	case "enter":
+		if len(m.matches) > 0 {
+			m.addRow(m.matches[m.selected])
+			return
+		}
+		m.searchJira()
+	default:
+		if len([]rune(key)) == 1 {
+			m.query += key
+			m.filter()
+		}
+	}
+}
+
+// filter narrows the candidates down to the issues matching the query
+func (m *tuiModel) filter() {
+	m.matches, m.selected = internal.FilterIssues(m.issues, m.query), 0
+}
+
+// searchJira looks the query up as an issue key or alias, or else searches Jira for it, when no candidate matches
+func (m *tuiModel) searchJira() {
+	if m.query == "" {
+		return
+	}
+	if !strings.ContainsAny(m.query, " \"") {
+		if issue, err := m.service.GetIssue(context.Background(), m.config.ResolveIssueKey(m.query)); err == nil {
+			m.addRow(*issue)
+			return
+		}
+	}
+	issues, err := m.service.SearchIssues(context.Background(), internal.IssueQuery{Text: m.query}.JQL(), pickerSize)
+	if err != nil {
+		m.message = err.Error()
+		return
+	}
+	if len(issues) == 0 {
+		m.message = fmt.Sprintf("No issue matches '%s'", m.query)
+		return
+	}
+	m.matches, m.selected = issues, 0
+}
+
+// addRow adds a row for an issue and moves the cursor onto it
+func (m *tuiModel) addRow(issue internal.Issue) {
+	m.row = m.grid.AddRow(issue.Key, issue.Summary)
+	m.mode = tuiGrid
+}
+
+// save applies the changes of the grid and reloads the week
+func (m *tuiModel) save() {
+	changes := m.grid.Changes()
+	applied := internal.ApplyGridChanges(context.Background(), m.service, changes)
+	m.applied = append(m.applied, applied...)
+
+	if failed := applied.Failed(); failed > 0 {
+		m.message = fmt.Sprintf("Saved %d of %d change(s): %s", len(applied)-failed, len(applied), applied.Err())
+	} else {
+		m.message = fmt.Sprintf("Saved %d change(s)", len(applied))
+	}
+	if err := m.load(m.grid.Days); err != nil {
+		m.message = err.Error()
+	}
+}
+
+// render draws the whole screen, with lines ended the way a terminal in raw mode needs
+func (m *tuiModel) render() string {
+	var lines []string
+	days := m.grid.Days
+	lines = append(lines, fmt.Sprintf("%stempoo%s  week of %s to %s", styleBold, styleReset, days.From.Format("02.01.2006"), days.To.Format("02.01.2006")), "")
+
+	header := fmt.Sprintf("%-*s", issueColumnWidth, "Issue")
+	for day := 0; day < m.grid.DayCount(); day++ {
+		header += fmt.Sprintf("%*s", dayColumnWidth, m.grid.Day(day).Format("Mon 02"))
+	}
+	lines = append(lines, styleBold+header+fmt.Sprintf("%*s", dayColumnWidth, "Total")+styleReset)
+
+	if len(m.grid.Rows) == 0 {
+		lines = append(lines, "No worklogs this week, press a to add an issue")
+	}
+	for i, row := range m.grid.Rows {
+		line := fmt.Sprintf("%-*s", issueColumnWidth, truncate(row.IssueKey+" "+row.Summary, issueColumnWidth-1))
+		total := 0.0
+		for day, cell := range row.Cells {
+			total += cell.Hours
+			text := formatCellHours(cell.Hours)
+			if cell.Changed() {
+				text += "*"
+			} else {
+				text += " "
+			}
+			if i == m.row && day == m.col && m.mode == tuiGrid {
+				if m.input != "" {
+					text = m.input + "_"
+				}
+				line += fmt.Sprintf("%*s", dayColumnWidth-len(text), "") + styleReverse + text + styleReset
+				continue
+			}
+			line += fmt.Sprintf("%*s", dayColumnWidth, text)
+		}
+		lines = append(lines, line+fmt.Sprintf("%*s", dayColumnWidth, formatCellHours(total)+" "))
+	}
+
+	totals := fmt.Sprintf("%-*s", issueColumnWidth, "Total / target")
+	week := 0.0
+	for day, total := range m.grid.Totals() {
+		week += total
+		target := m.config.TargetHours(m.grid.Day(day))
+		text := fmt.Sprintf("%s/%s ", formatCellHours(total), formatCellHours(target))
+		totals += fmt.Sprintf("%*s", dayColumnWidth-len(text), "") + targetStyle(total, target) + text + styleReset
+	}
+	lines = append(lines, "", styleBold+totals+styleReset+fmt.Sprintf("%*s", dayColumnWidth, formatCellHours(week)+" "))
+
+	lines = append(lines, "")
+	switch m.mode {
+	case tuiSearch:
+		lines = append(lines, "Add issue: "+m.query+"_")
+		for i, issue := range m.matches {
+			if i == pickerSize {
+				lines = append(lines, fmt.Sprintf("  ... %d more, type to filter", len(m.matches)-pickerSize))
+				break
+			}
+			line := "  " + issue.Key + " " + issue.Summary
+			if i == m.selected {
+				line = styleReverse + "> " + issue.Key + " " + issue.Summary + styleReset
+			}
+			lines = append(lines, line)
+		}
+	case tuiReview:
+		changes := m.grid.Changes()
+		lines = append(lines, strings.Split(changes.Text(), "\n")...)
+		lines = append(lines, fmt.Sprintf("Save these %d change(s)?", len(changes)))
+	case tuiQuit:
+		lines = append(lines, fmt.Sprintf("Discard %d unsaved change(s)?", len(m.grid.Changes())))
+	}
+	if m.message != "" {
+		lines = append(lines, m.message)
+	}
+	lines = append(lines, "", tuiHelp[m.mode])
+	return strings.Join(lines, "\r\n")
+}
+
+// formatCellHours formats hours compactly, showing a dot for none
+func formatCellHours(hours float64) string {
+	if hours == 0 {
+		return "."
+	}
+	return strconv.FormatFloat(hours, 'f', -1, 64)
+}
+
+// targetStyle colours a day's total: green when it meets the target, yellow below it and red above it
+func targetStyle(total, target float64) string {
+	switch {
+	case total == target:
+		return styleGreen
+	case total < target:
+		return styleYellow
+	default:
+		return styleRed
+	}
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
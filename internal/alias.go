@@ -0,0 +1,169 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// aliasesKey is the config file key holding the issue key aliases
+const aliasesKey = "aliases"
+
+var (
+	// issueKeyPattern matches a Jira issue key such as PROJ-123
+	issueKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*-[0-9]+$`)
+	// aliasNamePattern matches a valid alias name
+	aliasNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+)
+
+// Alias is a short name standing for an issue key
+type Alias struct {
+	Name     string `json:"name"`
+	IssueKey string `json:"issue_key"`
+	Summary  string `json:"summary,omitempty"`
+}
+
+// Aliases is a list of aliases
+type Aliases []Alias
+
+// ResolveIssueKey returns the issue key an alias stands for, or key itself if it is not an alias
+func (c *Config) ResolveIssueKey(key string) string {
+	if issueKey, ok := c.Aliases[key]; ok {
+		return issueKey
+	}
+	return key
+}
+
+// AliasList returns the configured aliases sorted by name
+func (c *Config) AliasList() Aliases {
+	aliases := Aliases{}
+	for name, issueKey := range c.Aliases {
+		aliases = append(aliases, Alias{Name: name, IssueKey: issueKey})
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	return aliases
+}
+
+// ValidateAlias checks that name can be used as an alias for issueKey.
+// An alias must not look like an issue key itself, so keys and aliases can never be confused.
+func ValidateAlias(name, issueKey string) error {
+	if !aliasNamePattern.MatchString(name) {
+		return &TempooError{Message: fmt.Sprintf("Invalid alias '%s'. Use letters, digits, '.', '_' and '-'", name)}
+	}
+	if issueKeyPattern.MatchString(name) {
+		return &TempooError{Message: fmt.Sprintf("Invalid alias '%s'. An alias cannot look like an issue key", name)}
+	}
+	if !issueKeyPattern.MatchString(issueKey) {
+		return &TempooError{Message: fmt.Sprintf("Invalid issue key '%s' for alias '%s'. Expected a key like PROJ-123", issueKey, name)}
+	}
+	return nil
+}
+
+// SetAlias adds or replaces an alias in the config file at path, keeping the rest of the file and its comments as they are
+func SetAlias(path, name, issueKey string) error {
+	if err := ValidateAlias(name, issueKey); err != nil {
+		return err
+	}
+
+	return editConfigFile(path, func(root *yaml.Node) error {
+		aliases := aliasMapping(root)
+		if value := mappingValue(aliases, name); value != nil {
+			value.SetString(issueKey)
+			return nil
+		}
+
+		key, value := &yaml.Node{}, &yaml.Node{}
+		key.SetString(name)
+		value.SetString(issueKey)
+		aliases.Content = append(aliases.Content, key, value)
+		return nil
+	})
+}
+
+// RemoveAlias deletes an alias from the config file at path, keeping the rest of the file and its comments as they are
+func RemoveAlias(path, name string) error {
+	return editConfigFile(path, func(root *yaml.Node) error {
+		aliases := aliasMapping(root)
+		for i := 0; i < len(aliases.Content); i += 2 {
+			if aliases.Content[i].Value == name {
+				aliases.Content = append(aliases.Content[:i], aliases.Content[i+2:]...)
+				return nil
+			}
+		}
+		return &TempooError{Message: fmt.Sprintf("Alias '%s' is not defined", name)}
+	})
+}
+
+// editConfigFile applies fn to the top level mapping of the config file at path and writes it back.
+// The result is checked like a loaded config before it replaces the file.
+func editConfigFile(path string, fn func(root *yaml.Node) error) error {
+	return withFileLock(path, func() error {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return &TempooError{Message: fmt.Sprintf("Failed to read config file %s", path), Cause: err}
+		}
+
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return &TempooError{Message: fmt.Sprintf("Failed to parse config file %s", path), Cause: err}
+		}
+		if doc.Kind == 0 {
+			doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+		}
+		root := doc.Content[0]
+		if root.Kind != yaml.MappingNode {
+			return &TempooError{Message: fmt.Sprintf("Failed to parse config file %s: expected a mapping", path)}
+		}
+
+		if err := fn(root); err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&doc); err != nil {
+			return &TempooError{Message: fmt.Sprintf("Failed to encode config file %s", path), Cause: err}
+		}
+
+		config := DefaultConfig()
+		if err := yaml.Unmarshal(buf.Bytes(), config); err != nil {
+			return &TempooError{Message: fmt.Sprintf("Failed to parse config file %s", path), Cause: err}
+		}
+		if err := config.validate(); err != nil {
+			return err
+		}
+		return writeFileAtomic(path, buf.Bytes())
+	})
+}
+
+// aliasMapping returns the aliases mapping of the config file, creating it when missing
+func aliasMapping(root *yaml.Node) *yaml.Node {
+	if value := mappingValue(root, aliasesKey); value != nil {
+		if value.Kind != yaml.MappingNode {
+			// "aliases:" without entries decodes as null
+			*value = yaml.Node{Kind: yaml.MappingNode}
+		}
+		return value
+	}
+
+	key, value := &yaml.Node{}, &yaml.Node{Kind: yaml.MappingNode}
+	key.SetString(aliasesKey)
+	root.Content = append(root.Content, key, value)
+	return value
+}
+
+// mappingValue returns the value of key in a mapping node, or nil if it is not set
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
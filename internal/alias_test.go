@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		name        string
+		issueKey    string
+		expectError bool
+	}{
+		{"standup", "INF-12", false},
+		{"support.rota", "OPS-7", false},
+		{"INF-12", "INF-12", true},
+		{"has space", "INF-12", true},
+		{"", "INF-12", true},
+		{"standup", "INF", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateAlias(tt.name, tt.issueKey)
+		if (err != nil) != tt.expectError {
+			t.Errorf("ValidateAlias(%q, %q) error = %v, expectError %v", tt.name, tt.issueKey, err, tt.expectError)
+		}
+	}
+}
+
+func TestConfig_ResolveIssueKey(t *testing.T) {
+	config := &Config{Aliases: map[string]string{"standup": "INF-12"}}
+
+	if got := config.ResolveIssueKey("standup"); got != "INF-12" {
+		t.Errorf("Expected alias to resolve to INF-12, got %s", got)
+	}
+	if got := config.ResolveIssueKey("INF-88"); got != "INF-88" {
+		t.Errorf("Expected issue key to stay as it is, got %s", got)
+	}
+}
+
+func TestSetAlias_KeepsConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	content := "# my settings\nrounding:\n  mode: up # round up\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetAlias(path, "standup", "INF-12"); err != nil {
+		t.Fatalf("SetAlias failed: %v", err)
+	}
+	if err := SetAlias(path, "support", "OPS-7"); err != nil {
+		t.Fatalf("SetAlias failed: %v", err)
+	}
+	if err := SetAlias(path, "standup", "INF-13"); err != nil {
+		t.Fatalf("SetAlias failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	for _, comment := range []string{"# my settings", "# round up"} {
+		if !strings.Contains(string(data), comment) {
+			t.Errorf("Expected comment %q to be kept, got:\n%s", comment, data)
+		}
+	}
+
+	config, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load edited config: %v", err)
+	}
+	if config.Rounding.Mode != RoundUp {
+		t.Errorf("Expected rounding mode to be kept, got %s", config.Rounding.Mode)
+	}
+	aliases := config.AliasList()
+	if len(aliases) != 2 || aliases[0] != (Alias{Name: "standup", IssueKey: "INF-13"}) || aliases[1] != (Alias{Name: "support", IssueKey: "OPS-7"}) {
+		t.Errorf("Unexpected aliases %+v", aliases)
+	}
+
+	if err := RemoveAlias(path, "standup"); err != nil {
+		t.Fatalf("RemoveAlias failed: %v", err)
+	}
+	if err := RemoveAlias(path, "standup"); err == nil {
+		t.Error("Expected an error removing an undefined alias")
+	}
+	config, _ = LoadConfigFile(path)
+	if _, ok := config.Aliases["standup"]; ok || config.Aliases["support"] != "OPS-7" {
+		t.Errorf("Unexpected aliases after removal %+v", config.Aliases)
+	}
+}
+
+func TestSetAlias_NewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+
+	if err := SetAlias(path, "standup", "INF-12"); err != nil {
+		t.Fatalf("SetAlias failed: %v", err)
+	}
+	config, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load new config: %v", err)
+	}
+	if config.ResolveIssueKey("standup") != "INF-12" {
+		t.Errorf("Unexpected aliases %+v", config.Aliases)
+	}
+}
+
+func TestLoadConfigFile_InvalidAlias(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := os.WriteFile(path, []byte("aliases:\n  INF-1: INF-2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfigFile(path); err == nil {
+		t.Error("Expected an error for an alias that looks like an issue key")
+	}
+}
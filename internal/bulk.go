@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// bulk deletion tuning, variables so tests can shorten them
+var (
+	// bulkWorkers is how many deletions run at once
+	bulkWorkers = 4
+	// bulkInterval is the minimum time between two deletion requests, across all workers
+	bulkInterval = 200 * time.Millisecond
+	// bulkAttempts is how often a rate limited deletion is tried before giving up
+	bulkAttempts = 3
+	// bulkRetryAfter is how long to back off when Jira rate limits without saying for how long
+	bulkRetryAfter = 2 * time.Second
+)
+
+// FailedDeletion is a worklog that could not be deleted, with the reason
+type FailedDeletion struct {
+	WorklogID string `json:"worklog_id"`
+	Error     string `json:"error"`
+	err       error
+}
+
+// DeleteResult summarises a bulk deletion
+type DeleteResult struct {
+	IssueKey string   `json:"issue_key"`
+	Deleted  []string `json:"deleted"`
+	// Queued are the deletions queued because Jira was unreachable, to be made by sync
+	Queued []string         `json:"queued,omitempty"`
+	Failed []FailedDeletion `json:"failed,omitempty"`
+}
+
+// pacedService is a service whose calls can make several requests, such as the Jira client fetching a
+// worklog for the history before deleting it. withPacing returns the service calling wait before each request.
+type pacedService interface {
+	withPacing(wait func()) WorklogService
+}
+
+// Err returns an error describing the failed deletions, wrapping the first failure so its
+// class can be told with errors.As, or nil if every deletion succeeded
+func (r *DeleteResult) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	return &TempooError{
+		Message: fmt.Sprintf("Failed to delete %d of %d worklog(s) from %s", len(r.Failed), len(r.Failed)+len(r.Deleted)+len(r.Queued), r.IssueKey),
+		Cause:   r.Failed[0].err,
+	}
+}
+
+// DeleteWorklogs deletes worklogs from an issue through a bounded pool of workers sharing a rate limit,
+// which covers every request of a deletion when the service supports it. Failures do not stop the other
+// deletions; the result lists what was deleted, queued and what failed, in the given order.
+func DeleteWorklogs(ctx context.Context, service WorklogService, issueKey string, worklogIDs []string) *DeleteResult {
+	errs := make([]error, len(worklogIDs))
+	limiter := &rateLimiter{interval: bulkInterval}
+	// pace each deletion as a whole, unless the service paces each of its requests
+	wait := limiter.wait
+	if paced, ok := service.(pacedService); ok {
+		service, wait = paced.withPacing(limiter.wait), func() {}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(bulkWorkers, len(worklogIDs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = deleteWithRetry(ctx, service, limiter, wait, issueKey, worklogIDs[i])
+			}
+		}()
+	}
+	for i := range worklogIDs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	result := &DeleteResult{IssueKey: issueKey, Deleted: []string{}}
+	for i, id := range worklogIDs {
+		var queued *QueuedError
+		switch {
+		case errors.As(errs[i], &queued):
+			result.Queued = append(result.Queued, id)
+		case errs[i] != nil:
+			result.Failed = append(result.Failed, FailedDeletion{WorklogID: id, Error: errs[i].Error(), err: errs[i]})
+		default:
+			result.Deleted = append(result.Deleted, id)
+		}
+	}
+	return result
+}
+
+// deleteWithRetry deletes one worklog after calling wait, backing off and retrying when Jira rate limits the request
+func deleteWithRetry(ctx context.Context, service WorklogService, limiter *rateLimiter, wait func(), issueKey, worklogID string) error {
+	for attempt := 1; ; attempt++ {
+		wait()
+		err := service.DeleteWorklog(ctx, issueKey, worklogID)
+
+		var rateLimited *RateLimitError
+		if !errors.As(err, &rateLimited) || attempt >= bulkAttempts {
+			return err
+		}
+
+		delay := rateLimited.RetryAfter
+		if delay <= 0 {
+			delay = bulkRetryAfter
+		}
+		// hold back every worker, not just this one
+		limiter.pause(delay)
+	}
+}
+
+// rateLimiter spaces out requests made from several goroutines
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until the next request may be made
+func (l *rateLimiter) wait() {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	time.Sleep(time.Until(slot))
+}
+
+// pause delays every request for at least d from now
+func (l *rateLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if resume := time.Now().Add(d); resume.After(l.next) {
+		l.next = resume
+	}
+}
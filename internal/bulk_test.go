@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// flakyService fails deletions of some worklogs and rate limits others once, tracking concurrency
+type flakyService struct {
+	*MemoryService
+	forbidden   string
+	rateLimited string
+
+	mu         sync.Mutex
+	limited    bool
+	active     int
+	maxActive  int
+	deleteCall int
+}
+
+func (s *flakyService) DeleteWorklog(ctx context.Context, issueKey, worklogID string) error {
+	s.mu.Lock()
+	s.deleteCall++
+	s.active++
+	s.maxActive = max(s.maxActive, s.active)
+	limit := worklogID == s.rateLimited && !s.limited
+	if limit {
+		s.limited = true
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	switch {
+	case worklogID == s.forbidden:
+		return &PermissionError{APIError{TempooError: TempooError{Message: "Failed to delete worklog: 403 Forbidden"}, StatusCode: 403}}
+	case limit:
+		return &RateLimitError{APIError: APIError{TempooError: TempooError{Message: "Failed to delete worklog: 429"}, StatusCode: 429}, RetryAfter: 10 * time.Millisecond}
+	}
+	return s.MemoryService.DeleteWorklog(ctx, issueKey, worklogID)
+}
+
+func TestDeleteWorklogs(t *testing.T) {
+	defer func(workers int, interval time.Duration) { bulkWorkers, bulkInterval = workers, interval }(bulkWorkers, bulkInterval)
+	bulkWorkers, bulkInterval = 2, time.Millisecond
+
+	memory := NewMemoryService(User{AccountID: "user-1"}, Issue{Key: "TEST-1"})
+	var ids []string
+	for i := 0; i < 6; i++ {
+		worklog, _ := memory.AddWorklogAt(t.Context(), "TEST-1", time.Now(), time.Hour)
+		ids = append(ids, worklog.ID)
+	}
+	service := &flakyService{MemoryService: memory, forbidden: ids[1], rateLimited: ids[3]}
+
+	result := DeleteWorklogs(t.Context(), service, "TEST-1", append(ids, "missing"))
+
+	if len(result.Deleted) != 5 || result.Deleted[0] != ids[0] || result.Deleted[2] != ids[3] {
+		t.Errorf("Deleted = %v, want every ID but %s in order", result.Deleted, ids[1])
+	}
+	if len(result.Failed) != 2 || result.Failed[0].WorklogID != ids[1] || result.Failed[1].WorklogID != "missing" {
+		t.Errorf("Failed = %+v", result.Failed)
+	}
+	if service.maxActive > 2 {
+		t.Errorf("Expected at most 2 concurrent deletions, got %d", service.maxActive)
+	}
+	if service.deleteCall != 8 {
+		t.Errorf("Expected 8 delete calls including one retry, got %d", service.deleteCall)
+	}
+
+	err := result.Err()
+	var permissionErr *PermissionError
+	if !errors.As(err, &permissionErr) {
+		t.Errorf("Err() = %v, want it to wrap the first failure", err)
+	}
+	if remaining, _ := memory.ListWorklogs(t.Context(), "TEST-1"); len(remaining) != 1 {
+		t.Errorf("Expected only the forbidden worklog to remain, got %+v", remaining)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := &rateLimiter{interval: 20 * time.Millisecond}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		limiter.wait()
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected 3 requests to take at least 40ms, took %s", elapsed)
+	}
+}
+
+func TestDeleteWorklogs_PacesEveryRequest(t *testing.T) {
+	defer func(interval time.Duration) { bulkInterval = interval }(bulkInterval)
+	bulkInterval = 20 * time.Millisecond
+
+	server, client := newFakeJira(t)
+	client.EnableHistory(NewHistory(t.TempDir()))
+	var ids []string
+	for i := 0; i < 2; i++ {
+		worklog, err := client.AddWorklogAt(t.Context(), "TEST-1", time.Now(), time.Hour)
+		if err != nil {
+			t.Fatalf("AddWorklogAt() error = %v", err)
+		}
+		ids = append(ids, worklog.ID)
+	}
+
+	start := time.Now()
+	result := DeleteWorklogs(t.Context(), client, "TEST-1", ids)
+	if len(result.Deleted) != 2 || len(server.Worklogs("TEST-1")) != 0 {
+		t.Fatalf("Expected both worklogs deleted, got %+v", result)
+	}
+	// each deletion fetches the worklog for the history, so four requests are spaced out
+	if elapsed := time.Since(start); elapsed < 3*bulkInterval {
+		t.Errorf("Expected the snapshot requests to be rate limited too, took %s", elapsed)
+	}
+}
+
+func TestDeleteWorklogs_Queued(t *testing.T) {
+	queue := NewQueue(t.TempDir())
+	// nothing listens on this port, so the request fails in transport
+	client := resty.New().SetTransport(&redirectTransport{target: &url.URL{Scheme: "http", Host: "127.0.0.1:1"}})
+	tempoo := &Tempoo{client: client}
+	tempoo.EnableOfflineQueue(queue)
+
+	result := DeleteWorklogs(t.Context(), tempoo, "TEST-1", []string{"10001"})
+	if len(result.Deleted) != 0 || len(result.Failed) != 0 || len(result.Queued) != 1 || result.Err() != nil {
+		t.Errorf("Expected the deletion to be reported as queued, got %+v", result)
+	}
+	if ops, _ := queue.List(); len(ops) != 1 || ops[0].Op != OpDeleteWorklog {
+		t.Errorf("Expected a queued deletion, got %+v", ops)
+	}
+}
@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apex/log"
+)
+
+const (
+	// CacheFileName is the name of the identity and issue cache inside the tempoo home directory
+	CacheFileName = "cache.json"
+	// CacheTTL is how long cached identities and issues are trusted before they are fetched again
+	CacheTTL = 24 * time.Hour
+)
+
+// Cache keeps the current user's identity and the issue keys known to exist on disk, so commands
+// do not have to ask Jira again. Entries are scoped by Jira site and email and expire after a TTL.
+type Cache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// cacheFile is the content of the cache file, by scope
+type cacheFile map[string]*cacheScope
+
+// cacheScope holds what is cached for one Jira site and user
+type cacheScope struct {
+	Identity *cachedIdentity        `json:"identity,omitempty"`
+	Issues   map[string]cachedIssue `json:"issues,omitempty"`
+}
+
+// cachedIdentity is a cached current user
+type cachedIdentity struct {
+	User     User      `json:"user"`
+	CachedAt time.Time `json:"cached_at"`
+}
+
+// cachedIssue is an issue key known to exist, with its summary
+type cachedIssue struct {
+	Summary  string    `json:"summary"`
+	CachedAt time.Time `json:"cached_at"`
+}
+
+// NewCache returns the cache stored in dir, whose entries expire after ttl
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{path: filepath.Join(dir, CacheFileName), ttl: ttl, now: time.Now}
+}
+
+// Identity returns the cached user for a scope, if there is one that has not expired
+func (c *Cache) Identity(scope string) (*User, bool) {
+	var user *User
+	c.view(func(file cacheFile) {
+		if s := file[scope]; s != nil && s.Identity != nil && c.fresh(s.Identity.CachedAt) {
+			identity := s.Identity.User
+			user = &identity
+		}
+	})
+	return user, user != nil
+}
+
+// SetIdentity caches the user for a scope
+func (c *Cache) SetIdentity(scope string, user *User) {
+	c.update(func(file cacheFile) {
+		file.scope(scope).Identity = &cachedIdentity{User: *user, CachedAt: c.now()}
+	})
+}
+
+// Issue returns the cached summary of an issue key known to exist in a scope
+func (c *Cache) Issue(scope, issueKey string) (string, bool) {
+	var summary string
+	found := false
+	c.view(func(file cacheFile) {
+		if s := file[scope]; s != nil {
+			if issue, ok := s.Issues[issueKey]; ok && c.fresh(issue.CachedAt) {
+				summary, found = issue.Summary, true
+			}
+		}
+	})
+	return summary, found
+}
+
+// SetIssue caches an issue key known to exist in a scope, with its summary
+func (c *Cache) SetIssue(scope, issueKey, summary string) {
+	c.update(func(file cacheFile) {
+		s := file.scope(scope)
+		if s.Issues == nil {
+			s.Issues = map[string]cachedIssue{}
+		}
+		s.Issues[issueKey] = cachedIssue{Summary: summary, CachedAt: c.now()}
+	})
+}
+
+// Clear deletes everything cached
+func (c *Cache) Clear() error {
+	return withFileLock(c.path, func() error {
+		if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return &TempooError{Message: "Failed to clear cache", Cause: err}
+		}
+		return nil
+	})
+}
+
+// fresh reports whether an entry cached at the given time has not expired
+func (c *Cache) fresh(cachedAt time.Time) bool {
+	return c.now().Sub(cachedAt) < c.ttl
+}
+
+// scope returns the entries of a scope, creating them if needed
+func (f cacheFile) scope(name string) *cacheScope {
+	if f[name] == nil {
+		f[name] = &cacheScope{}
+	}
+	return f[name]
+}
+
+// view runs fn on the cache content. The cache is only an optimisation, so failures are logged and ignored.
+func (c *Cache) view(fn func(cacheFile)) {
+	err := withFileLock(c.path, func() error {
+		file, err := c.read()
+		if err != nil {
+			return err
+		}
+		fn(file)
+		return nil
+	})
+	if err != nil {
+		log.Debugf("Ignoring unreadable cache: %v", err)
+	}
+}
+
+// update applies fn to the cache content and writes it back, logging and ignoring failures
+func (c *Cache) update(fn func(cacheFile)) {
+	err := withFileLock(c.path, func() error {
+		file, err := c.read()
+		if err != nil {
+			// start over rather than keep failing on a corrupt cache
+			file = cacheFile{}
+		}
+		fn(file)
+
+		data, err := json.MarshalIndent(file, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(c.path, data)
+	})
+	if err != nil {
+		log.Debugf("Failed to update cache: %v", err)
+	}
+}
+
+// read loads the cache file, returning an empty cache if it does not exist yet
+func (c *Cache) read() (cacheFile, error) {
+	file := cacheFile{}
+
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", c.path, err)
+	}
+	return file, nil
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCache_ExpiresAndClears(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Hour)
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	if _, ok := cache.Identity("site|a@example.com"); ok {
+		t.Error("Expected an empty cache")
+	}
+
+	cache.SetIdentity("site|a@example.com", &User{AccountID: "user-1", TimeZone: "Europe/London"})
+	cache.SetIssue("site|a@example.com", "TEST-1", "Test issue")
+
+	if user, ok := cache.Identity("site|a@example.com"); !ok || user.AccountID != "user-1" || user.TimeZone != "Europe/London" {
+		t.Errorf("Identity() = %+v, %v", user, ok)
+	}
+	if _, ok := cache.Identity("site|b@example.com"); ok {
+		t.Error("Expected identities to be scoped by email")
+	}
+	if summary, ok := cache.Issue("site|a@example.com", "TEST-1"); !ok || summary != "Test issue" {
+		t.Errorf("Issue() = %q, %v", summary, ok)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := cache.Identity("site|a@example.com"); ok {
+		t.Error("Expected the identity to expire")
+	}
+	if _, ok := cache.Issue("site|a@example.com", "TEST-1"); ok {
+		t.Error("Expected the issue to expire")
+	}
+
+	cache.SetIssue("site|a@example.com", "TEST-2", "")
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, ok := cache.Issue("site|a@example.com", "TEST-2"); ok {
+		t.Error("Expected Clear() to forget issues")
+	}
+}
+
+func TestTempoo_CachesIdentityAndIssues(t *testing.T) {
+	requests := map[string]int{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/3/myself", func(w http.ResponseWriter, r *http.Request) {
+		requests["myself"]++
+		json.NewEncoder(w).Encode(map[string]string{"accountId": "user-1", "timeZone": "Europe/London"})
+	})
+	mux.HandleFunc("GET /rest/api/3/issue/TEST-1", func(w http.ResponseWriter, r *http.Request) {
+		requests["issue"]++
+		if r.URL.Query().Get("fields") != "summary" {
+			t.Errorf("Expected only the summary to be fetched, got %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"key":"TEST-1","fields":{"summary":"Test issue"}}`))
+	})
+	mux.HandleFunc("GET /rest/api/3/issue/TEST-1/worklog", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"total":0,"worklogs":[]}`))
+	})
+	tempoo := newTestTempoo(t, mux)
+	cache := NewCache(t.TempDir(), time.Hour)
+	tempoo.EnableCache(cache)
+
+	for i := 0; i < 3; i++ {
+		if _, err := tempoo.ListWorklogs(t.Context(), "TEST-1"); err != nil {
+			t.Fatalf("ListWorklogs() error = %v", err)
+		}
+	}
+	if requests["myself"] != 1 || requests["issue"] != 1 {
+		t.Errorf("Expected one request each for the user and the issue, got %v", requests)
+	}
+
+	user, err := tempoo.WhoAmI(t.Context())
+	if err != nil || user.TimeZone != "Europe/London" {
+		t.Errorf("WhoAmI() = %+v, %v", user, err)
+	}
+	if summary, _ := cache.Issue(tempoo.cacheScope(), "TEST-1"); summary != "Test issue" {
+		t.Errorf("Expected the summary to be cached, got %q", summary)
+	}
+}
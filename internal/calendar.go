@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+// CalendarConfig controls how calendar events become worklogs
+type CalendarConfig struct {
+	// Email identifies you among the attendees of events, defaulting to the Jira email
+	Email string `yaml:"email"`
+	// Rules map events to issue keys, the first matching rule wins
+	Rules []CalendarRule `yaml:"rules"`
+}
+
+// CalendarRule maps the events matching all of its conditions to an issue key or alias
+type CalendarRule struct {
+	// Title is a regular expression matched against the event title
+	Title string `yaml:"title"`
+	// Organizer is the email address of the organiser
+	Organizer string `yaml:"organizer"`
+	// Category is one of the event's categories
+	Category string `yaml:"category"`
+	IssueKey string `yaml:"issue_key"`
+}
+
+// validate checks every rule has a condition, a valid title pattern and an issue key
+func (c CalendarConfig) validate() error {
+	for i, rule := range c.Rules {
+		if rule.Title == "" && rule.Organizer == "" && rule.Category == "" {
+			return &TempooError{Message: fmt.Sprintf("Calendar rule %d needs a title, organizer or category", i+1)}
+		}
+		if _, err := regexp.Compile(rule.Title); err != nil {
+			return &TempooError{Message: fmt.Sprintf("Invalid title pattern '%s' in calendar rule %d", rule.Title, i+1), Cause: err}
+		}
+		if rule.IssueKey == "" {
+			return &TempooError{Message: fmt.Sprintf("Calendar rule %d needs an issue key", i+1)}
+		}
+	}
+	return nil
+}
+
+// matches reports whether an event meets all the conditions of the rule
+func (r CalendarRule) matches(event Event) bool {
+	if r.Title != "" {
+		if matched, err := regexp.MatchString(r.Title, event.Summary); err != nil || !matched {
+			return false
+		}
+	}
+	if r.Organizer != "" && !strings.EqualFold(r.Organizer, event.Organizer) {
+		return false
+	}
+	if r.Category != "" {
+		found := false
+		for _, category := range event.Categories {
+			found = found || strings.EqualFold(r.Category, category)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// IssueKey returns the issue key or alias of the first rule matching an event, or else the issue key in its title
+func (c CalendarConfig) IssueKey(event Event) (string, bool) {
+	for _, rule := range c.Rules {
+		if rule.matches(event) {
+			return rule.IssueKey, true
+		}
+	}
+	if issueKey := issueKeyInText.FindString(event.Summary); issueKey != "" {
+		return issueKey, true
+	}
+	return "", false
+}
+
+// Attending reports whether the user with the given email takes part in an event: it is not cancelled, and
+// they organise it, accepted it, or are not among its attendees as it is their own
+func Attending(event Event, email string) bool {
+	if event.Status == "CANCELLED" {
+		return false
+	}
+	if email == "" || strings.EqualFold(event.Organizer, email) {
+		return true
+	}
+	for _, attendee := range event.Attendees {
+		if strings.EqualFold(attendee.Email, email) {
+			return attendee.PartStat == "ACCEPTED"
+		}
+	}
+	return true
+}
+
+// CalendarWorklogs drafts worklogs for the events during a range of days that the user attends,
+// mapped to issue keys by the calendar rules. Each event's duration is rounded with the configured
+// rounding, and events on the same day and issue are combined. All-day events and events without
+// an issue are skipped.
+func CalendarWorklogs(events []Event, days DateRange, calendar CalendarConfig, email string, rounding RoundingConfig) *Draft {
+	builder := draftBuilder{}
+	for _, event := range ExpandEvents(events, days) {
+		day := event.Start.Format(draftDateFormat)
+		switch issueKey, ok := calendar.IssueKey(event); {
+		case event.AllDay || event.Duration() <= 0:
+			log.Debugf("Skipping '%s' on %s, it has no duration", event.Summary, day)
+		case !Attending(event, email):
+			log.Debugf("Skipping '%s' on %s, it is cancelled or not accepted", event.Summary, day)
+		case !ok:
+			log.Infof("Skipping '%s' on %s, no rule maps it to an issue", event.Summary, day)
+		default:
+			builder.add(event.Start, issueKey, rounding.Round(event.Duration()), event.Summary)
+		}
+	}
+	return builder.draft(rounding, func(titles []string) string { return strings.Join(titles, ", ") })
+}
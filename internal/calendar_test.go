@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarConfig_IssueKey(t *testing.T) {
+	calendar := CalendarConfig{Rules: []CalendarRule{
+		{Title: "(?i)standup", IssueKey: "standup"},
+		{Organizer: "boss@example.com", Category: "1:1", IssueKey: "INF-1"},
+		{Category: "support", IssueKey: "OPS-7"},
+	}}
+
+	tests := []struct {
+		event    Event
+		expected string
+	}{
+		{Event{Summary: "Daily Standup"}, "standup"},
+		{Event{Summary: "Catch up", Organizer: "boss@example.com", Categories: []string{"1:1"}}, "INF-1"},
+		{Event{Summary: "Catch up", Organizer: "boss@example.com"}, ""},
+		{Event{Summary: "Rota", Categories: []string{"Meeting", "Support"}}, "OPS-7"},
+		{Event{Summary: "Review of INF-88"}, "INF-88"},
+		{Event{Summary: "Lunch"}, ""},
+	}
+
+	for _, tt := range tests {
+		issueKey, _ := calendar.IssueKey(tt.event)
+		if issueKey != tt.expected {
+			t.Errorf("IssueKey(%q) = %q, want %q", tt.event.Summary, issueKey, tt.expected)
+		}
+	}
+}
+
+func TestAttending(t *testing.T) {
+	me := "me@example.com"
+	tests := []struct {
+		name     string
+		event    Event
+		expected bool
+	}{
+		{"own event", Event{}, true},
+		{"organiser", Event{Organizer: me, Attendees: []Attendee{{Email: "other@example.com"}}}, true},
+		{"accepted", Event{Attendees: []Attendee{{Email: me, PartStat: "ACCEPTED"}}}, true},
+		{"declined", Event{Attendees: []Attendee{{Email: me, PartStat: "DECLINED"}}}, false},
+		{"not answered", Event{Attendees: []Attendee{{Email: me, PartStat: "NEEDS-ACTION"}}}, false},
+		{"cancelled", Event{Status: "CANCELLED"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := Attending(tt.event, me); got != tt.expected {
+			t.Errorf("%s: Attending = %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestCalendarWorklogs(t *testing.T) {
+	events, err := ParseICS(strings.NewReader(testCalendar))
+	if err != nil {
+		t.Fatalf("ParseICS failed: %v", err)
+	}
+	days := DateRange{From: time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC), To: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)}
+	calendar := CalendarConfig{Rules: []CalendarRule{
+		{Title: "standup", IssueKey: "INF-12"},
+		{Category: "release", IssueKey: "INF-20"},
+	}}
+
+	draft := CalendarWorklogs(events, days, calendar, "me@example.com", RoundingConfig{GranularityMinutes: 15, Mode: RoundUp})
+
+	// the declined review is left out
+	expected := []DraftWorklog{
+		{Date: "30.06.2025", IssueKey: "INF-12", Hours: 0.25, Note: "Daily standup"},
+		{Date: "01.07.2025", IssueKey: "INF-12", Hours: 0.25, Note: "Daily standup"},
+	}
+	if len(draft.Worklogs) != len(expected) {
+		t.Fatalf("Expected %d worklogs, got %+v", len(expected), draft.Worklogs)
+	}
+	for i := range expected {
+		if draft.Worklogs[i] != expected[i] {
+			t.Errorf("Worklog %d: expected %+v, got %+v", i, expected[i], draft.Worklogs[i])
+		}
+	}
+
+	// the default granularity never logs less than half an hour
+	draft = CalendarWorklogs(events, days, calendar, "me@example.com", DefaultConfig().Rounding)
+	for _, worklog := range draft.Worklogs {
+		if worklog.Hours != 0.5 {
+			t.Errorf("Expected the standup rounded to 0.5 hours, got %+v", worklog)
+		}
+	}
+}
+
+func TestLoadConfigFile_InvalidCalendarRule(t *testing.T) {
+	for _, content := range []string{
+		"calendar:\n  rules:\n    - issue_key: INF-1\n",
+		"calendar:\n  rules:\n    - title: \"(\"\n      issue_key: INF-1\n",
+		"calendar:\n  rules:\n    - title: standup\n",
+	} {
+		path := t.TempDir() + "/" + ConfigFileName
+		writeFile(t, path, content)
+		if _, err := LoadConfigFile(path); err == nil {
+			t.Errorf("Expected an error for %q", content)
+		}
+	}
+}
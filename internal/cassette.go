@@ -0,0 +1,308 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+)
+
+// redacted replaces secrets in recorded cassettes
+const redacted = "REDACTED"
+
+// Interaction is a recorded HTTP request and the response it got, stored as one cassette file
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the request half of an interaction
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the response half of an interaction
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// RecordingTransport passes requests on to the network and writes each request and response,
+// with credentials redacted, to a numbered cassette file in a directory
+type RecordingTransport struct {
+	dir  string
+	next http.RoundTripper
+
+	mu    sync.Mutex
+	count int
+}
+
+// NewRecordingTransport creates a transport recording to dir, numbering new cassettes after any already there
+func NewRecordingTransport(dir string, next http.RoundTripper) (*RecordingTransport, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, &TempooError{Message: fmt.Sprintf("Failed to create cassette directory %s", dir), Cause: err}
+	}
+	existing, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &RecordingTransport{dir: dir, next: next, count: len(existing)}, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (rt *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     redactURL(req.URL.String()),
+			Headers: redactHeaders(req.Header),
+			Body:    redactBody(requestBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    redactHeaders(resp.Header),
+			Body:       redactBody(responseBody),
+		},
+	}
+	if err := rt.save(interaction); err != nil {
+		log.Warnf("Failed to record %s %s: %v", req.Method, req.URL, err)
+	}
+	return resp, nil
+}
+
+// save writes an interaction to the next cassette file
+func (rt *RecordingTransport) save(interaction *Interaction) error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+	rt.count++
+	path := filepath.Join(rt.dir, cassetteName(rt.count, interaction.Request))
+	log.Debugf("Recording %s %s to %s", interaction.Request.Method, interaction.Request.URL, path)
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// ReplayTransport answers requests with recorded responses instead of the network
+type ReplayTransport struct {
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// NewReplayTransport loads the cassettes recorded in dir
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, &TempooError{Message: fmt.Sprintf("No cassettes found in %s", dir)}
+	}
+
+	rt := &ReplayTransport{used: make([]bool, len(files))}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, &TempooError{Message: fmt.Sprintf("Failed to read cassette %s", file), Cause: err}
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return nil, &TempooError{Message: fmt.Sprintf("Failed to parse cassette %s", file), Cause: err}
+		}
+		rt.interactions = append(rt.interactions, &interaction)
+	}
+	log.Debugf("Loaded %d cassette(s) from %s", len(rt.interactions), dir)
+	return rt, nil
+}
+
+// RoundTrip implements http.RoundTripper. Requests are matched on method and URL in recording
+// order, so repeated requests get successive responses; once those run out, the last one repeats.
+func (rt *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	url := redactURL(req.URL.String())
+	match := -1
+	for i, interaction := range rt.interactions {
+		if interaction.Request.Method != req.Method || interaction.Request.URL != url {
+			continue
+		}
+		match = i
+		if !rt.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, &TempooError{Message: fmt.Sprintf("No recorded response for %s %s", req.Method, url)}
+	}
+	rt.used[match] = true
+
+	recorded := rt.interactions[match].Response
+	log.Debugf("Replaying %s %s: %d", req.Method, url, recorded.StatusCode)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Headers.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// cassetteFiles returns the cassette files in dir in recording order
+func cassetteFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, &TempooError{Message: fmt.Sprintf("Failed to list cassettes in %s", dir), Cause: err}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// unsafeFileChars matches characters kept out of cassette file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// cassetteName returns the file name of the nth cassette, e.g. 0001-GET-rest-api-3-myself.json
+func cassetteName(n int, req RecordedRequest) string {
+	path := req.URL
+	if i := strings.Index(path, "://"); i >= 0 {
+		path = path[i+3:]
+	}
+	if i := strings.Index(path, "/"); i >= 0 {
+		path = path[i:]
+	}
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(path, "-"), "-")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	return fmt.Sprintf("%04d-%s-%s.json", n, req.Method, name)
+}
+
+// readBody reads a request or response body and replaces it with an unread copy
+func readBody(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return "", err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
+
+// isSecret reports whether a header, query parameter or JSON field name holds a credential
+func isSecret(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range []string{"authorization", "cookie", "token", "password", "secret", "apikey", "api_key"} {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders returns a copy of headers with credentials redacted
+func redactHeaders(headers http.Header) http.Header {
+	result := headers.Clone()
+	for name := range result {
+		if isSecret(name) {
+			result[name] = []string{redacted}
+		}
+	}
+	return result
+}
+
+// redactURL redacts credentials passed as query parameters
+func redactURL(rawURL string) string {
+	i := strings.Index(rawURL, "?")
+	if i < 0 {
+		return rawURL
+	}
+	params := strings.Split(rawURL[i+1:], "&")
+	for j, param := range params {
+		if name, _, ok := strings.Cut(param, "="); ok && isSecret(name) {
+			params[j] = name + "=" + redacted
+		}
+	}
+	return rawURL[:i+1] + strings.Join(params, "&")
+}
+
+// redactBody redacts credential fields of a JSON body, leaving other bodies as they are
+func redactBody(body string) string {
+	var value interface{}
+	if body == "" || json.Unmarshal([]byte(body), &value) != nil {
+		return body
+	}
+	if !redactValue(value) {
+		return body
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return string(data)
+}
+
+// redactValue redacts credential fields in a decoded JSON value, reporting whether it changed anything
+func redactValue(value interface{}) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, ok := field.(string); ok && isSecret(key) {
+				v[key] = redacted
+				changed = true
+			} else if redactValue(field) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if redactValue(item) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
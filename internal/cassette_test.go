@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tempoo/internal/fakejira"
+)
+
+func TestCassettes_RecordAndReplay(t *testing.T) {
+	server := fakejira.New()
+	server.AddUser(fakejira.User{AccountID: "user-1", DisplayName: "Test User", Email: "test@example.com", APIToken: "test-token"})
+	server.AddIssue(fakejira.Issue{Key: "TEST-1"})
+	dir := t.TempDir()
+
+	recorder, err := NewRecordingTransport(dir, nil)
+	if err != nil {
+		t.Fatalf("NewRecordingTransport() error = %v", err)
+	}
+	client, _ := NewClient(ClientOptions{BaseURL: server.APIURL(), Email: "test@example.com", APIToken: "test-token", HTTPClient: &http.Client{Transport: recorder}})
+	if _, err := client.AddWorklog(t.Context(), "TEST-1", "1", nil); err != nil {
+		t.Fatalf("AddWorklog() error = %v", err)
+	}
+	recorded, err := client.ListWorklogs(t.Context(), "TEST-1")
+	if err != nil || len(recorded) != 1 {
+		t.Fatalf("ListWorklogs() = %+v, %v", recorded, err)
+	}
+	server.Close()
+
+	files, _ := cassetteFiles(dir)
+	if len(files) != 4 {
+		t.Fatalf("Expected 4 cassettes, got %v", files)
+	}
+	if !strings.HasSuffix(files[0], "0001-POST-rest-api-3-issue-TEST-1-worklog.json") {
+		t.Errorf("Unexpected cassette name %s", files[0])
+	}
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if strings.Contains(string(data), "test-token") || strings.Contains(string(data), "Basic ") {
+			t.Errorf("Cassette %s contains credentials:\n%s", filepath.Base(file), data)
+		}
+	}
+
+	replayer, err := NewReplayTransport(dir)
+	if err != nil {
+		t.Fatalf("NewReplayTransport() error = %v", err)
+	}
+	client, _ = NewClient(ClientOptions{BaseURL: server.APIURL(), Email: "replay", APIToken: "replay", HTTPClient: &http.Client{Transport: replayer}})
+	replayed, err := client.ListWorklogs(t.Context(), "TEST-1")
+	if err != nil || len(replayed) != 1 || replayed[0].ID != recorded[0].ID {
+		t.Errorf("Replayed ListWorklogs() = %+v, %v, want %+v", replayed, err, recorded)
+	}
+
+	if _, err := client.ListWorklogs(t.Context(), "TEST-2"); err == nil {
+		t.Error("Expected an error for a request that was not recorded")
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	if got := redactURL("https://example.com/x?accountId=1&api_token=abc"); got != "https://example.com/x?accountId=1&api_token=REDACTED" {
+		t.Errorf("redactURL() = %s", got)
+	}
+	if got := redactBody(`{"name":"x","nested":[{"refreshToken":"abc","count":1}]}`); got != `{"name":"x","nested":[{"count":1,"refreshToken":"REDACTED"}]}` {
+		t.Errorf("redactBody() = %s", got)
+	}
+	if got := redactBody("not json token=abc"); got != "not json token=abc" {
+		t.Errorf("redactBody() changed a non-JSON body: %s", got)
+	}
+	headers := redactHeaders(http.Header{"Authorization": {"Basic abc"}, "Content-Type": {"application/json"}})
+	if headers.Get("Authorization") != redacted || headers.Get("Content-Type") != "application/json" {
+		t.Errorf("redactHeaders() = %v", headers)
+	}
+}
@@ -1,13 +1,33 @@
 package internal
 
 import (
+	"context"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/apex/log"
 	"github.com/go-resty/resty/v2"
 )
 
+// defaultTimeout is the request timeout used when none is configured
+const defaultTimeout = 10 * time.Second
+
+// ClientOptions configures a Jira client
+type ClientOptions struct {
+	// BaseURL is the root URL of the Jira REST API, defaulting to JiraAPIRootURL
+	BaseURL  string
+	Email    string
+	APIToken string
+	// HTTPClient sends the requests, defaulting to a new client
+	HTTPClient *http.Client
+	// Logger receives the client's log messages, defaulting to the global apex/log logger
+	Logger log.Interface
+	// Timeout bounds each request, defaulting to 10 seconds
+	Timeout time.Duration
+}
+
 // NewTempoo creates a new client for the Jira API
 func NewTempoo() (*Tempoo, error) {
 	email := os.Getenv("JIRA_EMAIL")
@@ -22,23 +42,108 @@ func NewTempoo() (*Tempoo, error) {
 	}
 	log.Debug("Read JIRA_API_TOKEN from env")
 
+	opts := ClientOptions{Email: email, APIToken: apiToken}
+	if baseURL := os.Getenv(JiraBaseURLEnvVar); baseURL != "" {
+		log.Debugf("Read %s from env: %s", JiraBaseURLEnvVar, baseURL)
+		opts.BaseURL = strings.TrimSuffix(baseURL, "/") + JiraAPIPath
+	}
+
+	return NewClient(opts)
+}
+
+// NewClient creates a new client for the Jira API from explicit options
+func NewClient(opts ClientOptions) (*Tempoo, error) {
+	if opts.Email == "" || opts.APIToken == "" {
+		return nil, &TempooError{Message: "Jira email and API token are required"}
+	}
+
 	// create a new resty client
-	client := resty.New()
+	client := newRestyClient(opts.HTTPClient, opts.Timeout)
 	// set auth
-	client.SetBasicAuth(email, apiToken)
-	// build header
-	client.SetHeader("Content-Type", "application/json")
-	// set default timeout
-	client.SetTimeout(10 * time.Second)
-
-	log.Debugf("Created Resty client: %+v", client)
+	client.SetBasicAuth(opts.Email, opts.APIToken)
 
 	t := &Tempoo{
-		email:    email,
-		apiToken: apiToken,
+		email:    opts.Email,
+		apiToken: opts.APIToken,
 		client:   client,
+		baseURL:  strings.TrimSuffix(opts.BaseURL, "/"),
+		logger:   opts.Logger,
 	}
+	logRequests(client, t.log)
 
 	log.Debug("Tempoo initialized")
 	return t, nil
 }
+
+// newRestyClient creates a resty client sending JSON through httpClient, or a new client if nil
+func newRestyClient(httpClient *http.Client, timeout time.Duration) *resty.Client {
+	var client *resty.Client
+	if httpClient != nil {
+		client = resty.NewWithClient(httpClient)
+	} else {
+		client = resty.New()
+	}
+	// build header
+	client.SetHeader("Content-Type", "application/json")
+	// set default timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	client.SetTimeout(timeout)
+	return client
+}
+
+// EnableOfflineQueue makes worklog changes that fail because Jira is unreachable go to q instead of erroring
+func (t *Tempoo) EnableOfflineQueue(q *Queue) {
+	t.log().Debug("Offline queue enabled")
+	t.queue = q
+}
+
+// EnableHistory journals every worklog change made through this client in h, so it can be undone
+func (t *Tempoo) EnableHistory(h *History) {
+	t.log().Debug("History enabled")
+	t.history = h
+}
+
+// EnableCache keeps the current user and validated issue keys in c, saving requests to Jira
+func (t *Tempoo) EnableCache(c *Cache) {
+	t.log().Debug("Cache enabled")
+	t.cache = c
+}
+
+// cacheScope identifies the Jira site and user cache entries belong to
+func (t *Tempoo) cacheScope() string {
+	return t.apiRoot() + "|" + t.email
+}
+
+// withPacing returns a shallow copy of the client that calls wait before each of its requests,
+// so a rate limit covers every request a call makes
+func (t *Tempoo) withPacing(wait func()) WorklogService {
+	c := *t
+	c.pace = wait
+	return &c
+}
+
+// request starts a Jira API request bound to ctx
+func (t *Tempoo) request(ctx context.Context) *resty.Request {
+	if t.pace != nil {
+		t.pace()
+	}
+	return t.client.R().SetContext(ctx)
+}
+
+// apiRoot returns the root URL of the Jira API the client talks to
+func (t *Tempoo) apiRoot() string {
+	if t.baseURL != "" {
+		return t.baseURL
+	}
+	return JiraAPIRootURL
+}
+
+// log returns the client's logger
+func (t *Tempoo) log() log.Interface {
+	if t.logger != nil {
+		return t.logger
+	}
+	return log.Log
+}
@@ -0,0 +1,229 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apex/log"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// HomeEnvVar overrides the directory holding the tempoo config and state files
+	HomeEnvVar = "TEMPOO_HOME"
+	// ConfigFileName is the name of the config file inside the tempoo home directory
+	ConfigFileName = "config.yaml"
+)
+
+// rounding modes supported by RoundingConfig
+const (
+	RoundNearest = "nearest"
+	RoundUp      = "up"
+	RoundDown    = "down"
+)
+
+// worklog backends a profile can use
+const (
+	BackendJira  = "jira"
+	BackendTempo = "tempo"
+	// BackendMemory keeps demo worklogs in memory for the duration of one command
+	BackendMemory = "memory"
+)
+
+// Config holds the user settings read from the tempoo config file
+type Config struct {
+	Rounding RoundingConfig `yaml:"rounding"`
+	// Offline queues worklog changes locally when Jira is unreachable instead of failing
+	Offline bool `yaml:"offline"`
+	// Profile is the name of the profile used when none is selected on the command line
+	Profile  string             `yaml:"profile"`
+	Profiles map[string]Profile `yaml:"profiles"`
+	// Aliases maps short names to the issue keys they stand for, accepted wherever an issue key is
+	Aliases  map[string]string `yaml:"aliases"`
+	Git      GitConfig         `yaml:"git"`
+	Calendar CalendarConfig    `yaml:"calendar"`
+	Import   ImportConfig      `yaml:"import"`
+	// Templates are named sets of recurring worklogs
+	Templates map[string]Template `yaml:"templates"`
+	// DailyTargetHours is the time expected to be logged on each weekday
+	DailyTargetHours float64 `yaml:"daily_target_hours"`
+}
+
+// Profile is a named set of settings selecting where worklogs are kept
+type Profile struct {
+	// Backend is where worklogs are read from and written to: jira (default), tempo or memory
+	Backend string `yaml:"backend"`
+}
+
+// RoundingConfig controls how measured durations are rounded before logging
+type RoundingConfig struct {
+	GranularityMinutes int    `yaml:"granularity_minutes"`
+	Mode               string `yaml:"mode"`
+}
+
+// DefaultConfig returns the config used when no config file exists
+func DefaultConfig() *Config {
+	return &Config{
+		Rounding: RoundingConfig{
+			GranularityMinutes: 30,
+			Mode:               RoundNearest,
+		},
+		Git: GitConfig{
+			BranchPattern: DefaultBranchPattern,
+		},
+		DailyTargetHours: 8,
+	}
+}
+
+// HomeDir returns the directory holding the tempoo config and state files
+func HomeDir() (string, error) {
+	if dir := os.Getenv(HomeEnvVar); dir != "" {
+		return dir, nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", &TempooError{Message: "Failed to locate user config directory", Cause: err}
+	}
+	return filepath.Join(configDir, "tempoo"), nil
+}
+
+// ConfigPath returns the path of the config file in the tempoo home directory
+func ConfigPath() (string, error) {
+	dir, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ConfigFileName), nil
+}
+
+// LoadConfig reads the config file from the tempoo home directory, falling back to defaults
+func LoadConfig() (*Config, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return LoadConfigFile(path)
+}
+
+// LoadConfigFile reads the config file at path, falling back to defaults if it does not exist
+func LoadConfigFile(path string) (*Config, error) {
+	config := DefaultConfig()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Debugf("No config file at %s, using defaults", path)
+		return config, nil
+	}
+	if err != nil {
+		return nil, &TempooError{Message: fmt.Sprintf("Failed to read config file %s", path), Cause: err}
+	}
+
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, &TempooError{Message: fmt.Sprintf("Failed to parse config file %s", path), Cause: err}
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	log.Debugf("Loaded config from %s", path)
+
+	return config, nil
+}
+
+// validate checks the config values that cannot be caught by the YAML decoder
+func (c *Config) validate() error {
+	if c.Rounding.GranularityMinutes <= 0 {
+		return &TempooError{Message: fmt.Sprintf("Rounding granularity must be positive, got %d", c.Rounding.GranularityMinutes)}
+	}
+
+	switch c.Rounding.Mode {
+	case RoundNearest, RoundUp, RoundDown:
+	default:
+		return &TempooError{Message: fmt.Sprintf("Invalid rounding mode '%s'. Expected nearest, up or down", c.Rounding.Mode)}
+	}
+
+	if c.DailyTargetHours < 0 || c.DailyTargetHours > 24 {
+		return &TempooError{Message: fmt.Sprintf("Daily target hours must be between 0 and 24, got %g", c.DailyTargetHours)}
+	}
+
+	for name, profile := range c.Profiles {
+		switch profile.Backend {
+		case "", BackendJira, BackendTempo, BackendMemory:
+		default:
+			return &TempooError{Message: fmt.Sprintf("Invalid backend '%s' in profile '%s'. Expected jira, tempo or memory", profile.Backend, name)}
+		}
+	}
+
+	if err := c.Git.validate(); err != nil {
+		return err
+	}
+	if err := c.Calendar.validate(); err != nil {
+		return err
+	}
+	if err := c.Import.validate(); err != nil {
+		return err
+	}
+	if err := validateTemplates(c.Templates); err != nil {
+		return err
+	}
+
+	for name, issueKey := range c.Aliases {
+		if err := ValidateAlias(name, issueKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TargetHours returns the time expected to be logged on a day: the daily target on weekdays, and none at weekends
+func (c *Config) TargetHours(day time.Time) float64 {
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return 0
+	}
+	return c.DailyTargetHours
+}
+
+// ActiveProfile returns the profile called name, or the configured default profile if name is empty.
+// Without any profile selected the Jira backend is used.
+func (c *Config) ActiveProfile(name string) (Profile, error) {
+	if name == "" {
+		name = c.Profile
+	}
+	if name == "" {
+		return Profile{Backend: BackendJira}, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, &TempooError{Message: fmt.Sprintf("Profile '%s' is not defined in the config file", name)}
+	}
+	if profile.Backend == "" {
+		profile.Backend = BackendJira
+	}
+	return profile, nil
+}
+
+// Round rounds a measured duration to the configured granularity, never returning less than one unit
+func (r RoundingConfig) Round(d time.Duration) time.Duration {
+	unit := time.Duration(r.GranularityMinutes) * time.Minute
+
+	var rounded time.Duration
+	switch r.Mode {
+	case RoundUp:
+		rounded = ((d + unit - 1) / unit) * unit
+	case RoundDown:
+		rounded = (d / unit) * unit
+	default:
+		rounded = d.Round(unit)
+	}
+
+	if rounded < unit {
+		return unit
+	}
+	return rounded
+}
@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFile_Missing(t *testing.T) {
+	config, err := LoadConfigFile(filepath.Join(t.TempDir(), ConfigFileName))
+	if err != nil {
+		t.Fatalf("Expected no error for a missing config file, got %v", err)
+	}
+
+	if config.Rounding != DefaultConfig().Rounding {
+		t.Errorf("Expected default rounding, got %+v", config.Rounding)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expected    RoundingConfig
+		expectError bool
+	}{
+		{
+			name:     "custom rounding",
+			content:  "rounding:\n  granularity_minutes: 15\n  mode: up\n",
+			expected: RoundingConfig{GranularityMinutes: 15, Mode: RoundUp},
+		},
+		{
+			name:     "partial config keeps defaults",
+			content:  "rounding:\n  mode: down\n",
+			expected: RoundingConfig{GranularityMinutes: 30, Mode: RoundDown},
+		},
+		{
+			name:        "invalid mode",
+			content:     "rounding:\n  mode: sideways\n",
+			expectError: true,
+		},
+		{
+			name:        "invalid granularity",
+			content:     "rounding:\n  granularity_minutes: 0\n",
+			expectError: true,
+		},
+		{
+			name:        "malformed yaml",
+			content:     "rounding: [",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ConfigFileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LoadConfigFile(path)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if config.Rounding != tt.expected {
+				t.Errorf("Rounding = %+v, want %+v", config.Rounding, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHomeDir_EnvOverride(t *testing.T) {
+	t.Setenv(HomeEnvVar, "/tmp/tempoo-home")
+
+	dir, err := HomeDir()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if dir != "/tmp/tempoo-home" {
+		t.Errorf("HomeDir() = %q, want %q", dir, "/tmp/tempoo-home")
+	}
+}
+
+func TestRoundingConfig_Round(t *testing.T) {
+	tests := []struct {
+		name     string
+		rounding RoundingConfig
+		input    time.Duration
+		expected time.Duration
+	}{
+		{"nearest rounds down", RoundingConfig{30, RoundNearest}, 40 * time.Minute, 30 * time.Minute},
+		{"nearest rounds up", RoundingConfig{30, RoundNearest}, 47 * time.Minute, time.Hour},
+		{"up rounds any remainder", RoundingConfig{15, RoundUp}, 61 * time.Minute, 75 * time.Minute},
+		{"up keeps exact values", RoundingConfig{15, RoundUp}, time.Hour, time.Hour},
+		{"down truncates", RoundingConfig{15, RoundDown}, 74 * time.Minute, time.Hour},
+		{"never below one unit", RoundingConfig{30, RoundDown}, 5 * time.Minute, 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rounding.Round(tt.input); got != tt.expected {
+				t.Errorf("Round(%s) = %s, want %s", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfig_ActiveProfile(t *testing.T) {
+	config := &Config{
+		Profile: "work",
+		Profiles: map[string]Profile{
+			"work":     {Backend: BackendTempo},
+			"personal": {},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		profile     string
+		expected    string
+		expectError bool
+	}{
+		{name: "default profile", profile: "", expected: BackendTempo},
+		{name: "selected profile defaults to jira", profile: "personal", expected: BackendJira},
+		{name: "unknown profile", profile: "other", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := config.ActiveProfile(tt.profile)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if profile.Backend != tt.expected {
+				t.Errorf("Backend = %q, want %q", profile.Backend, tt.expected)
+			}
+		})
+	}
+
+	if profile, _ := DefaultConfig().ActiveProfile(""); profile.Backend != BackendJira {
+		t.Errorf("Expected jira backend without profiles, got %q", profile.Backend)
+	}
+}
+
+func TestLoadConfigFile_InvalidBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := os.WriteFile(path, []byte("profiles:\n  work:\n    backend: harvest\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfigFile(path); err == nil {
+		t.Error("Expected error for an unknown backend")
+	}
+}
+
+func TestConfig_TargetHours(t *testing.T) {
+	config := DefaultConfig()
+	config.DailyTargetHours = 7.5
+
+	if hours := config.TargetHours(time.Date(2025, time.July, 4, 0, 0, 0, 0, time.UTC)); hours != 7.5 {
+		t.Errorf("Expected 7.5 hours on a Friday, got %v", hours)
+	}
+	if hours := config.TargetHours(time.Date(2025, time.July, 5, 0, 0, 0, 0, time.UTC)); hours != 0 {
+		t.Errorf("Expected no target on a Saturday, got %v", hours)
+	}
+
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	os.WriteFile(path, []byte("daily_target_hours: -1\n"), 0o600)
+	if _, err := LoadConfigFile(path); err == nil {
+		t.Error("Expected an error for a negative daily target")
+	}
+}
@@ -3,6 +3,22 @@ package internal
 const (
 	// JiraFQDN is the FQDN of the Jira instance
 	JiraFQDN = "esendex.atlassian.net"
+	// JiraAPIPath is the path of the Jira API below a Jira site URL
+	JiraAPIPath = "/rest/api/3"
 	// JiraAPIRootURL is the root URL of the Jira API
-	JiraAPIRootURL = "https://" + JiraFQDN + "/rest/api/3"
+	JiraAPIRootURL = "https://" + JiraFQDN + JiraAPIPath
+	// JiraBaseURLEnvVar overrides the Jira site URL, e.g. to point tempoo at another instance or a fake server
+	JiraBaseURLEnvVar = "JIRA_BASE_URL"
+
+	// jiraTimestampFormat is the layout Jira uses for worklog timestamps
+	jiraTimestampFormat = "2006-01-02T15:04:05.000-0700"
+)
+
+const (
+	// TempoAPIRootURL is the root URL of the Tempo Cloud REST API
+	TempoAPIRootURL = "https://api.tempo.io/4"
+
+	// tempoDateFormat and tempoTimeFormat are the layouts of a Tempo worklog's startDate and startTime
+	tempoDateFormat = "2006-01-02"
+	tempoTimeFormat = "15:04:05"
 )
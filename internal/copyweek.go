@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxWorklogIssues caps the issues searched for worklogs in a range of days
+const maxWorklogIssues = 200
+
+// worklogsJQL finds the issues the current user logged time to during a range of days
+func worklogsJQL(days DateRange) string {
+	return fmt.Sprintf(`worklogAuthor = currentUser() AND worklogDate >= "%s" AND worklogDate <= "%s"`,
+		days.From.Format("2006-01-02"), days.To.Format("2006-01-02"))
+}
+
+// WorklogsBetween returns the current user's worklogs starting during a range of days, across all issues, oldest first
+func WorklogsBetween(ctx context.Context, service WorklogService, days DateRange) (Worklogs, error) {
+	issues, err := service.SearchIssues(ctx, worklogsJQL(days), maxWorklogIssues)
+	if err != nil {
+		return nil, err
+	}
+
+	worklogs := Worklogs{}
+	for _, issue := range issues {
+		found, err := service.ListWorklogs(ctx, issue.Key)
+		if err != nil {
+			return nil, err
+		}
+		for _, worklog := range found {
+			if days.Contains(worklog.Started) {
+				worklogs = append(worklogs, worklog)
+			}
+		}
+	}
+	sort.SliceStable(worklogs, func(i, j int) bool { return worklogs[i].Started.Before(worklogs[j].Started) })
+	return worklogs, nil
+}
+
+// CopyWorklogs drafts worklogs again on the corresponding days of the week starting at target, keeping
+// their issues, local start times and comments. Durations are multiplied by scale, and rounded with the
+// configured rounding when scaled.
+func CopyWorklogs(worklogs Worklogs, target DateRange, scale float64, rounding RoundingConfig) *Draft {
+	draft := &Draft{Worklogs: []DraftWorklog{}}
+	for _, worklog := range worklogs {
+		started := worklog.Started.In(time.Local)
+		day := calendarDay(started)
+
+		duration := time.Duration(worklog.TimeSpentSeconds) * time.Second
+		if scale != 1 {
+			duration = rounding.Round(time.Duration(float64(duration) * scale))
+		}
+
+		draft.Worklogs = append(draft.Worklogs, DraftWorklog{
+			Date:     target.From.AddDate(0, 0, daysSinceMonday(day)).Format(draftDateFormat),
+			Start:    started.Format(draftStartFormat),
+			IssueKey: worklog.IssueKey,
+			Hours:    duration.Hours(),
+			Comment:  worklog.Comment,
+			Note:     "copied from " + day.Format(draftDateFormat),
+		})
+	}
+	return draft
+}
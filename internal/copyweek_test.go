@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorklogsBetween(t *testing.T) {
+	service := NewMemoryService(User{AccountID: "user-1"}, Issue{Key: "INF-1"}, Issue{Key: "INF-2"})
+	service.AddWorklogAt(t.Context(), "INF-2", time.Date(2025, time.July, 2, 13, 0, 0, 0, time.Local), time.Hour)
+	service.AddWorklogAt(t.Context(), "INF-1", time.Date(2025, time.July, 1, 9, 0, 0, 0, time.Local), time.Hour)
+	service.AddWorklogAt(t.Context(), "INF-1", time.Date(2025, time.July, 8, 9, 0, 0, 0, time.Local), time.Hour)
+	week, _ := ParseWeek("01.07.2025", time.Now())
+
+	worklogs, err := WorklogsBetween(t.Context(), service, week)
+	if err != nil {
+		t.Fatalf("WorklogsBetween failed: %v", err)
+	}
+	if len(worklogs) != 2 || worklogs[0].IssueKey != "INF-1" || worklogs[1].IssueKey != "INF-2" {
+		t.Errorf("Unexpected worklogs %+v", worklogs)
+	}
+}
+
+func TestCopyWorklogs(t *testing.T) {
+	worklogs := Worklogs{
+		{IssueKey: "INF-1", Started: time.Date(2025, time.July, 1, 9, 15, 0, 0, time.Local), TimeSpentSeconds: 5400, Comment: "Planning"},
+		{IssueKey: "INF-2", Started: time.Date(2025, time.July, 4, 14, 0, 0, 0, time.Local), TimeSpentSeconds: 3600},
+	}
+	target, _ := ParseWeek("16.07.2025", time.Now())
+	rounding := RoundingConfig{GranularityMinutes: 15, Mode: RoundNearest}
+
+	draft := CopyWorklogs(worklogs, target, 1, rounding)
+
+	expected := []DraftWorklog{
+		{Date: "15.07.2025", Start: "09:15", IssueKey: "INF-1", Hours: 1.5, Comment: "Planning", Note: "copied from 01.07.2025"},
+		{Date: "18.07.2025", Start: "14:00", IssueKey: "INF-2", Hours: 1, Note: "copied from 04.07.2025"},
+	}
+	if len(draft.Worklogs) != len(expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, draft.Worklogs)
+	}
+	for i := range expected {
+		if draft.Worklogs[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], draft.Worklogs[i])
+		}
+	}
+
+	// 80% of 1.5 hours is 72 minutes, rounded to 75
+	scaled := CopyWorklogs(worklogs, target, 0.8, rounding)
+	if scaled.Worklogs[0].Hours != 1.25 {
+		t.Errorf("Expected the scaled worklog to be rounded to 1.25 hours, got %v", scaled.Worklogs[0].Hours)
+	}
+}
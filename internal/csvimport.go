@@ -0,0 +1,397 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// time tracker export formats read by ParseTimeEntries
+const (
+	FormatToggl    = "toggl"
+	FormatClockify = "clockify"
+	FormatHarvest  = "harvest"
+)
+
+// utf8BOM is the byte order mark some exports start with
+const utf8BOM = "\ufeff"
+
+// csvDateFormats are the date formats accepted by ParseTimeEntries, with their layouts. Unless one is given,
+// dates are read as YYYY-MM-DD or DD.MM.YYYY, and dates with slashes as whichever order the whole column allows.
+var csvDateFormats = map[string]string{
+	"YYYY-MM-DD": "2006-01-02",
+	"DD.MM.YYYY": "02.01.2006",
+	"MM/DD/YYYY": "01/02/2006",
+	"DD/MM/YYYY": "02/01/2006",
+}
+
+// slashDate matches a date with slashes, capturing the day and month in either order
+var slashDate = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})/\d{4}$`)
+
+// TimeEntry is a time entry exported from a time tracker
+type TimeEntry struct {
+	// Date is the local day the time was tracked on
+	Date        time.Time
+	Project     string
+	Task        string
+	Description string
+	Tags        []string
+	Duration    time.Duration
+}
+
+// csvFormat describes the columns of a time tracker's detailed CSV export. Each field lists
+// the accepted header names, as exports differ between versions and settings.
+type csvFormat struct {
+	date        []string
+	project     []string
+	task        []string
+	description []string
+	tags        []string
+	duration    []string
+}
+
+// csvFormats are the supported exports, by name
+var csvFormats = map[string]csvFormat{
+	FormatToggl: {
+		date:        []string{"Start date"},
+		project:     []string{"Project"},
+		task:        []string{"Task"},
+		description: []string{"Description"},
+		tags:        []string{"Tags"},
+		duration:    []string{"Duration"},
+	},
+	FormatClockify: {
+		date:        []string{"Start Date"},
+		project:     []string{"Project"},
+		task:        []string{"Task"},
+		description: []string{"Description"},
+		tags:        []string{"Tags"},
+		duration:    []string{"Duration (decimal)", "Duration (h)"},
+	},
+	FormatHarvest: {
+		date:        []string{"Date", "Spent Date"},
+		project:     []string{"Project"},
+		task:        []string{"Task"},
+		description: []string{"Notes"},
+		duration:    []string{"Hours"},
+	},
+}
+
+// ParseTimeEntries reads the time entries of a detailed CSV export from Toggl, Clockify or Harvest.
+// An empty format is detected from the header row, and an empty date format from the dates.
+// It returns the entries and the format read.
+func ParseTimeEntries(r io.Reader, format, dateFormat string) ([]TimeEntry, string, error) {
+	// exports from Clockify start with a byte order mark, which would break the first quoted field
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(len(utf8BOM)); err == nil && string(bom) == utf8BOM {
+		buffered.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, "", &TempooError{Message: "Failed to read CSV header", Cause: err}
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	if format == "" {
+		if format = detectCSVFormat(columns); format == "" {
+			return nil, "", &TempooError{Message: "Unknown CSV export. Expected a detailed export from Toggl, Clockify or Harvest, or pass --format"}
+		}
+		log.Debugf("Reading %s export", format)
+	}
+	spec, ok := csvFormats[format]
+	if !ok {
+		return nil, "", &TempooError{Message: fmt.Sprintf("Invalid format '%s'. Expected toggl, clockify or harvest", format)}
+	}
+	if _, ok := spec.column(columns, spec.date); !ok {
+		return nil, "", &TempooError{Message: fmt.Sprintf("Missing %s column in %s export", spec.date[0], format)}
+	}
+	if _, ok := spec.column(columns, spec.duration); !ok {
+		return nil, "", &TempooError{Message: fmt.Sprintf("Missing %s column in %s export", spec.duration[0], format)}
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, "", &TempooError{Message: "Failed to read CSV export", Cause: err}
+	}
+	dates := make([]string, 0, len(records))
+	for _, record := range records {
+		dates = append(dates, spec.value(columns, record, spec.date))
+	}
+	layouts, err := csvDateLayouts(dates, dateFormat)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var entries []TimeEntry
+	for i, record := range records {
+		entry, err := spec.entry(columns, record, layouts)
+		if err != nil {
+			return nil, "", &TempooError{Message: fmt.Sprintf("Invalid entry on CSV line %d", i+2), Cause: err}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, format, nil
+}
+
+// csvDateLayouts returns the layouts to read the dates of an export with. Without a date format, the order of
+// day and month in dates with slashes is taken from the dates where it shows, and is an error where none does.
+func csvDateLayouts(dates []string, dateFormat string) ([]string, error) {
+	if dateFormat != "" {
+		layout, ok := csvDateFormats[strings.ToUpper(dateFormat)]
+		if !ok {
+			return nil, &TempooError{Message: fmt.Sprintf("Invalid date format '%s'. Expected YYYY-MM-DD, DD.MM.YYYY, MM/DD/YYYY or DD/MM/YYYY", dateFormat)}
+		}
+		return []string{layout}, nil
+	}
+
+	layouts := []string{csvDateFormats["YYYY-MM-DD"], csvDateFormats["DD.MM.YYYY"]}
+	dayFirst, monthFirst, example := false, false, ""
+	for _, date := range dates {
+		match := slashDate.FindStringSubmatch(date)
+		if match == nil {
+			continue
+		}
+		first, _ := strconv.Atoi(match[1])
+		second, _ := strconv.Atoi(match[2])
+		dayFirst = dayFirst || first > 12
+		monthFirst = monthFirst || second > 12
+		example = date
+	}
+	switch {
+	case example == "":
+		return layouts, nil
+	case dayFirst && monthFirst:
+		return nil, &TempooError{Message: "Dates in the export mix DD/MM/YYYY and MM/DD/YYYY"}
+	case dayFirst:
+		return append(layouts, csvDateFormats["DD/MM/YYYY"]), nil
+	case monthFirst:
+		return append(layouts, csvDateFormats["MM/DD/YYYY"]), nil
+	}
+	return nil, &TempooError{Message: fmt.Sprintf("Dates such as %s could be DD/MM/YYYY or MM/DD/YYYY. Pass --date-format or set date_format in the import section of the config file", example)}
+}
+
+// detectCSVFormat tells the export format from its distinctive header names
+func detectCSVFormat(columns map[string]int) string {
+	has := func(name string) bool {
+		_, ok := columns[name]
+		return ok
+	}
+	switch {
+	case has("Start date") && has("Duration"):
+		return FormatToggl
+	case has("Start Date") && (has("Duration (decimal)") || has("Duration (h)")):
+		return FormatClockify
+	case (has("Date") || has("Spent Date")) && has("Hours"):
+		return FormatHarvest
+	}
+	return ""
+}
+
+// column returns the index of the first of names found in the header
+func (f csvFormat) column(columns map[string]int, names []string) (int, bool) {
+	for _, name := range names {
+		if i, ok := columns[name]; ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// value returns the field of a record in the first of names found in the header, or "" if there is none
+func (f csvFormat) value(columns map[string]int, record []string, names []string) string {
+	if i, ok := f.column(columns, names); ok && i < len(record) {
+		return strings.TrimSpace(record[i])
+	}
+	return ""
+}
+
+// entry converts a CSV record into a time entry, reading its date with the first matching layout
+func (f csvFormat) entry(columns map[string]int, record []string, layouts []string) (TimeEntry, error) {
+	value := func(names []string) string {
+		return f.value(columns, record, names)
+	}
+
+	entry := TimeEntry{Project: value(f.project), Task: value(f.task), Description: value(f.description)}
+	for _, tag := range strings.Split(value(f.tags), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			entry.Tags = append(entry.Tags, tag)
+		}
+	}
+
+	date := value(f.date)
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, date, time.Local); err == nil {
+			entry.Date = t
+			break
+		}
+	}
+	if entry.Date.IsZero() {
+		return entry, fmt.Errorf("invalid date %q", date)
+	}
+
+	var err error
+	entry.Duration, err = parseTrackedDuration(value(f.duration))
+	return entry, err
+}
+
+// parseTrackedDuration parses a duration exported as h:mm, h:mm:ss or decimal hours
+func parseTrackedDuration(value string) (time.Duration, error) {
+	if !strings.Contains(value, ":") {
+		hours, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(hours * float64(time.Hour)).Round(time.Second), nil
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second}[:len(parts)] {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
+
+// ImportConfig controls how time entries from other time trackers become worklogs
+type ImportConfig struct {
+	// Rules map time entries to issue keys, the first matching rule wins
+	Rules []ImportRule `yaml:"rules"`
+	// DateFormat is the date format of exports, such as DD/MM/YYYY, detected from the dates when empty
+	DateFormat string `yaml:"date_format"`
+}
+
+// ImportRule maps the time entries matching all of its conditions to an issue key or alias
+type ImportRule struct {
+	// Project, Task and Description are regular expressions matched against the entry's fields
+	Project     string `yaml:"project"`
+	Task        string `yaml:"task"`
+	Description string `yaml:"description"`
+	// Tag is one of the entry's tags
+	Tag      string `yaml:"tag"`
+	IssueKey string `yaml:"issue_key"`
+}
+
+// validate checks the date format is known and every rule has a condition, valid patterns and an issue key
+func (c ImportConfig) validate() error {
+	if _, ok := csvDateFormats[strings.ToUpper(c.DateFormat)]; c.DateFormat != "" && !ok {
+		return &TempooError{Message: fmt.Sprintf("Invalid import date format '%s'. Expected YYYY-MM-DD, DD.MM.YYYY, MM/DD/YYYY or DD/MM/YYYY", c.DateFormat)}
+	}
+	for i, rule := range c.Rules {
+		if rule.Project == "" && rule.Task == "" && rule.Description == "" && rule.Tag == "" {
+			return &TempooError{Message: fmt.Sprintf("Import rule %d needs a project, task, description or tag", i+1)}
+		}
+		for _, pattern := range []string{rule.Project, rule.Task, rule.Description} {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return &TempooError{Message: fmt.Sprintf("Invalid pattern '%s' in import rule %d", pattern, i+1), Cause: err}
+			}
+		}
+		if rule.IssueKey == "" {
+			return &TempooError{Message: fmt.Sprintf("Import rule %d needs an issue key", i+1)}
+		}
+	}
+	return nil
+}
+
+// matches reports whether a time entry meets all the conditions of the rule
+func (r ImportRule) matches(entry TimeEntry) bool {
+	for _, condition := range []struct{ pattern, value string }{
+		{r.Project, entry.Project},
+		{r.Task, entry.Task},
+		{r.Description, entry.Description},
+	} {
+		if condition.pattern == "" {
+			continue
+		}
+		if matched, err := regexp.MatchString(condition.pattern, condition.value); err != nil || !matched {
+			return false
+		}
+	}
+	if r.Tag != "" {
+		found := false
+		for _, tag := range entry.Tags {
+			found = found || strings.EqualFold(r.Tag, tag)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// IssueKey returns the issue key or alias of the first rule matching a time entry, or else an issue key
+// found in its description, task or project
+func (c ImportConfig) IssueKey(entry TimeEntry) (string, bool) {
+	for _, rule := range c.Rules {
+		if rule.matches(entry) {
+			return rule.IssueKey, true
+		}
+	}
+	for _, field := range []string{entry.Description, entry.Task, entry.Project} {
+		if issueKey := issueKeyInText.FindString(field); issueKey != "" {
+			return issueKey, true
+		}
+	}
+	return "", false
+}
+
+// TrackerWorklogs drafts worklogs from time entries mapped to issue keys by the import rules.
+// Entries on the same day and issue are added up before the total is rounded with the configured
+// rounding. Entries without an issue are skipped.
+func TrackerWorklogs(entries []TimeEntry, config ImportConfig, rounding RoundingConfig) *Draft {
+	builder := draftBuilder{}
+	for _, entry := range entries {
+		issueKey, ok := config.IssueKey(entry)
+		if !ok {
+			log.Infof("Skipping %s on %s for '%s', no rule maps it to an issue", entry.Duration, entry.Date.Format(draftDateFormat), entryTitle(entry))
+			continue
+		}
+		if entry.Duration <= 0 {
+			continue
+		}
+		builder.add(entry.Date, issueKey, entry.Duration, entryTitle(entry))
+	}
+	return builder.draft(rounding, summariseEntries)
+}
+
+// entryTitle names a time entry by its description, or else its task or project
+func entryTitle(entry TimeEntry) string {
+	for _, title := range []string{entry.Description, entry.Task, entry.Project} {
+		if title != "" {
+			return title
+		}
+	}
+	return ""
+}
+
+// summariseEntries joins the distinct titles of the entries behind a worklog
+func summariseEntries(titles []string) string {
+	var distinct []string
+	seen := map[string]bool{}
+	for _, title := range titles {
+		if !seen[title] {
+			seen[title] = true
+			distinct = append(distinct, title)
+		}
+	}
+	return strings.Join(distinct, ", ")
+}
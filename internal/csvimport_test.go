@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testTogglCSV = "User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags,Amount ()\n" +
+		"Me,me@example.com,ACME,Website,,INF-88 fix login,Yes,2025-07-01,09:00:00,2025-07-01,10:30:00,01:30:00,,\n" +
+		"Me,me@example.com,ACME,Website,,INF-88 review,Yes,2025-07-01,11:00:00,2025-07-01,11:20:00,00:20:00,,\n" +
+		"Me,me@example.com,,Internal,,Lunch,No,2025-07-01,12:00:00,2025-07-01,13:00:00,01:00:00,,\n"
+	testClockifyCSV = "\ufeff\"Project\",\"Client\",\"Description\",\"Task\",\"User\",\"Group\",\"Email\",\"Tags\",\"Billable\",\"Start Date\",\"Start Time\",\"End Date\",\"End Time\",\"Duration (h)\",\"Duration (decimal)\"\n" +
+		"\"Support\",\"\",\"Tickets\",\"\",\"Me\",\"\",\"me@example.com\",\"rota, urgent\",\"No\",\"07/02/2025\",\"09:00:00\",\"07/02/2025\",\"11:00:00\",\"02:00:00\",\"2.00\"\n"
+	testHarvestCSV = "Date,Client,Project,Project Code,Task,Notes,Hours,Hours Rounded,Billable?\n" +
+		"2025-07-03,ACME,Website,WEB,Development,,2.5,2.5,Yes\n" +
+		"2025-07-03,ACME,Website,WEB,Meetings,Planning,0:45,0.75,Yes\n"
+)
+
+func TestParseTimeEntries(t *testing.T) {
+	tests := []struct {
+		name       string
+		csv        string
+		format     string
+		dateFormat string
+		entries    int
+		first      TimeEntry
+		expected   string
+	}{
+		{"toggl", testTogglCSV, "", "", 3, TimeEntry{Date: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.Local), Project: "Website", Description: "INF-88 fix login", Duration: 90 * time.Minute}, FormatToggl},
+		{"clockify", testClockifyCSV, "", "MM/DD/YYYY", 1, TimeEntry{Date: time.Date(2025, time.July, 2, 0, 0, 0, 0, time.Local), Project: "Support", Description: "Tickets", Tags: []string{"rota", "urgent"}, Duration: 2 * time.Hour}, FormatClockify},
+		{"harvest", testHarvestCSV, FormatHarvest, "", 2, TimeEntry{Date: time.Date(2025, time.July, 3, 0, 0, 0, 0, time.Local), Project: "Website", Task: "Development", Duration: 150 * time.Minute}, FormatHarvest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, format, err := ParseTimeEntries(strings.NewReader(tt.csv), tt.format, tt.dateFormat)
+			if err != nil {
+				t.Fatalf("ParseTimeEntries failed: %v", err)
+			}
+			if format != tt.expected || len(entries) != tt.entries {
+				t.Fatalf("Expected %d %s entries, got %d %s entries", tt.entries, tt.expected, len(entries), format)
+			}
+			first := entries[0]
+			if !first.Date.Equal(tt.first.Date) || first.Project != tt.first.Project || first.Task != tt.first.Task ||
+				first.Description != tt.first.Description || first.Duration != tt.first.Duration || strings.Join(first.Tags, ",") != strings.Join(tt.first.Tags, ",") {
+				t.Errorf("Expected %+v, got %+v", tt.first, first)
+			}
+		})
+	}
+
+	if _, _, err := ParseTimeEntries(strings.NewReader("a,b\n1,2\n"), "", ""); err == nil {
+		t.Error("Expected an error for an unknown export")
+	}
+	if _, _, err := ParseTimeEntries(strings.NewReader(testHarvestCSV), FormatToggl, ""); err == nil {
+		t.Error("Expected an error for a format without its columns")
+	}
+}
+
+func TestCSVDateLayouts(t *testing.T) {
+	tests := []struct {
+		dates      []string
+		dateFormat string
+		date       string
+		expected   time.Time
+	}{
+		{[]string{"2025-07-03", "03.07.2025"}, "", "03.07.2025", time.Date(2025, time.July, 3, 0, 0, 0, 0, time.Local)},
+		{[]string{"07/02/2025", "07/15/2025"}, "", "07/02/2025", time.Date(2025, time.July, 2, 0, 0, 0, 0, time.Local)},
+		{[]string{"07/02/2025", "15/07/2025"}, "", "07/02/2025", time.Date(2025, time.February, 7, 0, 0, 0, 0, time.Local)},
+		{[]string{"07/02/2025"}, "dd/mm/yyyy", "07/02/2025", time.Date(2025, time.February, 7, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		layouts, err := csvDateLayouts(tt.dates, tt.dateFormat)
+		if err != nil {
+			t.Fatalf("csvDateLayouts(%v) failed: %v", tt.dates, err)
+		}
+		entry, err := csvFormats[FormatHarvest].entry(map[string]int{"Date": 0, "Hours": 1}, []string{tt.date, "1"}, layouts)
+		if err != nil || !entry.Date.Equal(tt.expected) {
+			t.Errorf("Expected %s for %s in %v, got %s (%v)", tt.expected, tt.date, tt.dates, entry.Date, err)
+		}
+	}
+
+	for _, dates := range [][]string{{"07/02/2025", "08/02/2025"}, {"13/02/2025", "02/13/2025"}} {
+		if _, err := csvDateLayouts(dates, ""); err == nil {
+			t.Errorf("Expected an error for %v", dates)
+		}
+	}
+	if _, err := csvDateLayouts(nil, "MM-DD-YY"); err == nil {
+		t.Error("Expected an error for an unknown date format")
+	}
+}
+
+func TestTrackerWorklogs(t *testing.T) {
+	var entries []TimeEntry
+	for _, export := range []struct{ csv, dateFormat string }{{testTogglCSV, ""}, {testClockifyCSV, "MM/DD/YYYY"}, {testHarvestCSV, ""}} {
+		parsed, _, err := ParseTimeEntries(strings.NewReader(export.csv), "", export.dateFormat)
+		if err != nil {
+			t.Fatalf("ParseTimeEntries failed: %v", err)
+		}
+		entries = append(entries, parsed...)
+	}
+	config := ImportConfig{Rules: []ImportRule{
+		{Tag: "rota", IssueKey: "support"},
+		{Project: "^Website$", Task: "Meetings", IssueKey: "INF-1"},
+		{Project: "^Website$", Task: "Development", IssueKey: "INF-90"},
+	}}
+
+	draft := TrackerWorklogs(entries, config, RoundingConfig{GranularityMinutes: 30, Mode: RoundNearest})
+
+	// the Toggl entries of INF-88 add up to 1h50m, the lunch maps to no issue
+	expected := []DraftWorklog{
+		{Date: "01.07.2025", IssueKey: "INF-88", Hours: 2, Note: "INF-88 fix login, INF-88 review"},
+		{Date: "02.07.2025", IssueKey: "support", Hours: 2, Note: "Tickets"},
+		{Date: "03.07.2025", IssueKey: "INF-1", Hours: 1, Note: "Planning"},
+		{Date: "03.07.2025", IssueKey: "INF-90", Hours: 2.5, Note: "Development"},
+	}
+	if len(draft.Worklogs) != len(expected) {
+		t.Fatalf("Expected %d worklogs, got %+v", len(expected), draft.Worklogs)
+	}
+	for i := range expected {
+		if draft.Worklogs[i] != expected[i] {
+			t.Errorf("Worklog %d: expected %+v, got %+v", i, expected[i], draft.Worklogs[i])
+		}
+	}
+}
+
+func TestLoadConfigFile_InvalidImportRule(t *testing.T) {
+	for _, content := range []string{
+		"import:\n  rules:\n    - issue_key: INF-1\n",
+		"import:\n  rules:\n    - project: \"[\"\n      issue_key: INF-1\n",
+		"import:\n  rules:\n    - tag: rota\n",
+		"import:\n  date_format: MM-DD-YY\n",
+	} {
+		path := t.TempDir() + "/" + ConfigFileName
+		writeFile(t, path, content)
+		if _, err := LoadConfigFile(path); err == nil {
+			t.Errorf("Expected an error for %q", content)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateRange is an inclusive range of calendar days, each held as midnight UTC like parsed --date values
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// ParseDateRange parses DD.MM.YYYY bounds of a range of days. An empty from means the Monday of the
+// current week and an empty to means today, so no bounds at all cover the week so far.
+func ParseDateRange(from, to string, now time.Time) (DateRange, error) {
+	today := calendarDay(now)
+	r := DateRange{From: today.AddDate(0, 0, -daysSinceMonday(today)), To: today}
+
+	if from != "" {
+		date, err := parseDateString(from)
+		if err != nil {
+			return DateRange{}, err
+		}
+		r.From = date
+	}
+	if to != "" {
+		date, err := parseDateString(to)
+		if err != nil {
+			return DateRange{}, err
+		}
+		r.To = date
+	}
+
+	if r.To.Before(r.From) {
+		return DateRange{}, &TempooError{Message: fmt.Sprintf("Invalid date range: %s is before %s", r.To.Format("02.01.2006"), r.From.Format("02.01.2006"))}
+	}
+	return r, nil
+}
+
+// ParseWeek parses a DD.MM.YYYY day and returns the Monday to Sunday week containing it.
+// An empty day means the current week.
+func ParseWeek(day string, now time.Time) (DateRange, error) {
+	date := calendarDay(now)
+	if day != "" {
+		parsed, err := parseDateString(day)
+		if err != nil {
+			return DateRange{}, err
+		}
+		date = parsed
+	}
+	monday := date.AddDate(0, 0, -daysSinceMonday(date))
+	return DateRange{From: monday, To: monday.AddDate(0, 0, 6)}, nil
+}
+
+// Contains reports whether t falls on a day of the range, in local time
+func (r DateRange) Contains(t time.Time) bool {
+	day := calendarDay(t)
+	return !day.Before(r.From) && !day.After(r.To)
+}
+
+// Start returns the local time the range starts at
+func (r DateRange) Start() time.Time {
+	return time.Date(r.From.Year(), r.From.Month(), r.From.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// calendarDay returns the local day of t as midnight UTC
+func calendarDay(t time.Time) time.Time {
+	local := t.In(time.Local)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysSinceMonday returns how many days t is after the Monday of its week
+func daysSinceMonday(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
@@ -0,0 +1,217 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// draftDateFormat is the layout of draft worklog dates, the same as --date
+	draftDateFormat = "02.01.2006"
+	// draftStartFormat is the layout of draft worklog start times
+	draftStartFormat = "15:04"
+)
+
+// DraftWorklog is a worklog that has not been logged yet, to be reviewed and edited before importing
+type DraftWorklog struct {
+	// Date is the day to log the time on, as DD.MM.YYYY
+	Date     string  `json:"date" yaml:"date"`
+	IssueKey string  `json:"issue_key" yaml:"issue_key"`
+	Hours    float64 `json:"hours" yaml:"hours"`
+	// Start is the local time of day the worklog starts at as HH:MM, defaulting to the time add-worklog uses
+	Start string `json:"start,omitempty" yaml:"start,omitempty"`
+	// Comment is logged with the worklog
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	// Note explains where the worklog comes from, it is not logged
+	Note string `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+// Draft is a reviewable set of worklogs, written with -o yaml or -o json and logged with the import command
+type Draft struct {
+	Worklogs []DraftWorklog `json:"worklogs" yaml:"worklogs"`
+}
+
+// SaveDraft writes a draft to a YAML file, which LoadDraft reads back
+func SaveDraft(path string, draft *Draft) error {
+	data, err := yaml.Marshal(draft)
+	if err != nil {
+		return &TempooError{Message: "Failed to encode draft", Cause: err}
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return &TempooError{Message: fmt.Sprintf("Failed to write draft %s", path), Cause: err}
+	}
+	return nil
+}
+
+// LoadDraft reads a draft from a YAML or JSON file and checks its worklogs
+func LoadDraft(path string) (*Draft, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &TempooError{Message: fmt.Sprintf("Failed to read draft %s", path), Cause: err}
+	}
+
+	// JSON is valid YAML, so one decoder reads both
+	draft := &Draft{}
+	if err := yaml.Unmarshal(data, draft); err != nil {
+		return nil, &TempooError{Message: fmt.Sprintf("Failed to parse draft %s", path), Cause: err}
+	}
+
+	for i, worklog := range draft.Worklogs {
+		if err := worklog.validate(); err != nil {
+			return nil, &TempooError{Message: fmt.Sprintf("Invalid worklog %d in draft %s", i+1, path), Cause: err}
+		}
+	}
+	return draft, nil
+}
+
+// validate checks a draft worklog can be logged, for at most the hours add-worklog accepts
+func (w DraftWorklog) validate() error {
+	if w.IssueKey == "" {
+		return &TempooError{Message: "Missing issue key"}
+	}
+	if _, err := parseDateString(w.Date); err != nil {
+		return err
+	}
+	if _, err := time.Parse(draftStartFormat, w.Start); w.Start != "" && err != nil {
+		return &TempooError{Message: fmt.Sprintf("Invalid start time '%s'. Expected HH:MM", w.Start)}
+	}
+	if w.Hours > maxWorklogHours {
+		return &TempooError{Message: fmt.Sprintf("Hours cannot exceed %d, got %.1f", maxWorklogHours, w.Hours)}
+	}
+	return validateWorklogDuration(w.Duration())
+}
+
+// Started returns the time the worklog starts at: its start time on its date in local time, or else
+// the time add-worklog uses for a --date
+func (w DraftWorklog) Started() (time.Time, error) {
+	if w.Start == "" {
+		return worklogStart(&w.Date)
+	}
+	date, err := parseDateString(w.Date)
+	if err != nil {
+		return time.Time{}, err
+	}
+	clock, err := time.Parse(draftStartFormat, w.Start)
+	if err != nil {
+		return time.Time{}, &TempooError{Message: fmt.Sprintf("Invalid start time '%s'. Expected HH:MM", w.Start)}
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local), nil
+}
+
+// Duration returns the time to log
+func (w DraftWorklog) Duration() time.Duration {
+	return time.Duration(w.Hours * float64(time.Hour)).Round(time.Second)
+}
+
+// draftBuilder accumulates time by day and issue key to build a draft
+type draftBuilder map[draftKey]*draftEntry
+
+// draftKey identifies a worklog in a draft being built
+type draftKey struct {
+	day      time.Time
+	issueKey string
+}
+
+// draftEntry is the time collected for a day and issue, with its sources
+type draftEntry struct {
+	duration time.Duration
+	notes    []string
+}
+
+// add collects time spent on an issue on a day
+func (b draftBuilder) add(day time.Time, issueKey string, duration time.Duration, note string) {
+	key := draftKey{day: calendarDay(day), issueKey: issueKey}
+	entry := b[key]
+	if entry == nil {
+		entry = &draftEntry{}
+		b[key] = entry
+	}
+	entry.duration += duration
+	if note != "" {
+		entry.notes = append(entry.notes, note)
+	}
+}
+
+// draft returns the collected worklogs sorted by day and issue key, with durations rounded
+// and the notes of each worklog combined by summarise
+func (b draftBuilder) draft(rounding RoundingConfig, summarise func(notes []string) string) *Draft {
+	keys := make([]draftKey, 0, len(b))
+	for key := range b {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].day.Equal(keys[j].day) {
+			return keys[i].day.Before(keys[j].day)
+		}
+		return keys[i].issueKey < keys[j].issueKey
+	})
+
+	draft := &Draft{Worklogs: []DraftWorklog{}}
+	for _, key := range keys {
+		entry := b[key]
+		draft.Worklogs = append(draft.Worklogs, DraftWorklog{
+			Date:     key.day.Format(draftDateFormat),
+			IssueKey: key.issueKey,
+			Hours:    rounding.Round(entry.duration).Hours(),
+			Note:     summarise(entry.notes),
+		})
+	}
+	return draft
+}
+
+// FailedImport is a draft worklog that could not be logged, with the reason
+type FailedImport struct {
+	DraftWorklog
+	Error string `json:"error"`
+	err   error
+}
+
+// ImportResult summarises the import of a draft
+type ImportResult struct {
+	Added  Worklogs       `json:"added"`
+	Failed []FailedImport `json:"failed,omitempty"`
+}
+
+// Err returns an error describing the failed imports, wrapping the first failure, or nil if every worklog was logged
+func (r *ImportResult) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	return &TempooError{
+		Message: fmt.Sprintf("Failed to log %d of %d worklog(s)", len(r.Failed), len(r.Failed)+len(r.Added)),
+		Cause:   r.Failed[0].err,
+	}
+}
+
+// ImportDraft logs the worklogs of a draft in order, each at its start time or else the time add-worklog
+// uses for a --date.
+// Failures do not stop the other worklogs; the result lists what was logged and what failed.
+func ImportDraft(ctx context.Context, service WorklogService, draft *Draft) *ImportResult {
+	result := &ImportResult{Added: Worklogs{}}
+	for _, worklog := range draft.Worklogs {
+		added, err := importWorklog(ctx, service, worklog)
+		if err != nil {
+			result.Failed = append(result.Failed, FailedImport{DraftWorklog: worklog, Error: err.Error(), err: err})
+			continue
+		}
+		result.Added = append(result.Added, *added)
+	}
+	return result
+}
+
+// importWorklog logs one draft worklog
+func importWorklog(ctx context.Context, service WorklogService, worklog DraftWorklog) (*Worklog, error) {
+	if err := worklog.validate(); err != nil {
+		return nil, err
+	}
+	started, err := worklog.Started()
+	if err != nil {
+		return nil, err
+	}
+	return service.AddWorklogWithComment(ctx, worklog.IssueKey, started, worklog.Duration(), worklog.Comment)
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadDraft(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "draft.yaml")
+	jsonPath := filepath.Join(dir, "draft.json")
+	invalidPath := filepath.Join(dir, "invalid.yaml")
+	os.WriteFile(yamlPath, []byte("worklogs:\n  - date: 01.07.2025\n    issue_key: INF-88\n    hours: 1.5\n    note: from commits\n"), 0o600)
+	os.WriteFile(jsonPath, []byte(`{"worklogs":[{"date":"01.07.2025","issue_key":"INF-88","hours":1.5}]}`), 0o600)
+	os.WriteFile(invalidPath, []byte("worklogs:\n  - date: 2025-07-01\n    issue_key: INF-88\n    hours: 1\n"), 0o600)
+
+	for _, path := range []string{yamlPath, jsonPath} {
+		draft, err := LoadDraft(path)
+		if err != nil {
+			t.Fatalf("LoadDraft(%s) failed: %v", path, err)
+		}
+		if len(draft.Worklogs) != 1 || draft.Worklogs[0].Duration() != 90*time.Minute {
+			t.Errorf("Unexpected draft from %s: %+v", path, draft.Worklogs)
+		}
+	}
+
+	if _, err := LoadDraft(invalidPath); err == nil {
+		t.Error("Expected an error for a date in the wrong format")
+	}
+}
+
+func TestImportDraft(t *testing.T) {
+	service := NewMemoryService(User{AccountID: "user-1"}, Issue{Key: "INF-88"})
+	draft := &Draft{Worklogs: []DraftWorklog{
+		{Date: "01.07.2025", IssueKey: "INF-88", Hours: 1.5},
+		{Date: "01.07.2025", IssueKey: "NOPE-1", Hours: 1},
+		{Date: "02.07.2025", IssueKey: "INF-88", Hours: 0.25},
+	}}
+
+	result := ImportDraft(t.Context(), service, draft)
+
+	if len(result.Added) != 2 || len(result.Failed) != 1 || result.Failed[0].IssueKey != "NOPE-1" {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+	if result.Added[1].TimeSpentSeconds != 900 || result.Added[1].Started.Day() != 2 {
+		t.Errorf("Unexpected worklog %+v", result.Added[1])
+	}
+
+	var invalidKey *InvalidIssueKeyError
+	if !errors.As(result.Err(), &invalidKey) {
+		t.Errorf("Expected the first failure to be wrapped, got %v", result.Err())
+	}
+}
+
+func TestImportDraft_StartAndComment(t *testing.T) {
+	service := NewMemoryService(User{AccountID: "user-1"}, Issue{Key: "INF-88"})
+	draft := &Draft{Worklogs: []DraftWorklog{{Date: "01.07.2025", Start: "13:45", IssueKey: "INF-88", Hours: 1, Comment: "Review"}}}
+
+	result := ImportDraft(t.Context(), service, draft)
+
+	if len(result.Added) != 1 {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+	added := result.Added[0]
+	if !added.Started.Equal(time.Date(2025, time.July, 1, 13, 45, 0, 0, time.Local)) || added.Comment != "Review" {
+		t.Errorf("Unexpected worklog %+v", added)
+	}
+
+	draft.Worklogs[0].Start = "1:45pm"
+	if err := draft.Worklogs[0].validate(); err == nil {
+		t.Error("Expected an error for a start time in the wrong format")
+	}
+	// a mistyped or aggregated day is not logged beyond the add-worklog limit
+	draft.Worklogs[0] = DraftWorklog{Date: "01.07.2025", IssueKey: "INF-88", Hours: 80}
+	result = ImportDraft(t.Context(), service, draft)
+	if len(result.Added) != 0 || len(result.Failed) != 1 {
+		t.Errorf("Expected a worklog of 80 hours to fail, got %+v", result)
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	// a Thursday
+	now := time.Date(2025, time.July, 3, 15, 0, 0, 0, time.Local)
+
+	days, err := ParseDateRange("", "", now)
+	if err != nil {
+		t.Fatalf("ParseDateRange failed: %v", err)
+	}
+	if days.From.Format(draftDateFormat) != "30.06.2025" || days.To.Format(draftDateFormat) != "03.07.2025" {
+		t.Errorf("Expected the week so far, got %s to %s", days.From, days.To)
+	}
+	if !days.Contains(time.Date(2025, time.June, 30, 0, 0, 0, 0, time.Local)) || days.Contains(time.Date(2025, time.July, 4, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Unexpected days contained in %+v", days)
+	}
+
+	if _, err := ParseDateRange("05.07.2025", "01.07.2025", now); err == nil {
+		t.Error("Expected an error for a range ending before it starts")
+	}
+}
@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tempoo/internal/fakejira"
+)
+
+// newFakeJira starts a fake Jira server with two users and a few issues, returning it and a
+// client authenticated as the first user
+func newFakeJira(t *testing.T) (*fakejira.Server, *Tempoo) {
+	server := fakejira.New()
+	t.Cleanup(server.Close)
+
+	server.AddUser(fakejira.User{AccountID: "user-1", DisplayName: "Test User", Email: "test@example.com", APIToken: "test-token"})
+	server.AddUser(fakejira.User{AccountID: "user-2", DisplayName: "Other User", Email: "other@example.com", APIToken: "other-token"})
+	server.AddIssue(fakejira.Issue{Key: "TEST-1", Summary: "Open issue", Status: "In Progress"})
+	server.AddIssue(fakejira.Issue{Key: "TEST-2", Summary: "Closed issue", Status: "Done", ReadOnly: true})
+	server.AddIssue(fakejira.Issue{Key: "SECRET-1", Summary: "Hidden issue", Status: "To Do", Viewers: []string{"user-2"}})
+
+	client, err := NewClient(ClientOptions{BaseURL: server.APIURL(), Email: "test@example.com", APIToken: "test-token"})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return server, client
+}
+
+func TestFakeJira_WorklogLifecycle(t *testing.T) {
+	server, client := newFakeJira(t)
+
+	date := "01.07.2025"
+	added, err := client.AddWorklog(t.Context(), "TEST-1", "1.5", &date)
+	if err != nil {
+		t.Fatalf("AddWorklog() error = %v", err)
+	}
+	if added.ID == "" || added.TimeSpentSeconds != 5400 || added.AuthorAccountID != "user-1" {
+		t.Errorf("AddWorklog() = %+v", added)
+	}
+
+	ids, err := client.GetWorklogs(t.Context(), "TEST-1", "user-1")
+	if err != nil || len(ids) != 1 || ids[0] != added.ID {
+		t.Errorf("GetWorklogs() = %v, %v, want [%s]", ids, err, added.ID)
+	}
+
+	newDate := "02.07.2025"
+	updated, err := client.UpdateWorklog(t.Context(), "TEST-1", added.ID, "2", &newDate)
+	if err != nil {
+		t.Fatalf("UpdateWorklog() error = %v", err)
+	}
+	if updated.TimeSpentSeconds != 7200 || updated.Started.Day() != 2 {
+		t.Errorf("UpdateWorklog() = %+v", updated)
+	}
+
+	// durations are not limited to half hours up to a working day
+	for _, duration := range []time.Duration{45 * time.Minute, 9 * time.Hour} {
+		updated, err = client.UpdateWorklogDuration(t.Context(), "TEST-1", added.ID, duration, time.Time{})
+		if err != nil {
+			t.Fatalf("UpdateWorklogDuration(%s) error = %v", duration, err)
+		}
+		if updated.TimeSpentSeconds != int(duration.Seconds()) || updated.Started.Day() != 2 {
+			t.Errorf("UpdateWorklogDuration(%s) = %+v", duration, updated)
+		}
+	}
+
+	if err := client.DeleteWorklog(t.Context(), "TEST-1", added.ID); err != nil {
+		t.Fatalf("DeleteWorklog() error = %v", err)
+	}
+	if worklogs := server.Worklogs("TEST-1"); len(worklogs) != 0 {
+		t.Errorf("Expected no worklogs left, got %+v", worklogs)
+	}
+}
+
+func TestFakeJira_WorklogComment(t *testing.T) {
+	_, client := newFakeJira(t)
+
+	started := time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC)
+	added, err := client.AddWorklogWithComment(t.Context(), "TEST-1", started, time.Hour, "Planning\nand estimates")
+	if err != nil {
+		t.Fatalf("AddWorklogWithComment() error = %v", err)
+	}
+	if added.Comment != "Planning\nand estimates" {
+		t.Errorf("AddWorklogWithComment() comment = %q", added.Comment)
+	}
+
+	worklogs, err := client.ListWorklogs(t.Context(), "TEST-1")
+	if err != nil || len(worklogs) != 1 || worklogs[0].Comment != "Planning\nand estimates" {
+		t.Errorf("ListWorklogs() = %+v, %v", worklogs, err)
+	}
+}
+
+func TestFakeJira_Pagination(t *testing.T) {
+	server, client := newFakeJira(t)
+	server.PageSize = 2
+
+	started := time.Date(2025, 7, 1, 8, 30, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		author := "user-1"
+		if i%2 == 1 {
+			author = "user-2"
+		}
+		server.AddWorklog("TEST-1", author, started.Add(time.Duration(i)*time.Hour), 3600)
+	}
+
+	worklogs, err := client.ListWorklogs(t.Context(), "TEST-1")
+	if err != nil || len(worklogs) != 3 {
+		t.Fatalf("ListWorklogs() = %+v, %v, want 3 worklogs across pages", worklogs, err)
+	}
+
+	ids, err := client.GetWorklogs(t.Context(), "TEST-1", "user-2")
+	if err != nil || len(ids) != 2 {
+		t.Errorf("GetWorklogs() = %v, %v, want 2 IDs across pages", ids, err)
+	}
+}
+
+func TestFakeJira_Errors(t *testing.T) {
+	server, client := newFakeJira(t)
+	othersWorklog := server.AddWorklog("TEST-1", "user-2", time.Now(), 3600)
+
+	// issues the user cannot see look like they do not exist
+	_, err := client.ListWorklogs(t.Context(), "SECRET-1")
+	var invalidKey *InvalidIssueKeyError
+	if !errors.As(err, &invalidKey) {
+		t.Errorf("ListWorklogs() on a hidden issue error = %v, want an *InvalidIssueKeyError", err)
+	}
+
+	_, err = client.AddWorklog(t.Context(), "TEST-2", "1", nil)
+	var permissionErr *PermissionError
+	if !errors.As(err, &permissionErr) || permissionErr.Hint == "" {
+		t.Errorf("AddWorklog() on a closed issue error = %v, want a *PermissionError with a hint", err)
+	}
+
+	err = client.DeleteWorklog(t.Context(), "TEST-1", othersWorklog)
+	if !errors.As(err, &permissionErr) {
+		t.Errorf("DeleteWorklog() of another user's worklog error = %v, want a *PermissionError", err)
+	}
+
+	err = client.DeleteWorklog(t.Context(), "TEST-1", "1")
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("DeleteWorklog() of a missing worklog error = %v, want a *NotFoundError", err)
+	}
+
+	_, err = client.AddWorklogAt(t.Context(), "TEST-1", time.Now(), 30*time.Second)
+	if err == nil {
+		t.Error("AddWorklogAt() of 30s succeeded, want an error")
+	}
+
+	unauthorized, _ := NewClient(ClientOptions{BaseURL: server.APIURL(), Email: "test@example.com", APIToken: "wrong"})
+	_, err = unauthorized.WhoAmI(t.Context())
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Errorf("WhoAmI() with a wrong token error = %v, want an *AuthError", err)
+	}
+}
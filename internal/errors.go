@@ -1,6 +1,16 @@
 package internal
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
 
 type TempooError struct {
 	Message string
@@ -15,6 +25,11 @@ func (e *TempooError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying cause, such as a context cancellation or a network error
+func (e *TempooError) Unwrap() error {
+	return e.Cause
+}
+
 // InvalidIssueKeyError is an error type for invalid issue keys
 type InvalidIssueKeyError struct {
 	IssueKey string
@@ -24,3 +39,166 @@ type InvalidIssueKeyError struct {
 func (e *InvalidIssueKeyError) Error() string {
 	return fmt.Sprintf("Issue key %s is not valid", e.IssueKey)
 }
+
+// QueuedError is returned by a worklog change that was queued because Jira was unreachable, instead of
+// being made. It is not a failure: sync applies the change later.
+type QueuedError struct {
+	Op        string
+	IssueKey  string
+	WorklogID string
+}
+
+// error returns the error message
+func (e *QueuedError) Error() string {
+	if e.WorklogID == "" {
+		return fmt.Sprintf("Jira is unreachable, %s of a worklog on %s is queued until sync", e.Op, e.IssueKey)
+	}
+	return fmt.Sprintf("Jira is unreachable, %s of worklog %s on %s is queued until sync", e.Op, e.WorklogID, e.IssueKey)
+}
+
+// APIError is a non-success response from the Jira API. The typed errors below wrap it
+// so callers can tell failure classes apart with errors.As.
+type APIError struct {
+	TempooError
+	StatusCode int
+	// ErrorMessages are the general messages from Jira's error body
+	ErrorMessages []string
+	// FieldErrors are the per-field messages from Jira's error body
+	FieldErrors map[string]string
+	// Hint is advice on how to fix the failure, if tempoo knows any
+	Hint string
+}
+
+// Unwrap returns the underlying TempooError
+func (e *APIError) Unwrap() error {
+	return &e.TempooError
+}
+
+// AuthError is returned when Jira rejects the credentials (401)
+type AuthError struct{ APIError }
+
+// PermissionError is returned when the user may not perform the request (403)
+type PermissionError struct{ APIError }
+
+// NotFoundError is returned when the issue or worklog does not exist (404)
+type NotFoundError struct{ APIError }
+
+// RateLimitError is returned when Jira throttles the requests (429)
+type RateLimitError struct {
+	APIError
+	// RetryAfter is how long Jira asked to wait, or zero if it did not say
+	RetryAfter time.Duration
+}
+
+// ValidationError is returned when Jira rejects the request content (other 4xx)
+type ValidationError struct{ APIError }
+
+// ServerError is returned when Jira fails to handle the request (5xx)
+type ServerError struct{ APIError }
+
+// Unwrap returns the underlying APIError
+func (e *AuthError) Unwrap() error { return &e.APIError }
+
+// Unwrap returns the underlying APIError
+func (e *PermissionError) Unwrap() error { return &e.APIError }
+
+// Unwrap returns the underlying APIError
+func (e *NotFoundError) Unwrap() error { return &e.APIError }
+
+// Unwrap returns the underlying APIError
+func (e *RateLimitError) Unwrap() error { return &e.APIError }
+
+// Unwrap returns the underlying APIError
+func (e *ValidationError) Unwrap() error { return &e.APIError }
+
+// Unwrap returns the underlying APIError
+func (e *ServerError) Unwrap() error { return &e.APIError }
+
+// jiraErrorBody is the error document Jira returns with failed requests
+type jiraErrorBody struct {
+	ErrorMessages []string          `json:"errorMessages"`
+	Errors        map[string]string `json:"errors"`
+}
+
+// tempoErrorBody is the error document the Tempo API returns with failed requests
+type tempoErrorBody struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// newAPIError builds the typed error for a non-success response, with message describing what failed
+func newAPIError(message string, resp *resty.Response) error {
+	apiErr := APIError{StatusCode: resp.StatusCode()}
+
+	var body jiraErrorBody
+	var tempoBody tempoErrorBody
+	if json.Unmarshal(resp.Body(), &body) == nil {
+		apiErr.ErrorMessages = body.ErrorMessages
+		apiErr.FieldErrors = body.Errors
+	} else if json.Unmarshal(resp.Body(), &tempoBody) == nil {
+		for _, tempoErr := range tempoBody.Errors {
+			apiErr.ErrorMessages = append(apiErr.ErrorMessages, tempoErr.Message)
+		}
+	}
+	apiErr.Hint = hintFor(apiErr.StatusCode, apiErr.ErrorMessages, apiErr.FieldErrors, isTempoResponse(resp))
+	apiErr.Message = formatAPIErrorMessage(fmt.Sprintf("%s: %s", message, resp.Status()), apiErr)
+
+	status := resp.StatusCode()
+	switch {
+	case status == http.StatusUnauthorized:
+		return &AuthError{apiErr}
+	case status == http.StatusForbidden:
+		return &PermissionError{apiErr}
+	case status == http.StatusNotFound:
+		return &NotFoundError{apiErr}
+	case status == http.StatusTooManyRequests:
+		return &RateLimitError{APIError: apiErr, RetryAfter: parseRetryAfter(resp.Header().Get("Retry-After"))}
+	case status >= 500:
+		return &ServerError{apiErr}
+	case status >= 400:
+		return &ValidationError{apiErr}
+	}
+	return &apiErr
+}
+
+// isTempoResponse reports whether a response came from the Tempo API rather than Jira, telling them apart
+// by the Jira REST API path every Jira request goes to
+func isTempoResponse(resp *resty.Response) bool {
+	if resp.Request == nil || resp.Request.RawRequest == nil {
+		return false
+	}
+	return !strings.Contains(resp.Request.RawRequest.URL.Path, JiraAPIPath)
+}
+
+// formatAPIErrorMessage appends Jira's reasons and the hint to message, e.g.
+// "Failed to add worklog: 400 Bad Request: Issue is closed; started: Invalid date. Hint: ..."
+func formatAPIErrorMessage(message string, apiErr APIError) string {
+	reasons := append([]string{}, apiErr.ErrorMessages...)
+
+	fields := make([]string, 0, len(apiErr.FieldErrors))
+	for field := range apiErr.FieldErrors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", field, apiErr.FieldErrors[field]))
+	}
+
+	if len(reasons) > 0 {
+		message = fmt.Sprintf("%s: %s", message, strings.Join(reasons, "; "))
+	}
+	if apiErr.Hint != "" {
+		message = fmt.Sprintf("%s. Hint: %s", message, apiErr.Hint)
+	}
+	return message
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds, returning zero if it is missing or malformed
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -3,7 +3,9 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestTempooError_Error(t *testing.T) {
@@ -396,3 +398,84 @@ func TestErrorEdgeCases(t *testing.T) {
 		t.Run(tt.name, tt.test)
 	}
 }
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		status int
+		check  func(error) bool
+	}{
+		{401, func(err error) bool { var e *AuthError; return errors.As(err, &e) }},
+		{403, func(err error) bool { var e *PermissionError; return errors.As(err, &e) }},
+		{404, func(err error) bool { var e *NotFoundError; return errors.As(err, &e) }},
+		{429, func(err error) bool {
+			var e *RateLimitError
+			return errors.As(err, &e) && e.RetryAfter == 30*time.Second
+		}},
+		{400, func(err error) bool { var e *ValidationError; return errors.As(err, &e) }},
+		{503, func(err error) bool { var e *ServerError; return errors.As(err, &e) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /rest/api/3/issue/{key}", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{}`))
+			})
+			mux.HandleFunc("GET /rest/api/3/myself", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"accountId":"user-1"}`))
+			})
+			mux.HandleFunc("GET /rest/api/3/issue/{key}/worklog", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"errorMessages":["Nope"],"errors":{"started":"Invalid date"}}`))
+			})
+			tempoo := newTestTempoo(t, mux)
+
+			_, err := tempoo.ListWorklogs(t.Context(), "TEST-1")
+			if !tt.check(err) {
+				t.Fatalf("Unexpected error type %T for status %d", err, tt.status)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Fatalf("Expected APIError with status %d, got %v", tt.status, err)
+			}
+			if len(apiErr.ErrorMessages) != 1 || apiErr.FieldErrors["started"] != "Invalid date" {
+				t.Errorf("Expected Jira error body to be parsed, got %+v", apiErr)
+			}
+
+			var tempooErr *TempooError
+			if !errors.As(err, &tempooErr) {
+				t.Error("Expected typed error to unwrap to TempooError")
+			}
+		})
+	}
+}
+
+func TestNewAPIError_Message(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/3/issue/{key}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("POST /rest/api/3/issue/{key}/worklog", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errorMessages":["You can not log work on a closed issue"],"errors":{"timeLogged":"Invalid","started":"Invalid date"}}`))
+	})
+	tempoo := newTestTempoo(t, mux)
+
+	_, err := tempoo.AddWorklogAt(t.Context(), "TEST-1", time.Now(), time.Hour)
+	expected := "Failed to add worklog: 400 Bad Request: You can not log work on a closed issue; started: Invalid date; timeLogged: Invalid. " +
+		"Hint: Check the worklog date, which must be a valid DD.MM.YYYY date"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Error() = %v, want %q", err, expected)
+	}
+}
+
+func TestValidateIssueKey_NotFound(t *testing.T) {
+	tempoo := newTestTempoo(t, http.NotFoundHandler())
+
+	var keyErr *InvalidIssueKeyError
+	if err := tempoo.validateIssueKey(t.Context(), "TEST-1"); !errors.As(err, &keyErr) {
+		t.Errorf("Expected InvalidIssueKeyError, got %v", err)
+	}
+}
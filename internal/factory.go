@@ -1,7 +1,13 @@
 package internal
 
+import (
+	"fmt"
+	"net/http"
+)
+
 type TempooFactory struct {
 	instance *Tempoo
+	service  WorklogService
 }
 
 func NewTempooFactory() (*TempooFactory, error) {
@@ -9,9 +15,68 @@ func NewTempooFactory() (*TempooFactory, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &TempooFactory{instance: instance}, nil
+	return &TempooFactory{instance: instance, service: instance}, nil
 }
 
+// NewBackendFactory creates a factory for a backend: jira, tempo or memory.
+// The memory backend serves demo data and needs no credentials.
+func NewBackendFactory(backend string) (*TempooFactory, error) {
+	if backend == BackendMemory {
+		return NewServiceFactory(NewDemoService()), nil
+	}
+
+	factory, err := NewTempooFactory()
+	if err != nil {
+		return nil, err
+	}
+	if err := factory.UseBackend(backend); err != nil {
+		return nil, err
+	}
+	return factory, nil
+}
+
+// NewServiceFactory creates a factory serving an existing service, without a Jira client
+func NewServiceFactory(service WorklogService) *TempooFactory {
+	return &TempooFactory{service: service}
+}
+
+// GetClient returns the Jira client, which backs queue replay and undo whatever the backend.
+// It is nil for factories without Jira access, such as in demo mode.
 func (f *TempooFactory) GetClient() *Tempoo {
 	return f.instance
 }
+
+// GetService returns the worklog service of the selected backend, Jira unless UseBackend chose another
+func (f *TempooFactory) GetService() WorklogService {
+	return f.service
+}
+
+// UseBackend selects the backend GetService returns: jira, tempo or memory
+func (f *TempooFactory) UseBackend(backend string) error {
+	switch backend {
+	case BackendMemory:
+		f.service = NewDemoService()
+	case "", BackendJira:
+		f.service = f.instance
+	case BackendTempo:
+		client, err := NewTempoClient(f.instance)
+		if err != nil {
+			return err
+		}
+		f.service = client
+	default:
+		return &TempooError{Message: fmt.Sprintf("Unknown backend '%s'. Expected jira, tempo or memory", backend)}
+	}
+	return nil
+}
+
+// UseTransport sends the requests of the Jira client and the selected backend through rt,
+// e.g. to record or replay them
+func (f *TempooFactory) UseTransport(rt http.RoundTripper) {
+	if f.instance != nil {
+		f.instance.client.SetTransport(rt)
+	}
+	if client, ok := f.service.(*TempoClient); ok {
+		client.client.SetTransport(rt)
+	}
+}
@@ -344,8 +344,8 @@ func TestTempooFactory_StructFields(t *testing.T) {
 	factoryType := factoryValue.Type()
 
 	// Verify struct has expected fields
-	if factoryType.NumField() != 1 {
-		t.Errorf("Expected 1 field in TempooFactory, got %d", factoryType.NumField())
+	if factoryType.NumField() != 2 {
+		t.Errorf("Expected 2 fields in TempooFactory, got %d", factoryType.NumField())
 	}
 
 	// Verify instance field
@@ -361,6 +361,12 @@ func TestTempooFactory_StructFields(t *testing.T) {
 	if instanceField.IsNil() {
 		t.Error("Expected instance field to be non-nil")
 	}
+
+	// Verify service field defaults to the Jira client
+	serviceField := factoryValue.FieldByName("service")
+	if !serviceField.IsValid() || serviceField.IsNil() {
+		t.Error("Expected 'service' field to be set")
+	}
 }
 
 // Benchmark tests
@@ -551,3 +557,42 @@ func TestNewTempooFactory_TableDriven(t *testing.T) {
 		})
 	}
 }
+
+func TestTempooFactory_UseBackend(t *testing.T) {
+	factory := &TempooFactory{instance: &Tempoo{}}
+
+	t.Setenv("TEMPO_API_TOKEN", "tempo-token")
+	if err := factory.UseBackend(BackendTempo); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := factory.GetService().(*TempoClient); !ok {
+		t.Errorf("Expected a TempoClient service, got %T", factory.GetService())
+	}
+
+	if err := factory.UseBackend(BackendJira); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if factory.GetService() != WorklogService(factory.instance) {
+		t.Errorf("Expected the Jira client as service, got %T", factory.GetService())
+	}
+
+	if err := factory.UseBackend("harvest"); err == nil {
+		t.Error("Expected error for an unknown backend")
+	}
+}
+
+func TestNewBackendFactory_Memory(t *testing.T) {
+	t.Setenv("JIRA_EMAIL", "")
+	t.Setenv("JIRA_API_TOKEN", "")
+
+	factory, err := NewBackendFactory(BackendMemory)
+	if err != nil {
+		t.Fatalf("Expected memory backend to need no credentials, got %v", err)
+	}
+	if _, ok := factory.GetService().(*MemoryService); !ok {
+		t.Errorf("Expected a MemoryService, got %T", factory.GetService())
+	}
+	if factory.GetClient() != nil {
+		t.Error("Expected no Jira client for the memory backend")
+	}
+}
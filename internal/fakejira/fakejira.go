@@ -0,0 +1,458 @@
+// Package fakejira is an in-process fake of the parts of the Jira Cloud REST API tempoo uses,
+// for end-to-end tests and local demos without a Jira account.
+//
+// It serves /myself, /user, /search/jql, /issue/{key} and the worklog endpoints of an issue
+// with Jira's pagination, permission checks and error bodies, keeping all state in memory.
+package fakejira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// APIPath is the path of the REST API below the server's URL
+const APIPath = "/rest/api/3"
+
+// timestampFormat is the layout Jira uses for worklog timestamps
+const timestampFormat = "2006-01-02T15:04:05.000-0700"
+
+// defaultPageSize is how many worklogs a page holds when the request does not say, as in Jira
+const defaultPageSize = 5000
+
+// User is an account that can authenticate to the server with its email and API token
+type User struct {
+	AccountID   string
+	DisplayName string
+	Email       string
+	APIToken    string
+	// TimeZone is the user's time zone, UTC when empty
+	TimeZone string
+}
+
+// Issue is an issue on the server
+type Issue struct {
+	ID      string
+	Key     string
+	Summary string
+	Status  string
+	// Viewers are the account IDs allowed to see the issue. Everyone can when empty.
+	Viewers []string
+	// ReadOnly rejects new worklogs and changes to existing ones, as when the issue is closed
+	ReadOnly bool
+}
+
+// Worklog is a worklog on an issue
+type Worklog struct {
+	ID               string
+	IssueKey         string
+	AuthorAccountID  string
+	Started          time.Time
+	TimeSpentSeconds int
+	Comment          json.RawMessage
+}
+
+// Server is a running fake Jira server. Its methods are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// PageSize caps the worklogs returned per page, defaulting to Jira's 5000
+	PageSize int
+
+	mu       sync.Mutex
+	users    []*User
+	issues   map[string]*Issue
+	worklogs map[string][]*Worklog
+	nextID   int
+}
+
+// New starts a fake Jira server with no users or issues. Close it when done.
+func New() *Server {
+	s := &Server{
+		issues:   map[string]*Issue{},
+		worklogs: map[string][]*Worklog{},
+		nextID:   10000,
+	}
+	s.Server = httptest.NewServer(s.routes())
+	return s
+}
+
+// APIURL returns the root URL of the server's REST API
+func (s *Server) APIURL() string {
+	return s.URL + APIPath
+}
+
+// AddUser adds an account that can authenticate to the server
+func (s *Server) AddUser(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users = append(s.users, &user)
+}
+
+// AddIssue adds an issue, assigning it an ID if it has none
+func (s *Server) AddIssue(issue Issue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if issue.ID == "" {
+		issue.ID = strconv.Itoa(10000 + len(s.issues) + 1)
+	}
+	s.issues[issue.Key] = &issue
+}
+
+// AddWorklog stores a worklog on an issue, bypassing permission checks, and returns its ID
+func (s *Server) AddWorklog(issueKey, authorAccountID string, started time.Time, timeSpentSeconds int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addWorklog(&Worklog{IssueKey: issueKey, AuthorAccountID: authorAccountID, Started: started, TimeSpentSeconds: timeSpentSeconds}).ID
+}
+
+// Worklogs returns copies of the worklogs on an issue, oldest first
+func (s *Server) Worklogs(issueKey string) []Worklog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	worklogs := []Worklog{}
+	for _, worklog := range s.worklogs[issueKey] {
+		worklogs = append(worklogs, *worklog)
+	}
+	return worklogs
+}
+
+// routes registers the API endpoints
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+APIPath+"/myself", s.authenticated(s.handleMyself))
+	mux.HandleFunc("GET "+APIPath+"/user", s.authenticated(s.handleUser))
+	mux.HandleFunc("GET "+APIPath+"/search/jql", s.authenticated(s.handleSearch))
+	mux.HandleFunc("GET "+APIPath+"/issue/{key}", s.authenticated(s.withIssue(s.handleIssue)))
+	mux.HandleFunc("GET "+APIPath+"/issue/{key}/worklog", s.authenticated(s.withIssue(s.handleListWorklogs)))
+	mux.HandleFunc("POST "+APIPath+"/issue/{key}/worklog", s.authenticated(s.withIssue(s.handleAddWorklog)))
+	mux.HandleFunc("GET "+APIPath+"/issue/{key}/worklog/{id}", s.authenticated(s.withIssue(s.handleGetWorklog)))
+	mux.HandleFunc("PUT "+APIPath+"/issue/{key}/worklog/{id}", s.authenticated(s.withIssue(s.handleUpdateWorklog)))
+	mux.HandleFunc("DELETE "+APIPath+"/issue/{key}/worklog/{id}", s.authenticated(s.withIssue(s.handleDeleteWorklog)))
+	return mux
+}
+
+// authenticated rejects requests without the basic auth credentials of a known user,
+// and otherwise passes the user on with the server locked
+func (s *Server) authenticated(next func(http.ResponseWriter, *http.Request, *User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		email, token, ok := r.BasicAuth()
+		if ok {
+			for _, user := range s.users {
+				if user.Email == email && user.APIToken == token {
+					next(w, r, user)
+					return
+				}
+			}
+		}
+		writeError(w, http.StatusUnauthorized, nil, "Client must be authenticated to access this resource.")
+	}
+}
+
+// withIssue resolves the issue in the path, answering 404 like Jira when it does not exist or the user cannot see it
+func (s *Server) withIssue(next func(http.ResponseWriter, *http.Request, *User, *Issue)) func(http.ResponseWriter, *http.Request, *User) {
+	return func(w http.ResponseWriter, r *http.Request, user *User) {
+		issue := s.issues[r.PathValue("key")]
+		if issue == nil || !canView(issue, user) {
+			writeError(w, http.StatusNotFound, nil, "Issue does not exist or you do not have permission to see it.")
+			return
+		}
+		next(w, r, user, issue)
+	}
+}
+
+func (s *Server) handleMyself(w http.ResponseWriter, r *http.Request, user *User) {
+	writeJSON(w, http.StatusOK, s.userJSON(user.AccountID))
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request, user *User) {
+	accountID := r.URL.Query().Get("accountId")
+	for _, u := range s.users {
+		if u.AccountID == accountID {
+			writeJSON(w, http.StatusOK, s.userJSON(accountID))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, nil, "Specified user does not exist or you do not have required permissions")
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request, user *User) {
+	max, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+
+	issues := []interface{}{}
+	for _, key := range s.issueKeys() {
+		issue := s.issues[key]
+		if !canView(issue, user) {
+			continue
+		}
+		if max > 0 && len(issues) >= max {
+			break
+		}
+		issues = append(issues, issueJSON(issue))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"issues": issues})
+}
+
+func (s *Server) handleIssue(w http.ResponseWriter, r *http.Request, user *User, issue *Issue) {
+	writeJSON(w, http.StatusOK, issueJSON(issue))
+}
+
+func (s *Server) handleListWorklogs(w http.ResponseWriter, r *http.Request, user *User, issue *Issue) {
+	worklogs := s.worklogs[issue.Key]
+
+	startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+	maxResults, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+	if maxResults <= 0 {
+		maxResults = defaultPageSize
+	}
+	if s.PageSize > 0 && maxResults > s.PageSize {
+		maxResults = s.PageSize
+	}
+
+	page := []interface{}{}
+	for i := startAt; i < len(worklogs) && len(page) < maxResults; i++ {
+		page = append(page, s.worklogJSON(issue, worklogs[i]))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"startAt":    startAt,
+		"maxResults": maxResults,
+		"total":      len(worklogs),
+		"worklogs":   page,
+	})
+}
+
+func (s *Server) handleAddWorklog(w http.ResponseWriter, r *http.Request, user *User, issue *Issue) {
+	if issue.ReadOnly {
+		writeError(w, http.StatusForbidden, nil, "You do not have the permission to associate a worklog to this issue.")
+		return
+	}
+
+	worklog := &Worklog{IssueKey: issue.Key, AuthorAccountID: user.AccountID}
+	if !decodeWorklog(w, r, worklog, true) {
+		return
+	}
+	s.addWorklog(worklog)
+	writeJSON(w, http.StatusCreated, s.worklogJSON(issue, worklog))
+}
+
+func (s *Server) handleGetWorklog(w http.ResponseWriter, r *http.Request, user *User, issue *Issue) {
+	worklog := s.findWorklog(w, issue, r.PathValue("id"))
+	if worklog == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.worklogJSON(issue, worklog))
+}
+
+func (s *Server) handleUpdateWorklog(w http.ResponseWriter, r *http.Request, user *User, issue *Issue) {
+	worklog := s.findWorklog(w, issue, r.PathValue("id"))
+	if worklog == nil {
+		return
+	}
+	if issue.ReadOnly || worklog.AuthorAccountID != user.AccountID {
+		writeError(w, http.StatusForbidden, nil, "You do not have the permission to edit this worklog.")
+		return
+	}
+
+	updated := *worklog
+	if !decodeWorklog(w, r, &updated, false) {
+		return
+	}
+	*worklog = updated
+	writeJSON(w, http.StatusOK, s.worklogJSON(issue, worklog))
+}
+
+func (s *Server) handleDeleteWorklog(w http.ResponseWriter, r *http.Request, user *User, issue *Issue) {
+	worklog := s.findWorklog(w, issue, r.PathValue("id"))
+	if worklog == nil {
+		return
+	}
+	if issue.ReadOnly || worklog.AuthorAccountID != user.AccountID {
+		writeError(w, http.StatusForbidden, nil, "You do not have the permission to delete this worklog.")
+		return
+	}
+
+	worklogs := s.worklogs[issue.Key]
+	for i := range worklogs {
+		if worklogs[i] == worklog {
+			s.worklogs[issue.Key] = append(worklogs[:i:i], worklogs[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeWorklog applies a worklog request body to worklog, answering 400 with Jira's field errors
+// if it is invalid. New worklogs must say how much time was spent.
+func decodeWorklog(w http.ResponseWriter, r *http.Request, worklog *Worklog, create bool) bool {
+	var body struct {
+		Started          *string         `json:"started"`
+		TimeSpentSeconds *int            `json:"timeSpentSeconds"`
+		Comment          json.RawMessage `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, nil, "Invalid request payload. Refer to the REST API documentation and try again.")
+		return false
+	}
+
+	fieldErrors := map[string]string{}
+	if body.TimeSpentSeconds != nil {
+		if *body.TimeSpentSeconds < 60 {
+			fieldErrors["timeLogged"] = "Time Spent must be at least one minute."
+		}
+		worklog.TimeSpentSeconds = *body.TimeSpentSeconds
+	} else if create {
+		fieldErrors["timeLogged"] = "You must indicate the time spent working."
+	}
+	if body.Started != nil {
+		started, err := time.Parse(timestampFormat, *body.Started)
+		if err != nil {
+			fieldErrors["started"] = "Invalid date format. Please enter the date in the format \"yyyy-MM-dd'T'HH:mm:ss.SSSZ\"."
+		}
+		worklog.Started = started
+	} else if create {
+		fieldErrors["started"] = "You must specify a start date."
+	}
+	if body.Comment != nil {
+		worklog.Comment = body.Comment
+	}
+
+	if len(fieldErrors) > 0 {
+		writeError(w, http.StatusBadRequest, fieldErrors)
+		return false
+	}
+	return true
+}
+
+// addWorklog assigns a worklog an ID and stores it. Callers hold the lock.
+func (s *Server) addWorklog(worklog *Worklog) *Worklog {
+	s.nextID++
+	worklog.ID = strconv.Itoa(s.nextID)
+	s.worklogs[worklog.IssueKey] = append(s.worklogs[worklog.IssueKey], worklog)
+	return worklog
+}
+
+// findWorklog returns a worklog on an issue, answering 404 if there is none with the ID
+func (s *Server) findWorklog(w http.ResponseWriter, issue *Issue, id string) *Worklog {
+	for _, worklog := range s.worklogs[issue.Key] {
+		if worklog.ID == id {
+			return worklog
+		}
+	}
+	writeError(w, http.StatusNotFound, nil, fmt.Sprintf("Cannot find worklog with id: %s", id))
+	return nil
+}
+
+// issueKeys returns the keys of all issues in order
+func (s *Server) issueKeys() []string {
+	keys := make([]string, 0, len(s.issues))
+	for key := range s.issues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// canView reports whether user may see issue
+func canView(issue *Issue, user *User) bool {
+	if len(issue.Viewers) == 0 {
+		return true
+	}
+	for _, accountID := range issue.Viewers {
+		if accountID == user.AccountID {
+			return true
+		}
+	}
+	return false
+}
+
+// userJSON returns a user in Jira's shape
+func (s *Server) userJSON(accountID string) map[string]interface{} {
+	for _, user := range s.users {
+		if user.AccountID == accountID {
+			return map[string]interface{}{
+				"accountId":    user.AccountID,
+				"displayName":  user.DisplayName,
+				"emailAddress": user.Email,
+				"timeZone":     timeZone(user),
+				"active":       true,
+			}
+		}
+	}
+	return map[string]interface{}{"accountId": accountID}
+}
+
+// timeZone returns the time zone of a user
+func timeZone(user *User) string {
+	if user.TimeZone == "" {
+		return "UTC"
+	}
+	return user.TimeZone
+}
+
+// issueJSON returns an issue in Jira's shape
+func issueJSON(issue *Issue) map[string]interface{} {
+	return map[string]interface{}{
+		"id":  issue.ID,
+		"key": issue.Key,
+		"fields": map[string]interface{}{
+			"summary": issue.Summary,
+			"status":  map[string]string{"name": issue.Status},
+		},
+	}
+}
+
+// worklogJSON returns a worklog in Jira's shape
+func (s *Server) worklogJSON(issue *Issue, worklog *Worklog) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":               worklog.ID,
+		"issueId":          issue.ID,
+		"author":           s.userJSON(worklog.AuthorAccountID),
+		"updateAuthor":     s.userJSON(worklog.AuthorAccountID),
+		"started":          worklog.Started.Format(timestampFormat),
+		"timeSpent":        timeSpent(worklog.TimeSpentSeconds),
+		"timeSpentSeconds": worklog.TimeSpentSeconds,
+	}
+	if worklog.Comment != nil {
+		result["comment"] = worklog.Comment
+	}
+	return result
+}
+
+// timeSpent formats seconds the way Jira displays time spent, such as "1h 30m"
+func timeSpent(seconds int) string {
+	hours, minutes := seconds/3600, seconds%3600/60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an error response in Jira's shape
+func writeError(w http.ResponseWriter, status int, fieldErrors map[string]string, messages ...string) {
+	if fieldErrors == nil {
+		fieldErrors = map[string]string{}
+	}
+	if messages == nil {
+		messages = []string{}
+	}
+	writeJSON(w, status, map[string]interface{}{"errorMessages": messages, "errors": fieldErrors})
+}
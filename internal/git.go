@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultBranchPattern finds a Jira issue key anywhere in a branch name, as in feature/INF-88-fix-thing
+const DefaultBranchPattern = `[A-Z][A-Z0-9_]+-[0-9]+`
+
+// branchRefPrefix starts the content of .git/HEAD when a branch is checked out
+const branchRefPrefix = "ref: refs/heads/"
+
+// GitConfig controls how issue keys are derived from git branches
+type GitConfig struct {
+	// BranchPattern is the regular expression finding the issue key in a branch name.
+	// With a capture group, the first group is the issue key; otherwise the whole match is.
+	BranchPattern string `yaml:"branch_pattern"`
+}
+
+// IssueKeyFromBranch returns the issue key found in a branch name, or false if there is none
+func (g GitConfig) IssueKeyFromBranch(branch string) (string, bool) {
+	pattern := g.BranchPattern
+	if pattern == "" {
+		pattern = DefaultBranchPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", false
+	}
+
+	match := re.FindStringSubmatch(branch)
+	switch {
+	case match == nil:
+		return "", false
+	case len(match) > 1:
+		return match[1], match[1] != ""
+	default:
+		return match[0], true
+	}
+}
+
+// validate checks the branch pattern compiles
+func (g GitConfig) validate() error {
+	if _, err := regexp.Compile(g.BranchPattern); err != nil {
+		return &TempooError{Message: fmt.Sprintf("Invalid git branch pattern '%s'", g.BranchPattern), Cause: err}
+	}
+	return nil
+}
+
+// CurrentBranch returns the branch checked out in the git repository containing dir, read from HEAD
+// without running git. It returns an empty name outside a repository or when HEAD is detached.
+func CurrentBranch(dir string) (string, error) {
+	gitDir, err := findGitDir(dir)
+	if err != nil || gitDir == "" {
+		return "", err
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", &TempooError{Message: fmt.Sprintf("Failed to read git HEAD in %s", gitDir), Cause: err}
+	}
+
+	ref := strings.TrimSpace(string(head))
+	if !strings.HasPrefix(ref, branchRefPrefix) {
+		return "", nil
+	}
+	return strings.TrimPrefix(ref, branchRefPrefix), nil
+}
+
+// findGitDir returns the git directory of the repository containing dir, or an empty path outside one.
+// Worktrees and submodules have a .git file pointing to the git directory instead of a .git directory.
+func findGitDir(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", &TempooError{Message: "Failed to locate git repository", Cause: err}
+	}
+
+	for {
+		path := filepath.Join(dir, ".git")
+		info, err := os.Stat(path)
+		switch {
+		case err == nil && info.IsDir():
+			return path, nil
+		case err == nil:
+			return readGitFile(path)
+		case !errors.Is(err, os.ErrNotExist):
+			return "", &TempooError{Message: fmt.Sprintf("Failed to read %s", path), Cause: err}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// readGitFile returns the git directory a .git file points to
+func readGitFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", &TempooError{Message: fmt.Sprintf("Failed to read %s", path), Cause: err}
+	}
+
+	content := strings.TrimSpace(string(data))
+	gitDir, ok := strings.CutPrefix(content, "gitdir: ")
+	if !ok {
+		return "", &TempooError{Message: fmt.Sprintf("Unexpected content in %s", path)}
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return gitDir, nil
+}
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile creates a file and its parent directories
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCurrentBranch(t *testing.T) {
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "ref: refs/heads/feature/INF-88-fix-thing\n")
+	subdir := filepath.Join(repo, "src", "pkg")
+	if err := os.MkdirAll(subdir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// a worktree has a .git file pointing to its git directory
+	worktree := t.TempDir()
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: ../wt-git\n")
+	writeFile(t, filepath.Join(filepath.Dir(worktree), "wt-git", "HEAD"), "ref: refs/heads/OPS-7\n")
+
+	detached := t.TempDir()
+	writeFile(t, filepath.Join(detached, ".git", "HEAD"), "4b825dc642cb6eb9a060e54bf8d69288fbee4904\n")
+
+	tests := []struct {
+		name     string
+		dir      string
+		expected string
+	}{
+		{"repository root", repo, "feature/INF-88-fix-thing"},
+		{"subdirectory", subdir, "feature/INF-88-fix-thing"},
+		{"worktree", worktree, "OPS-7"},
+		{"detached head", detached, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			branch, err := CurrentBranch(tt.dir)
+			if err != nil {
+				t.Fatalf("CurrentBranch failed: %v", err)
+			}
+			if branch != tt.expected {
+				t.Errorf("Expected branch %q, got %q", tt.expected, branch)
+			}
+		})
+	}
+}
+
+func TestGitConfig_IssueKeyFromBranch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		branch   string
+		expected string
+		found    bool
+	}{
+		{DefaultBranchPattern, "feature/INF-88-fix-thing", "INF-88", true},
+		{DefaultBranchPattern, "OPS-7", "OPS-7", true},
+		{DefaultBranchPattern, "main", "", false},
+		{"", "bugfix/PROJ_X-12", "PROJ_X-12", true},
+		{`^[a-z]+/([a-z]+-[0-9]+)`, "feature/inf-88-fix", "inf-88", true},
+		{`^[a-z]+/([a-z]+-[0-9]+)`, "INF-88", "", false},
+	}
+
+	for _, tt := range tests {
+		issueKey, found := GitConfig{BranchPattern: tt.pattern}.IssueKeyFromBranch(tt.branch)
+		if issueKey != tt.expected || found != tt.found {
+			t.Errorf("IssueKeyFromBranch(%q) with %q = %q, %v, want %q, %v", tt.branch, tt.pattern, issueKey, found, tt.expected, tt.found)
+		}
+	}
+}
+
+func TestLoadConfigFile_InvalidBranchPattern(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	writeFile(t, path, "git:\n  branch_pattern: \"([A-Z\"\n")
+
+	if _, err := LoadConfigFile(path); err == nil {
+		t.Error("Expected an error for an invalid branch pattern")
+	}
+}
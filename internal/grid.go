@@ -0,0 +1,237 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Grid is a range of days of the current user's worklogs laid out as issues by days, edited in the tui command.
+// Cells hold the hours entered next to the worklogs already logged, so edits can be turned into API calls.
+type Grid struct {
+	Days DateRange
+	Rows []*GridRow
+}
+
+// GridRow is an issue in a grid
+type GridRow struct {
+	IssueKey string
+	Summary  string
+	// Cells has one cell per day of the grid
+	Cells []GridCell
+}
+
+// GridCell is the time on an issue on one day
+type GridCell struct {
+	// Worklogs are the worklogs logged on the day
+	Worklogs Worklogs
+	// Hours is the time entered, which starts as the time logged
+	Hours float64
+}
+
+// Logged returns the hours of the worklogs logged in the cell
+func (c GridCell) Logged() float64 {
+	seconds := 0
+	for _, worklog := range c.Worklogs {
+		seconds += worklog.TimeSpentSeconds
+	}
+	return float64(seconds) / 3600
+}
+
+// Changed reports whether the hours entered differ from the hours logged
+func (c GridCell) Changed() bool {
+	return c.Hours != c.Logged()
+}
+
+// NewGrid lays out worklogs by issue and day, with issues sorted by key. Worklogs outside the days are left out.
+func NewGrid(days DateRange, worklogs Worklogs) *Grid {
+	grid := &Grid{Days: days}
+	for _, worklog := range worklogs {
+		day := grid.dayIndex(worklog.Started)
+		if day < 0 {
+			continue
+		}
+		row := grid.Rows[grid.AddRow(worklog.IssueKey, "")]
+		row.Cells[day].Worklogs = append(row.Cells[day].Worklogs, worklog)
+		row.Cells[day].Hours = row.Cells[day].Logged()
+	}
+	sort.SliceStable(grid.Rows, func(i, j int) bool { return grid.Rows[i].IssueKey < grid.Rows[j].IssueKey })
+	return grid
+}
+
+// DayCount returns the number of days in the grid
+func (g *Grid) DayCount() int {
+	return int(g.Days.To.Sub(g.Days.From).Hours()/24) + 1
+}
+
+// Day returns the calendar day of a column
+func (g *Grid) Day(i int) time.Time {
+	return g.Days.From.AddDate(0, 0, i)
+}
+
+// dayIndex returns the column of the local day of t, or -1 if it is outside the grid
+func (g *Grid) dayIndex(t time.Time) int {
+	if !g.Days.Contains(t) {
+		return -1
+	}
+	return int(calendarDay(t).Sub(g.Days.From).Hours() / 24)
+}
+
+// AddRow adds an empty row for an issue unless it already has one, and returns the row's index
+func (g *Grid) AddRow(issueKey, summary string) int {
+	for i, row := range g.Rows {
+		if row.IssueKey == issueKey {
+			if summary != "" {
+				row.Summary = summary
+			}
+			return i
+		}
+	}
+	g.Rows = append(g.Rows, &GridRow{IssueKey: issueKey, Summary: summary, Cells: make([]GridCell, g.DayCount())})
+	return len(g.Rows) - 1
+}
+
+// SetHours enters hours into a cell, following the rules of add-worklog. An empty value or 0 clears the cell.
+func (g *Grid) SetHours(row, day int, value string) error {
+	value = strings.TrimSpace(value)
+	hours := 0.0
+	if value != "" && value != "0" {
+		var err error
+		if hours, err = validateWorklogHours(value); err != nil {
+			return err
+		}
+	}
+	g.Rows[row].Cells[day].Hours = hours
+	return nil
+}
+
+// Totals returns the hours entered on each day
+func (g *Grid) Totals() []float64 {
+	totals := make([]float64, g.DayCount())
+	for _, row := range g.Rows {
+		for day, cell := range row.Cells {
+			totals[day] += cell.Hours
+		}
+	}
+	return totals
+}
+
+// GridChange is an add, edit or delete call that brings the logged worklogs in line with a grid
+type GridChange struct {
+	Op       string  `json:"op"`
+	IssueKey string  `json:"issue_key"`
+	Date     string  `json:"date"`
+	Hours    float64 `json:"hours"`
+	// WorklogID is the worklog edited or deleted, or the worklog added once applied
+	WorklogID string `json:"worklog_id,omitempty"`
+	// Comment is the comment of a deleted worklog, which is lost with it
+	Comment string `json:"comment,omitempty"`
+	// Status is applied, queued or failed once the change is applied
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	err    error
+}
+
+// GridChanges is a list of changes to a grid's worklogs
+type GridChanges []GridChange
+
+// Changes returns the calls needed to log the hours entered, by issue and day. A cell with several worklogs
+// is edited by setting its first worklog to the hours entered and deleting the others, whose comments are
+// carried on the deletions so the review can show they will be lost.
+func (g *Grid) Changes() GridChanges {
+	changes := GridChanges{}
+	for _, row := range g.Rows {
+		for day, cell := range row.Cells {
+			if !cell.Changed() {
+				continue
+			}
+			date := g.Day(day).Format(draftDateFormat)
+			switch {
+			case len(cell.Worklogs) == 0:
+				changes = append(changes, GridChange{Op: OpAddWorklog, IssueKey: row.IssueKey, Date: date, Hours: cell.Hours})
+			case cell.Hours == 0:
+				for _, worklog := range cell.Worklogs {
+					changes = append(changes, GridChange{Op: OpDeleteWorklog, IssueKey: row.IssueKey, Date: date, Hours: float64(worklog.TimeSpentSeconds) / 3600, WorklogID: worklog.ID, Comment: worklog.Comment})
+				}
+			default:
+				changes = append(changes, GridChange{Op: OpEditWorklog, IssueKey: row.IssueKey, Date: date, Hours: cell.Hours, WorklogID: cell.Worklogs[0].ID})
+				for _, worklog := range cell.Worklogs[1:] {
+					changes = append(changes, GridChange{Op: OpDeleteWorklog, IssueKey: row.IssueKey, Date: date, Hours: float64(worklog.TimeSpentSeconds) / 3600, WorklogID: worklog.ID, Comment: worklog.Comment})
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// ApplyGridChanges makes the calls of a grid's changes in order and returns them with their outcome.
+// Failures do not stop the other changes.
+func ApplyGridChanges(ctx context.Context, service WorklogService, changes GridChanges) GridChanges {
+	applied := GridChanges{}
+	for _, change := range changes {
+		err := applyGridChange(ctx, service, &change)
+		var queued *QueuedError
+		switch {
+		case errors.As(err, &queued):
+			change.Status = "queued"
+		case err != nil:
+			change.Status, change.Error, change.err = "failed", err.Error(), err
+		default:
+			change.Status = "applied"
+		}
+		applied = append(applied, change)
+	}
+	return applied
+}
+
+// applyGridChange makes the call of one change, recording the ID of an added worklog. A change queued
+// because Jira is unreachable returns a *QueuedError.
+func applyGridChange(ctx context.Context, service WorklogService, change *GridChange) error {
+	switch change.Op {
+	case OpAddWorklog:
+		started, err := worklogStart(&change.Date)
+		if err != nil {
+			return err
+		}
+		added, err := service.AddWorklogAt(ctx, change.IssueKey, started, time.Duration(change.Hours*float64(time.Hour)))
+		if err != nil {
+			return err
+		}
+		if added.Queued {
+			return &QueuedError{Op: OpAddWorklog, IssueKey: change.IssueKey}
+		}
+		change.WorklogID = added.ID
+		return nil
+	case OpEditWorklog:
+		_, err := service.UpdateWorklog(ctx, change.IssueKey, change.WorklogID, strconv.FormatFloat(change.Hours, 'f', -1, 64), nil)
+		return err
+	case OpDeleteWorklog:
+		return service.DeleteWorklog(ctx, change.IssueKey, change.WorklogID)
+	}
+	return &TempooError{Message: fmt.Sprintf("Unknown change '%s'", change.Op)}
+}
+
+// Failed returns the number of changes that failed to apply
+func (c GridChanges) Failed() int {
+	failed := 0
+	for _, change := range c {
+		if change.Status == "failed" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Err returns an error describing the failed changes, wrapping the first failure, or nil if every change was applied
+func (c GridChanges) Err() error {
+	for _, change := range c {
+		if change.err != nil {
+			return &TempooError{Message: fmt.Sprintf("Failed to apply %d of %d change(s)", c.Failed(), len(c)), Cause: change.err}
+		}
+	}
+	return nil
+}
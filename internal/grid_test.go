@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestGrid(t *testing.T) {
+	week, _ := ParseWeek("01.07.2025", time.Now())
+	grid := NewGrid(week, Worklogs{
+		{ID: "1", IssueKey: "INF-2", Started: time.Date(2025, time.July, 1, 9, 0, 0, 0, time.Local), TimeSpentSeconds: 3600},
+		{ID: "2", IssueKey: "INF-1", Started: time.Date(2025, time.July, 2, 9, 0, 0, 0, time.Local), TimeSpentSeconds: 1800},
+		{ID: "3", IssueKey: "INF-1", Started: time.Date(2025, time.July, 2, 14, 0, 0, 0, time.Local), TimeSpentSeconds: 3600, Comment: "Review"},
+		{ID: "4", IssueKey: "INF-1", Started: time.Date(2025, time.July, 9, 9, 0, 0, 0, time.Local), TimeSpentSeconds: 3600},
+	})
+
+	if len(grid.Rows) != 2 || grid.Rows[0].IssueKey != "INF-1" || grid.Rows[0].Cells[2].Hours != 1.5 || grid.Rows[1].Cells[1].Hours != 1 {
+		t.Fatalf("Unexpected grid %+v", grid.Rows)
+	}
+	if changes := grid.Changes(); len(changes) != 0 {
+		t.Errorf("Expected no changes before editing, got %+v", changes)
+	}
+
+	if err := grid.SetHours(0, 2, "9"); err == nil {
+		t.Error("Expected an error for more than 8 hours")
+	}
+	grid.SetHours(0, 2, "2")
+	grid.SetHours(1, 1, "")
+	row := grid.AddRow("INF-3", "New issue")
+	grid.SetHours(row, 4, "0.5")
+
+	if totals := grid.Totals(); totals[2] != 2 || totals[4] != 0.5 || totals[1] != 0 {
+		t.Errorf("Unexpected totals %v", totals)
+	}
+
+	expected := GridChanges{
+		{Op: OpEditWorklog, IssueKey: "INF-1", Date: "02.07.2025", Hours: 2, WorklogID: "2"},
+		{Op: OpDeleteWorklog, IssueKey: "INF-1", Date: "02.07.2025", Hours: 1, WorklogID: "3", Comment: "Review"},
+		{Op: OpDeleteWorklog, IssueKey: "INF-2", Date: "01.07.2025", Hours: 1, WorklogID: "1"},
+		{Op: OpAddWorklog, IssueKey: "INF-3", Date: "04.07.2025", Hours: 0.5},
+	}
+	changes := grid.Changes()
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], changes[i])
+		}
+	}
+	// merging worklogs loses the comments of the deleted ones, which the review shows
+	if text := changes.Text(); !strings.Contains(text, `losing comment "Review"`) {
+		t.Errorf("Expected the lost comment in %q", text)
+	}
+}
+
+func TestApplyGridChanges(t *testing.T) {
+	service := NewMemoryService(User{AccountID: "user-1"}, Issue{Key: "INF-1"})
+	date := "01.07.2025"
+	existing, _ := service.AddWorklog(t.Context(), "INF-1", "1", &date)
+
+	applied := ApplyGridChanges(t.Context(), service, GridChanges{
+		{Op: OpEditWorklog, IssueKey: "INF-1", Date: date, Hours: 2, WorklogID: existing.ID},
+		{Op: OpAddWorklog, IssueKey: "INF-1", Date: "02.07.2025", Hours: 1.5},
+		{Op: OpAddWorklog, IssueKey: "NOPE-1", Date: "02.07.2025", Hours: 1},
+	})
+
+	if applied.Failed() != 1 || applied[1].Status != "applied" || applied[1].WorklogID == "" || applied[2].Status != "failed" {
+		t.Errorf("Unexpected outcome %+v", applied)
+	}
+	if applied.Err() == nil {
+		t.Error("Expected an error for the failed change")
+	}
+	worklogs, _ := service.ListWorklogs(t.Context(), "INF-1")
+	if len(worklogs) != 2 || worklogs[0].TimeSpentSeconds != 7200 || worklogs[1].TimeSpentSeconds != 5400 {
+		t.Errorf("Unexpected worklogs %+v", worklogs)
+	}
+}
+
+func TestApplyGridChanges_Queued(t *testing.T) {
+	// nothing listens on this port, so the requests fail in transport
+	client := resty.New().SetTransport(&redirectTransport{target: &url.URL{Scheme: "http", Host: "127.0.0.1:1"}})
+	tempoo := &Tempoo{client: client}
+	tempoo.EnableOfflineQueue(NewQueue(t.TempDir()))
+
+	applied := ApplyGridChanges(t.Context(), tempoo, GridChanges{
+		{Op: OpAddWorklog, IssueKey: "TEST-1", Date: "01.07.2025", Hours: 1},
+		{Op: OpDeleteWorklog, IssueKey: "TEST-1", Date: "01.07.2025", Hours: 1, WorklogID: "10001"},
+	})
+	for _, change := range applied {
+		if change.Status != "queued" || change.Error != "" {
+			t.Errorf("Expected the change to be queued, got %+v", change)
+		}
+	}
+	if applied.Err() != nil {
+		t.Errorf("Expected no error for queued changes, got %v", applied.Err())
+	}
+}
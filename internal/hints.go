@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"net/http"
+	"strings"
+)
+
+// errorHint maps a Jira error to advice on how to fix it. A hint matches when Jira reports
+// an error for field, or when one of its messages contains text, compared case-insensitively.
+type errorHint struct {
+	field string
+	text  string
+	hint  string
+}
+
+// errorHints lists the Jira errors users commonly run into, checked in order
+var errorHints = []errorHint{
+	{text: "time tracking", hint: "Time tracking is disabled for this issue or project. Ask a Jira admin to enable it"},
+	{text: "timetracking", hint: "Time tracking is disabled for this issue or project. Ask a Jira admin to enable it"},
+	{text: "not editable", hint: "The issue may be closed. Reopen it or log the time on another issue"},
+	{text: "cannot be edited", hint: "The issue may be closed. Reopen it or log the time on another issue"},
+	{text: "does not exist or you do not have permission", hint: "Check the issue key and that your account can browse the project"},
+	{text: "permission", hint: "Ask a Jira admin for the 'Work on issues' permission in this project"},
+	{field: "started", hint: "Check the worklog date, which must be a valid DD.MM.YYYY date"},
+	{field: "timeLogged", hint: "Check the hours, which must be between 0.5 and 8"},
+	{field: "timeSpent", hint: "Check the hours, which must be between 0.5 and 8"},
+	{field: "timeSpentSeconds", hint: "Check the hours, which must be between 0.5 and 8"},
+}
+
+// statusHints is the fallback advice for a status when no Jira error matched
+var statusHints = map[int]string{
+	http.StatusUnauthorized:    "Check JIRA_EMAIL and JIRA_API_TOKEN, the API token may have expired",
+	http.StatusForbidden:       "Your account is not allowed to do this. Ask a Jira admin for access to the project",
+	http.StatusTooManyRequests: "Jira is rate limiting requests. Wait a moment and try again",
+}
+
+// tempoStatusHints is the fallback advice for a status of the Tempo API, whose token is separate from Jira's
+var tempoStatusHints = map[int]string{
+	http.StatusUnauthorized:    "Check TEMPO_API_TOKEN, the Tempo API token may have expired or been revoked",
+	http.StatusForbidden:       "The Tempo API token or your account is not allowed to do this. Check the token's scopes and your Tempo permissions",
+	http.StatusTooManyRequests: "Tempo is rate limiting requests. Wait a moment and try again",
+}
+
+// hintFor returns advice for a failed response from Jira, or from Tempo if tempo is set,
+// or an empty string if there is none
+func hintFor(status int, messages []string, fieldErrors map[string]string, tempo bool) string {
+	for _, h := range errorHints {
+		if h.field != "" {
+			if _, ok := fieldErrors[h.field]; ok {
+				return h.hint
+			}
+			continue
+		}
+
+		for _, message := range messages {
+			if strings.Contains(strings.ToLower(message), h.text) {
+				return h.hint
+			}
+		}
+		for _, message := range fieldErrors {
+			if strings.Contains(strings.ToLower(message), h.text) {
+				return h.hint
+			}
+		}
+	}
+	if tempo {
+		return tempoStatusHints[status]
+	}
+	return statusHints[status]
+}
@@ -0,0 +1,57 @@
+package internal
+
+import "testing"
+
+func TestHintFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		messages    []string
+		fieldErrors map[string]string
+		tempo       bool
+		expected    string
+	}{
+		{
+			name:     "time tracking disabled",
+			status:   400,
+			messages: []string{"Time Tracking is not enabled"},
+			expected: "Time tracking is disabled for this issue or project. Ask a Jira admin to enable it",
+		},
+		{
+			name:        "invalid started",
+			status:      400,
+			fieldErrors: map[string]string{"started": "Invalid date format"},
+			expected:    "Check the worklog date, which must be a valid DD.MM.YYYY date",
+		},
+		{
+			name:     "missing issue before permission",
+			status:   404,
+			messages: []string{"Issue does not exist or you do not have permission to see it."},
+			expected: "Check the issue key and that your account can browse the project",
+		},
+		{
+			name:     "status fallback",
+			status:   401,
+			expected: "Check JIRA_EMAIL and JIRA_API_TOKEN, the API token may have expired",
+		},
+		{
+			name:     "tempo status fallback",
+			status:   401,
+			tempo:    true,
+			expected: "Check TEMPO_API_TOKEN, the Tempo API token may have expired or been revoked",
+		},
+		{
+			name:     "no hint",
+			status:   500,
+			messages: []string{"Internal error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hintFor(tt.status, tt.messages, tt.fieldErrors, tt.tempo); got != tt.expected {
+				t.Errorf("hintFor() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
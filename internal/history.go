@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// HistoryFileName is the name of the worklog change journal inside the tempoo home directory
+const HistoryFileName = "history.jsonl"
+
+// maxHistoryEntries is the number of most recent entries the journal keeps, a variable so tests can shorten it
+var maxHistoryEntries = 1000
+
+// HistoryEntry records one worklog change made through tempoo, with enough data to reverse it
+type HistoryEntry struct {
+	ID        string    `json:"id"`
+	At        time.Time `json:"at"`
+	Op        string    `json:"op"`
+	IssueKey  string    `json:"issue_key"`
+	WorklogID string    `json:"worklog_id"`
+	// Worklog is the worklog after an add or edit, or the worklog as it was before a delete
+	Worklog WorklogData `json:"worklog,omitempty"`
+	// Previous is the worklog before an edit
+	Previous WorklogData `json:"previous,omitempty"`
+	UndoneAt *time.Time  `json:"undone_at,omitempty"`
+}
+
+// HistoryEntries is a list of history entries
+type HistoryEntries []*HistoryEntry
+
+// History is an append-only journal of the worklog changes made through tempoo
+type History struct {
+	path string
+}
+
+// NewHistory creates a history journal backed by the journal file in dir
+func NewHistory(dir string) *History {
+	return &History{path: filepath.Join(dir, HistoryFileName)}
+}
+
+// Record appends an entry to the journal, dropping the oldest entries once it holds maxHistoryEntries
+func (h *History) Record(entry *HistoryEntry) error {
+	entry.ID = newOperationID()
+	entry.At = time.Now()
+
+	return withFileLock(h.path, func() error {
+		entries, err := readJSONLines[HistoryEntry](h.path)
+		if err != nil {
+			return err
+		}
+		if len(entries) < maxHistoryEntries {
+			return appendJSONLine(h.path, entry)
+		}
+		return writeJSONLines(h.path, append(entries[len(entries)-maxHistoryEntries+1:], entry))
+	})
+}
+
+// List returns up to the n most recent entries, newest first, or every entry if n is not positive
+func (h *History) List(n int) (HistoryEntries, error) {
+	var entries []*HistoryEntry
+	err := withFileLock(h.path, func() error {
+		var err error
+		entries, err = readJSONLines[HistoryEntry](h.path)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	latest := HistoryEntries{}
+	for i := len(entries) - 1; i >= 0 && (n <= 0 || len(latest) < n); i-- {
+		latest = append(latest, entries[i])
+	}
+	return latest, nil
+}
+
+// markUndone flags an entry as reversed. When undoing recreated a deleted worklog under
+// a new ID, other entries referring to the old ID are repointed so they can still be undone.
+func (h *History) markUndone(entryID, oldWorklogID, newWorklogID string) error {
+	return withFileLock(h.path, func() error {
+		entries, err := readJSONLines[HistoryEntry](h.path)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, entry := range entries {
+			if entry.ID == entryID {
+				entry.UndoneAt = &now
+			}
+			if newWorklogID != "" && entry.WorklogID == oldWorklogID {
+				entry.WorklogID = newWorklogID
+			}
+		}
+
+		if len(entries) > maxHistoryEntries {
+			entries = entries[len(entries)-maxHistoryEntries:]
+		}
+		return writeJSONLines(h.path, entries)
+	})
+}
+
+// record journals a change if history is enabled. Failing to journal never fails the change itself.
+func (t *Tempoo) record(entry *HistoryEntry) {
+	if t.history == nil {
+		return
+	}
+	if err := t.history.Record(entry); err != nil {
+		t.log().Warnf("Failed to record %s of worklog %s in history: %v", entry.Op, entry.WorklogID, err)
+	}
+}
+
+// Undo reverses the n most recent changes that have not been undone yet, newest first.
+// Added worklogs are deleted, deleted worklogs are recreated and edited worklogs are restored.
+// It stops at the first change that cannot be reversed.
+func (t *Tempoo) Undo(ctx context.Context, h *History, n int) (HistoryEntries, error) {
+	entries, err := h.List(0)
+	if err != nil {
+		return nil, err
+	}
+
+	undone := HistoryEntries{}
+	for _, entry := range entries {
+		if len(undone) >= n {
+			break
+		}
+		if entry.UndoneAt != nil {
+			continue
+		}
+
+		newWorklogID, err := t.reverse(ctx, entry)
+		if err != nil {
+			return undone, &TempooError{Message: fmt.Sprintf("Failed to undo %s of worklog %s on %s", entry.Op, entry.WorklogID, entry.IssueKey), Cause: err}
+		}
+		if err := h.markUndone(entry.ID, entry.WorklogID, newWorklogID); err != nil {
+			return undone, err
+		}
+
+		t.log().Infof("Undid %s of worklog %s on %s", entry.Op, entry.WorklogID, entry.IssueKey)
+		undone = append(undone, entry)
+	}
+
+	if len(undone) == 0 {
+		t.log().Info("Nothing to undo")
+	}
+	return undone, nil
+}
+
+// reverse applies the inverse of a journaled change, returning the new worklog ID if one was recreated
+func (t *Tempoo) reverse(ctx context.Context, entry *HistoryEntry) (string, error) {
+	switch entry.Op {
+	case OpAddWorklog:
+		resp, err := t.sendWorklogDeletion(ctx, entry.IssueKey, entry.WorklogID)
+		if err != nil {
+			return "", err
+		}
+		// already gone is as good as deleted
+		if resp.StatusCode() != 204 && resp.StatusCode() != 404 {
+			return "", newAPIError("Failed to delete worklog", resp)
+		}
+		return "", nil
+
+	case OpDeleteWorklog:
+		resp, err := t.sendWorklogPayload(ctx, entry.IssueKey, recreatePayload(entry.Worklog))
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode() != 201 {
+			return "", newAPIError("Failed to recreate worklog", resp)
+		}
+
+		var created WorklogData
+		if err := json.Unmarshal(resp.Body(), &created); err != nil {
+			return "", &TempooError{Message: "Failed to parse worklog data", Cause: err}
+		}
+		return worklogID(created), nil
+
+	case OpEditWorklog:
+		resp, err := t.sendWorklogUpdate(ctx, entry.IssueKey, entry.WorklogID, recreatePayload(entry.Previous))
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode() != 200 {
+			return "", newAPIError("Failed to restore worklog", resp)
+		}
+		return "", nil
+	}
+
+	return "", &TempooError{Message: fmt.Sprintf("Unknown history operation '%s'", entry.Op)}
+}
+
+// recreatePayload keeps the fields of a fetched worklog that Jira accepts when creating or updating one
+func recreatePayload(worklog WorklogData) map[string]interface{} {
+	payload := map[string]interface{}{}
+	for _, field := range []string{"started", "timeSpentSeconds", "comment", "visibility"} {
+		if value, ok := worklog[field]; ok {
+			payload[field] = value
+		}
+	}
+	return payload
+}
+
+// worklogID returns the ID of a worklog as a string, whether Jira sent it as a string or a number
+func worklogID(worklog WorklogData) string {
+	switch id := worklog["id"].(type) {
+	case string:
+		return id
+	case float64:
+		return fmt.Sprintf("%.0f", id)
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestHistory_RecordAndList(t *testing.T) {
+	history := NewHistory(t.TempDir())
+
+	for _, id := range []string{"1", "2", "3"} {
+		if err := history.Record(&HistoryEntry{Op: OpAddWorklog, IssueKey: "TEST-1", WorklogID: id}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	entries, err := history.List(2)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	// newest first, limited to n
+	if len(entries) != 2 || entries[0].WorklogID != "3" || entries[1].WorklogID != "2" {
+		t.Errorf("Expected worklogs 3 and 2, got %+v", entries)
+	}
+
+	entries, _ = history.List(0)
+	if len(entries) != 3 {
+		t.Errorf("Expected all 3 entries, got %d", len(entries))
+	}
+}
+
+func TestHistory_RecordTrims(t *testing.T) {
+	defer func(max int) { maxHistoryEntries = max }(maxHistoryEntries)
+	maxHistoryEntries = 3
+	history := NewHistory(t.TempDir())
+
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		if err := history.Record(&HistoryEntry{Op: OpAddWorklog, IssueKey: "TEST-1", WorklogID: id}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	entries, err := history.List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 3 || entries[0].WorklogID != "5" || entries[2].WorklogID != "3" {
+		t.Errorf("Expected the 3 newest entries, got %+v", entries)
+	}
+}
+
+func TestTempoo_Undo(t *testing.T) {
+	var deleted []string
+	var created, restored map[string]interface{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /rest/api/3/issue/{key}/worklog/{id}", func(w http.ResponseWriter, r *http.Request) {
+		deleted = append(deleted, r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /rest/api/3/issue/{key}/worklog", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&created)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"id": "200"})
+	})
+	mux.HandleFunc("PUT /rest/api/3/issue/{key}/worklog/{id}", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&restored)
+		json.NewEncoder(w).Encode(map[string]string{"id": r.PathValue("id")})
+	})
+
+	tempoo := newTestTempoo(t, mux)
+	history := NewHistory(t.TempDir())
+
+	// oldest to newest: an add, a delete of another worklog, an edit
+	history.Record(&HistoryEntry{Op: OpAddWorklog, IssueKey: "TEST-1", WorklogID: "100"})
+	history.Record(&HistoryEntry{Op: OpDeleteWorklog, IssueKey: "TEST-1", WorklogID: "101", Worklog: WorklogData{
+		"id": "101", "started": "2025-07-01T08:30:00.000+0000", "timeSpentSeconds": float64(5400), "author": map[string]interface{}{},
+	}})
+	history.Record(&HistoryEntry{Op: OpEditWorklog, IssueKey: "TEST-1", WorklogID: "102", Previous: WorklogData{
+		"started": "2025-07-02T08:30:00.000+0000", "timeSpentSeconds": float64(3600),
+	}})
+
+	undone, err := tempoo.Undo(t.Context(), history, 2)
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if len(undone) != 2 || undone[0].Op != OpEditWorklog || undone[1].Op != OpDeleteWorklog {
+		t.Fatalf("Expected the edit and the delete to be undone, got %+v", undone)
+	}
+
+	if restored["timeSpentSeconds"] != float64(3600) {
+		t.Errorf("Expected the edit to restore one hour, got %+v", restored)
+	}
+	if created["timeSpentSeconds"] != float64(5400) || created["started"] != "2025-07-01T08:30:00.000+0000" {
+		t.Errorf("Expected the deleted worklog to be recreated, got %+v", created)
+	}
+	if _, ok := created["author"]; ok {
+		t.Error("Expected read-only fields to be left out of the recreated worklog")
+	}
+
+	// the next undo reaches the add, skipping entries already undone
+	undone, err = tempoo.Undo(t.Context(), history, 5)
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if len(undone) != 1 || len(deleted) != 1 || deleted[0] != "100" {
+		t.Errorf("Expected only worklog 100 to be deleted, got %v", deleted)
+	}
+
+	// the recreated worklog is tracked under its new ID
+	entries, _ := history.List(0)
+	for _, entry := range entries {
+		if entry.UndoneAt == nil {
+			t.Errorf("Expected entry %s to be marked undone", entry.ID)
+		}
+		if entry.Op == OpDeleteWorklog && entry.WorklogID != "200" {
+			t.Errorf("Expected recreated worklog ID 200, got %s", entry.WorklogID)
+		}
+	}
+}
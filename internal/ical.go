@@ -0,0 +1,455 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// maxOccurrences bounds the expansion of a recurring event, guarding against rules without an end
+const maxOccurrences = 10000
+
+// Event is a calendar event read from an iCalendar file, or one occurrence of a recurring event
+type Event struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+	// AllDay events have dates but no times
+	AllDay bool
+	// Organizer is the email address of the organiser
+	Organizer  string
+	Categories []string
+	// Status is CONFIRMED, TENTATIVE or CANCELLED
+	Status    string
+	Attendees []Attendee
+
+	duration     time.Duration
+	rrule        string
+	exdates      []time.Time
+	recurrenceID time.Time
+}
+
+// Attendee is an invitee of an event with their participation status, such as ACCEPTED or DECLINED
+type Attendee struct {
+	Email    string
+	PartStat string
+}
+
+// icsProperty is a content line of an iCalendar file
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseICS reads the events of an iCalendar file. Recurring events are returned once, as defined;
+// ExpandEvents turns them into occurrences.
+func ParseICS(r io.Reader) ([]Event, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, &TempooError{Message: "Failed to read calendar", Cause: err}
+	}
+
+	var events []Event
+	var event *Event
+	// nested counts the components open inside the current event, such as a VALARM, whose properties are not the event's
+	nested := 0
+	for i, line := range lines {
+		prop, err := parseICSLine(line)
+		if err != nil {
+			return nil, &TempooError{Message: fmt.Sprintf("Invalid calendar line %d", i+1), Cause: err}
+		}
+
+		switch {
+		case prop.name == "BEGIN" && event != nil:
+			nested++
+		case prop.name == "END" && nested > 0:
+			nested--
+		case prop.name == "BEGIN" && prop.value == "VEVENT":
+			event = &Event{}
+		case prop.name == "END" && prop.value == "VEVENT" && event != nil:
+			if event.End.IsZero() {
+				event.End = event.Start.Add(event.duration)
+			}
+			events = append(events, *event)
+			event = nil
+		case event != nil && nested == 0:
+			if err := event.set(prop); err != nil {
+				return nil, &TempooError{Message: fmt.Sprintf("Invalid calendar line %d", i+1), Cause: err}
+			}
+		}
+	}
+	return events, nil
+}
+
+// set applies a property of a VEVENT
+func (e *Event) set(prop icsProperty) error {
+	var err error
+	switch prop.name {
+	case "UID":
+		e.UID = prop.value
+	case "SUMMARY":
+		e.Summary = unescapeICSText(prop.value)
+	case "DTSTART":
+		e.Start, e.AllDay, err = parseICSTime(prop)
+	case "DTEND":
+		e.End, _, err = parseICSTime(prop)
+	case "DURATION":
+		e.duration, err = parseICSDuration(prop.value)
+	case "ORGANIZER":
+		e.Organizer = icsEmail(prop.value)
+	case "CATEGORIES":
+		for _, category := range splitICSList(prop.value) {
+			e.Categories = append(e.Categories, unescapeICSText(category))
+		}
+	case "STATUS":
+		e.Status = strings.ToUpper(prop.value)
+	case "ATTENDEE":
+		e.Attendees = append(e.Attendees, Attendee{Email: icsEmail(prop.value), PartStat: strings.ToUpper(prop.params["PARTSTAT"])})
+	case "RRULE":
+		e.rrule = prop.value
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			var exdate time.Time
+			if exdate, _, err = parseICSTime(icsProperty{params: prop.params, value: value}); err != nil {
+				break
+			}
+			e.exdates = append(e.exdates, exdate)
+		}
+	case "RECURRENCE-ID":
+		e.recurrenceID, _, err = parseICSTime(prop)
+	}
+	return err
+}
+
+// Duration returns how long the event lasts
+func (e Event) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// unfoldICS reads the content lines of an iCalendar file, joining lines folded onto the next ones
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseICSLine splits a content line into its name, parameters and value
+func parseICSLine(line string) (icsProperty, error) {
+	// the value starts at the first colon outside a quoted parameter value
+	quoted, colon := false, -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return icsProperty{}, fmt.Errorf("missing ':' in %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := icsProperty{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		if name, value, ok := strings.Cut(param, "="); ok {
+			prop.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return prop, nil
+}
+
+// parseICSTime parses a DATE or DATE-TIME value, in UTC, in the zone of its TZID parameter, or else in local time.
+// It also reports whether the value is a date without a time.
+func parseICSTime(prop icsProperty) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.value)
+	if prop.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	location := time.Local
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			location = loc
+		} else {
+			log.Debugf("Unknown time zone '%s' in calendar, using local time", tzid)
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, location)
+	return t, false, err
+}
+
+// icsDurationPattern matches an iCalendar duration such as PT1H30M or P1D
+var icsDurationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICSDuration parses an iCalendar duration
+func parseICSDuration(value string) (time.Duration, error) {
+	match := icsDurationPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if n, err := strconv.Atoi(match[i+2]); err == nil {
+			d += time.Duration(n) * unit
+		}
+	}
+	if match[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// icsEmail returns the email address of a CAL-ADDRESS value such as mailto:me@example.com
+func icsEmail(value string) string {
+	email := value
+	if len(value) >= len("mailto:") && strings.EqualFold(value[:len("mailto:")], "mailto:") {
+		email = value[len("mailto:"):]
+	}
+	return strings.ToLower(email)
+}
+
+// splitICSList splits a comma separated value, leaving escaped commas alone
+func splitICSList(value string) []string {
+	var items []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			items = append(items, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, value[start:])
+}
+
+// unescapeICSText resolves the backslash escapes of a TEXT value
+func unescapeICSText(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
+
+// ExpandEvents returns the occurrences of events starting during the range of days, sorted by start.
+// Recurring events are expanded with their RRULE and EXDATEs, and occurrences moved or changed
+// individually are replaced by their own definition.
+func ExpandEvents(events []Event, days DateRange) []Event {
+	// occurrences defined on their own, by event and original start
+	overridden := map[string]bool{}
+	for _, event := range events {
+		if !event.recurrenceID.IsZero() {
+			overridden[occurrenceKey(event.UID, event.recurrenceID)] = true
+		}
+	}
+
+	var occurrences []Event
+	for _, event := range events {
+		if event.rrule == "" || !event.recurrenceID.IsZero() {
+			if days.Contains(event.Start) {
+				occurrences = append(occurrences, event)
+			}
+			continue
+		}
+
+		for _, start := range event.recurrences(days) {
+			if overridden[occurrenceKey(event.UID, start)] {
+				continue
+			}
+			occurrence := event
+			occurrence.Start, occurrence.End = start, start.Add(event.Duration())
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool { return occurrences[i].Start.Before(occurrences[j].Start) })
+	return occurrences
+}
+
+// occurrenceKey identifies an occurrence of a recurring event
+func occurrenceKey(uid string, start time.Time) string {
+	return uid + "|" + start.UTC().Format(time.RFC3339)
+}
+
+// recurrenceRule is the supported subset of an RRULE
+type recurrenceRule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday
+}
+
+// icsWeekdays maps iCalendar weekday names to weekdays
+var icsWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// parseRRULE parses the supported subset of a recurrence rule: DAILY, WEEKLY, MONTHLY and YEARLY
+// frequencies with INTERVAL, COUNT, UNTIL and, for daily and weekly rules, plain BYDAY weekdays
+func parseRRULE(value string) (recurrenceRule, error) {
+	rule := recurrenceRule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		name, val, _ := strings.Cut(part, "=")
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.freq = strings.ToUpper(val)
+		case "INTERVAL":
+			rule.interval, err = strconv.Atoi(val)
+		case "COUNT":
+			rule.count, err = strconv.Atoi(val)
+		case "UNTIL":
+			var allDay bool
+			rule.until, allDay, err = parseICSTime(icsProperty{value: val})
+			if allDay {
+				// a date includes the whole day
+				rule.until = rule.until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				weekday, ok := icsWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return rule, fmt.Errorf("unsupported BYDAY %q", day)
+				}
+				rule.byDay = append(rule.byDay, weekday)
+			}
+		case "WKST":
+		default:
+			return rule, fmt.Errorf("unsupported rule part %s", name)
+		}
+		if err != nil {
+			return rule, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	switch rule.freq {
+	case "DAILY", "WEEKLY":
+	case "MONTHLY", "YEARLY":
+		if len(rule.byDay) > 0 {
+			return rule, fmt.Errorf("unsupported BYDAY in %s rule", rule.freq)
+		}
+	default:
+		return rule, fmt.Errorf("unsupported frequency %q", rule.freq)
+	}
+	if rule.interval < 1 {
+		return rule, fmt.Errorf("invalid interval %d", rule.interval)
+	}
+	return rule, nil
+}
+
+// recurrences returns the starts of a recurring event's occurrences during the range of days.
+// A rule tempoo cannot expand is reported and only its first occurrence is used.
+func (e Event) recurrences(days DateRange) []time.Time {
+	rule, err := parseRRULE(e.rrule)
+	if err != nil {
+		log.Warnf("Only the first occurrence of '%s' is used: %v", e.Summary, err)
+		if days.Contains(e.Start) {
+			return []time.Time{e.Start}
+		}
+		return nil
+	}
+
+	excluded := map[string]bool{}
+	for _, exdate := range e.exdates {
+		excluded[exdate.UTC().Format(time.RFC3339)] = true
+	}
+
+	var starts []time.Time
+	count := 0
+	for period := 0; period < maxOccurrences; period++ {
+		for _, start := range rule.period(e.Start, period) {
+			if start.Before(e.Start) {
+				continue
+			}
+			if (!rule.until.IsZero() && start.After(rule.until)) || (rule.count > 0 && count >= rule.count) || calendarDay(start).After(days.To) {
+				return starts
+			}
+			count++
+			if days.Contains(start) && !excluded[start.UTC().Format(time.RFC3339)] {
+				starts = append(starts, start)
+			}
+		}
+	}
+	return starts
+}
+
+// period returns the candidate starts in the n-th period of a rule from the first start, in order
+func (r recurrenceRule) period(first time.Time, n int) []time.Time {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, first.Hour(), first.Minute(), first.Second(), 0, first.Location())
+	}
+	step := n * r.interval
+
+	switch r.freq {
+	case "DAILY":
+		day := at(first.Year(), first.Month(), first.Day()+step)
+		if len(r.byDay) > 0 && !containsWeekday(r.byDay, day.Weekday()) {
+			return nil
+		}
+		return []time.Time{day}
+	case "WEEKLY":
+		monday := at(first.Year(), first.Month(), first.Day()-daysSinceMonday(first)+7*step)
+		if len(r.byDay) == 0 {
+			return []time.Time{at(monday.Year(), monday.Month(), monday.Day()+daysSinceMonday(first))}
+		}
+		var starts []time.Time
+		for offset := 0; offset < 7; offset++ {
+			day := at(monday.Year(), monday.Month(), monday.Day()+offset)
+			if containsWeekday(r.byDay, day.Weekday()) {
+				starts = append(starts, day)
+			}
+		}
+		return starts
+	case "MONTHLY":
+		day := at(first.Year(), first.Month()+time.Month(step), first.Day())
+		if day.Day() != first.Day() {
+			// months without this day are skipped
+			return nil
+		}
+		return []time.Time{day}
+	default:
+		day := at(first.Year()+step, first.Month(), first.Day())
+		if day.Month() != first.Month() {
+			return nil
+		}
+		return []time.Time{day}
+	}
+}
+
+// containsWeekday reports whether days contains day
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
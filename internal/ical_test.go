@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"SUMMARY:Daily standup\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250630T093000\r\n" +
+	"DURATION:PT15M\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=10\r\n" +
+	"EXDATE;TZID=Europe/Berlin:20250702T093000\r\n" +
+	"ORGANIZER;CN=Lead:mailto:lead@example.com\r\n" +
+	"ATTENDEE;PARTSTAT=ACCEPTED;CN=\"Me: Dev\":mailto:Me@Example.com\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20250703T093000\r\n" +
+	"SUMMARY:Daily standup (moved)\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250703T140000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20250703T143000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:review\r\n" +
+	"SUMMARY:Release review\\, part 1 for a long title folded\r\n" +
+	"  onto the next line\r\n" +
+	"CATEGORIES:Meeting,Release\r\n" +
+	"DTSTART:20250701T120000Z\r\n" +
+	"DTEND:20250701T130000Z\r\n" +
+	"ATTENDEE;PARTSTAT=DECLINED:mailto:me@example.com\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS(t *testing.T) {
+	events, err := ParseICS(strings.NewReader(testCalendar))
+	if err != nil {
+		t.Fatalf("ParseICS failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+
+	standup := events[0]
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	if !standup.Start.Equal(time.Date(2025, time.June, 30, 9, 30, 0, 0, berlin)) || standup.Duration() != 15*time.Minute {
+		t.Errorf("Unexpected standup time %s for %s", standup.Start, standup.Duration())
+	}
+	if standup.Organizer != "lead@example.com" || len(standup.Attendees) != 1 || standup.Attendees[0] != (Attendee{Email: "me@example.com", PartStat: "ACCEPTED"}) {
+		t.Errorf("Unexpected standup people %s %+v", standup.Organizer, standup.Attendees)
+	}
+
+	review := events[2]
+	if review.Summary != "Release review, part 1 for a long title folded onto the next line" {
+		t.Errorf("Unexpected summary %q", review.Summary)
+	}
+	if len(review.Categories) != 2 || review.Categories[1] != "Release" || review.Duration() != time.Hour {
+		t.Errorf("Unexpected review %+v", review)
+	}
+}
+
+func TestParseICS_Alarm(t *testing.T) {
+	calendar := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Planning\r\n" +
+		"DTSTART:20250701T120000Z\r\n" +
+		"DURATION:PT1H\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:EMAIL\r\n" +
+		"SUMMARY:Reminder\r\n" +
+		"DURATION:PT5M\r\n" +
+		"ATTENDEE:mailto:me@example.com\r\n" +
+		"END:VALARM\r\n" +
+		"STATUS:CONFIRMED\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	events, err := ParseICS(strings.NewReader(calendar))
+	if err != nil {
+		t.Fatalf("ParseICS failed: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Summary != "Planning" || event.Duration() != time.Hour || len(event.Attendees) != 0 || event.Status != "CONFIRMED" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+func TestExpandEvents(t *testing.T) {
+	events, err := ParseICS(strings.NewReader(testCalendar))
+	if err != nil {
+		t.Fatalf("ParseICS failed: %v", err)
+	}
+	days := DateRange{From: time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC), To: time.Date(2025, time.July, 6, 0, 0, 0, 0, time.UTC)}
+
+	var got []string
+	for _, event := range ExpandEvents(events, days) {
+		got = append(got, event.Start.Format("02.01 15:04")+" "+event.Summary)
+	}
+
+	// the standup on 02.07 is excluded and the one on 03.07 moved
+	expected := []string{
+		"30.06 09:30 Daily standup",
+		"01.07 09:30 Daily standup",
+		"01.07 12:00 Release review, part 1 for a long title folded onto the next line",
+		"03.07 14:00 Daily standup (moved)",
+		"04.07 09:30 Daily standup",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected occurrences:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestParseRRULE_Unsupported(t *testing.T) {
+	for _, rule := range []string{"FREQ=HOURLY", "FREQ=MONTHLY;BYDAY=1MO", "FREQ=WEEKLY;BYSETPOS=1"} {
+		if _, err := parseRRULE(rule); err == nil {
+			t.Errorf("Expected %s to be unsupported", rule)
+		}
+	}
+}
+
+func TestParseICSDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"PT15M":    15 * time.Minute,
+		"PT1H30M":  90 * time.Minute,
+		"P1D":      24 * time.Hour,
+		"P1W":      7 * 24 * time.Hour,
+		"-PT5M":    -5 * time.Minute,
+		"P1DT2H3S": 26*time.Hour + 3*time.Second,
+	}
+	for value, expected := range tests {
+		if got, err := parseICSDuration(value); err != nil || got != expected {
+			t.Errorf("parseICSDuration(%s) = %s, %v, want %s", value, got, err, expected)
+		}
+	}
+}
+
+func TestEvent_Recurrences(t *testing.T) {
+	days := DateRange{From: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2025, time.July, 31, 0, 0, 0, 0, time.UTC)}
+	start := time.Date(2025, time.June, 27, 10, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		rrule    string
+		expected []string
+	}{
+		{"FREQ=DAILY;INTERVAL=2;UNTIL=20250707", []string{"01.07", "03.07", "05.07", "07.07"}},
+		{"FREQ=WEEKLY;INTERVAL=2", []string{"11.07", "25.07"}},
+		{"FREQ=MONTHLY", []string{"27.07"}},
+		{"FREQ=DAILY;BYDAY=SA;COUNT=3", []string{"05.07", "12.07"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, occurrence := range (Event{Start: start, rrule: tt.rrule}).recurrences(days) {
+			got = append(got, occurrence.Format("02.01"))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("%s: got %v, want %v", tt.rrule, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// RecentAndAssignedJQL finds the open issues assigned to the current user and the ones they viewed recently
+const RecentAndAssignedJQL = "(assignee = currentUser() AND statusCategory != Done) OR issuekey in issueHistory() ORDER BY updated DESC"
+
+// IssueQuery builds a JQL search from the shortcuts of the issues command
+type IssueQuery struct {
+	// Mine limits the search to issues assigned to the current user
+	Mine bool
+	// Sprint limits the search to issues in open sprints
+	Sprint bool
+	// Project limits the search to a project key
+	Project string
+	// Text searches the summary, description and comments
+	Text string
+	// Condition is an additional raw JQL condition
+	Condition string
+}
+
+// JQL returns the query as JQL, newest first. Without any condition it finds recent and assigned issues.
+func (q IssueQuery) JQL() string {
+	var conditions []string
+	if q.Mine {
+		conditions = append(conditions, "assignee = currentUser()")
+	}
+	if q.Sprint {
+		conditions = append(conditions, "sprint in openSprints()")
+	}
+	if q.Project != "" {
+		conditions = append(conditions, fmt.Sprintf("project = %s", jqlString(q.Project)))
+	}
+	if q.Text != "" {
+		conditions = append(conditions, fmt.Sprintf("text ~ %s", jqlString(q.Text)))
+	}
+	if q.Condition != "" {
+		conditions = append(conditions, "("+q.Condition+")")
+	}
+
+	if len(conditions) == 0 {
+		return RecentAndAssignedJQL
+	}
+	return strings.Join(conditions, " AND ") + " ORDER BY updated DESC"
+}
+
+// jqlString quotes a value for use in JQL
+func jqlString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// FilterIssues returns the issues whose key or summary fuzzily matches pattern, keeping their order.
+// A match contains the pattern's letters in order, ignoring case and spaces.
+func FilterIssues(issues Issues, pattern string) Issues {
+	filtered := Issues{}
+	for _, issue := range issues {
+		if fuzzyMatch(pattern, issue.Key+" "+issue.Summary) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
+// fuzzyMatch reports whether the letters of pattern appear in s in order
+func fuzzyMatch(pattern, s string) bool {
+	remaining := []rune(strings.ToLower(s))
+	for _, r := range strings.ToLower(pattern) {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		i := 0
+		for i < len(remaining) && remaining[i] != r {
+			i++
+		}
+		if i == len(remaining) {
+			return false
+		}
+		remaining = remaining[i+1:]
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package internal
+
+import "testing"
+
+func TestIssueQuery_JQL(t *testing.T) {
+	tests := []struct {
+		query    IssueQuery
+		expected string
+	}{
+		{IssueQuery{}, RecentAndAssignedJQL},
+		{IssueQuery{Mine: true}, "assignee = currentUser() ORDER BY updated DESC"},
+		{
+			IssueQuery{Mine: true, Sprint: true, Project: "PROJ", Text: `say "hi"`, Condition: "status = Done OR status = Closed"},
+			`assignee = currentUser() AND sprint in openSprints() AND project = "PROJ" AND text ~ "say \"hi\"" AND (status = Done OR status = Closed) ORDER BY updated DESC`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.query.JQL(); got != tt.expected {
+			t.Errorf("JQL() = %s, want %s", got, tt.expected)
+		}
+	}
+}
+
+func TestFilterIssues(t *testing.T) {
+	issues := Issues{
+		{Key: "PROJ-1", Summary: "Fix the login page"},
+		{Key: "PROJ-2", Summary: "Write release notes"},
+		{Key: "OPS-7", Summary: "Rotate certificates"},
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"", []string{"PROJ-1", "PROJ-2", "OPS-7"}},
+		{"login", []string{"PROJ-1"}},
+		{"ops7", []string{"OPS-7"}},
+		{"RLS NTS", []string{"PROJ-2"}},
+		{"zzz", nil},
+	}
+
+	for _, tt := range tests {
+		var keys []string
+		for _, issue := range FilterIssues(issues, tt.pattern) {
+			keys = append(keys, issue.Key)
+		}
+		if len(keys) != len(tt.expected) {
+			t.Errorf("FilterIssues(%q) = %v, want %v", tt.pattern, keys, tt.expected)
+			continue
+		}
+		for i := range keys {
+			if keys[i] != tt.expected[i] {
+				t.Errorf("FilterIssues(%q) = %v, want %v", tt.pattern, keys, tt.expected)
+			}
+		}
+	}
+}
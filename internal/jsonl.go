@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/apex/log"
+)
+
+// appendJSONLine appends v as one JSON line to path and flushes it to disk.
+// Callers hold the file lock.
+func appendJSONLine(path string, v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return &TempooError{Message: fmt.Sprintf("Failed to encode entry for %s", path), Cause: err}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return &TempooError{Message: fmt.Sprintf("Failed to open %s", path), Cause: err}
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return &TempooError{Message: fmt.Sprintf("Failed to write %s", path), Cause: err}
+	}
+	return f.Sync()
+}
+
+// readJSONLines parses a JSON lines file, returning nothing if it does not exist yet.
+// Callers hold the file lock.
+func readJSONLines[T any](path string) ([]*T, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &TempooError{Message: fmt.Sprintf("Failed to read %s", path), Cause: err}
+	}
+	defer f.Close()
+
+	var items []*T
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		item := new(T)
+		if err := json.Unmarshal(scanner.Bytes(), item); err != nil {
+			// a torn final write from a crash must not block the rest of the file
+			log.Warnf("Skipping unreadable line %d in %s: %v", line, path, err)
+			continue
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &TempooError{Message: fmt.Sprintf("Failed to read %s", path), Cause: err}
+	}
+	return items, nil
+}
+
+// writeJSONLines atomically replaces a JSON lines file with items.
+// Callers hold the file lock.
+func writeJSONLines[T any](path string, items []*T) error {
+	var buf bytes.Buffer
+	for _, item := range items {
+		line, err := json.Marshal(item)
+		if err != nil {
+			return &TempooError{Message: fmt.Sprintf("Failed to encode entry for %s", path), Cause: err}
+		}
+		buf.Write(append(line, '\n'))
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apex/log"
+)
+
+const (
+	// lockTimeout is how long to wait for another tempoo process to release a lock
+	lockTimeout = 30 * time.Second
+	// lockStaleAfter is the age after which a lock left behind by a crashed process is broken
+	lockStaleAfter = 2 * time.Minute
+	// lockRetryInterval is the delay between attempts to acquire a held lock
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+// withFileLock runs fn while holding an exclusive lock on path.
+// The lock is a sibling "<path>.lock" file created with O_EXCL, which works on every platform tempoo ships for.
+// It holds the owner's PID and a unique token, so a process only ever releases its own lock.
+func withFileLock(path string, fn func() error) error {
+	lockPath := path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return &TempooError{Message: "Failed to create tempoo state directory", Cause: err}
+	}
+	owner := fmt.Sprintf("%d %s\n", os.Getpid(), newOperationID())
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, err = f.WriteString(owner)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(lockPath)
+				return &TempooError{Message: fmt.Sprintf("Failed to write lock file %s", lockPath), Cause: err}
+			}
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return &TempooError{Message: fmt.Sprintf("Failed to create lock file %s", lockPath), Cause: err}
+		}
+
+		if breakStaleLock(lockPath) {
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return &TempooError{Message: fmt.Sprintf("Timed out waiting for lock %s", lockPath)}
+		}
+		time.Sleep(lockRetryInterval)
+	}
+	defer releaseLock(lockPath, owner)
+
+	return fn()
+}
+
+// breakStaleLock removes a lock abandoned by a crashed process, reporting whether it did. The lock is first
+// renamed to a unique name, which only one process can do, and handed back if the file renamed turns out
+// to be a newer lock taken since it was found stale.
+func breakStaleLock(lockPath string) bool {
+	info, err := os.Stat(lockPath)
+	if err != nil || time.Since(info.ModTime()) <= lockStaleAfter {
+		return false
+	}
+
+	stalePath := fmt.Sprintf("%s.stale-%s", lockPath, newOperationID())
+	if err := os.Rename(lockPath, stalePath); err != nil {
+		// another process broke or released it first
+		return false
+	}
+	defer os.Remove(stalePath)
+
+	if moved, err := os.Stat(stalePath); err != nil || !os.SameFile(info, moved) {
+		log.Debugf("Lock file %s was taken again, handing it back", lockPath)
+		os.Link(stalePath, lockPath)
+		return false
+	}
+	log.Debugf("Removed stale lock file %s", lockPath)
+	return true
+}
+
+// releaseLock removes the lock file if owner still holds it
+func releaseLock(lockPath, owner string) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil || string(data) != owner {
+		log.Debugf("Lock file %s is no longer ours, leaving it", lockPath)
+		return
+	}
+	os.Remove(lockPath)
+}
+
+// touchLock refreshes the lock on path, so a lock held through a long operation is not taken for stale
+func touchLock(path string) {
+	now := time.Now()
+	if err := os.Chtimes(path+".lock", now, now); err != nil {
+		log.Debugf("Failed to refresh lock file %s.lock: %v", path, err)
+	}
+}
+
+// writeFileAtomic replaces path with data by writing a temporary file and renaming it into place
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tempoo-*")
+	if err != nil {
+		return &TempooError{Message: fmt.Sprintf("Failed to write %s", path), Cause: err}
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return &TempooError{Message: fmt.Sprintf("Failed to write %s", path), Cause: err}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return &TempooError{Message: fmt.Sprintf("Failed to write %s", path), Cause: err}
+	}
+	if err := tmp.Close(); err != nil {
+		return &TempooError{Message: fmt.Sprintf("Failed to write %s", path), Cause: err}
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return &TempooError{Message: fmt.Sprintf("Failed to write %s", path), Cause: err}
+	}
+	return nil
+}
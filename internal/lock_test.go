@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithFileLock_BreaksStaleLock(t *testing.T) {
+	path := t.TempDir() + "/state.json"
+	writeFile(t, path+".lock", "12345 abandoned\n")
+	old := time.Now().Add(-2 * lockStaleAfter)
+	if err := os.Chtimes(path+".lock", old, old); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	ran := false
+	if err := withFileLock(path, func() error { ran = true; return nil }); err != nil || !ran {
+		t.Fatalf("withFileLock() = %v, ran = %t", err, ran)
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("Expected the lock to be released, got %v", err)
+	}
+	if matches, _ := filepath.Glob(path + ".lock.stale-*"); len(matches) != 0 {
+		t.Errorf("Expected the stale lock to be removed, got %v", matches)
+	}
+}
+
+func TestWithFileLock_KeepsOthersLock(t *testing.T) {
+	path := t.TempDir() + "/state.json"
+
+	err := withFileLock(path, func() error {
+		// another process broke this lock and took its own
+		os.Remove(path + ".lock")
+		writeFile(t, path+".lock", "12345 other\n")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withFileLock() error = %v", err)
+	}
+	if data, err := os.ReadFile(path + ".lock"); err != nil || string(data) != "12345 other\n" {
+		t.Errorf("Expected the other process's lock to be left alone, got %q, %v", data, err)
+	}
+}
@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// MemoryService keeps worklogs in memory for a fixed user and set of issues.
+// It backs tests and demo mode, where nothing should reach Jira.
+type MemoryService struct {
+	mu       sync.Mutex
+	user     User
+	issues   Issues
+	worklogs map[string]Worklogs
+	nextID   int
+}
+
+// NewMemoryService creates an empty in-memory service for user, knowing the given issues
+func NewMemoryService(user User, issues ...Issue) *MemoryService {
+	return &MemoryService{
+		user:     user,
+		issues:   issues,
+		worklogs: map[string]Worklogs{},
+		nextID:   10000,
+	}
+}
+
+// NewDemoService creates an in-memory service with sample issues and worklogs to try tempoo without a Jira account
+func NewDemoService() *MemoryService {
+	m := NewMemoryService(
+		User{AccountID: "demo-user", DisplayName: "Demo User", Email: "demo@example.com"},
+		Issue{Key: "DEMO-1", Summary: "Set up the development environment", Status: "Done"},
+		Issue{Key: "DEMO-2", Summary: "Implement the login page", Status: "In Progress"},
+		Issue{Key: "DEMO-3", Summary: "Write the release notes", Status: "To Do"},
+	)
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	start := time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 8, 30, 0, 0, time.UTC)
+	m.add("DEMO-1", start, 2*time.Hour, "")
+	m.add("DEMO-2", start.Add(2*time.Hour), 4*time.Hour, "")
+	return m
+}
+
+// GetUserAccountID returns the account ID of the service's user
+func (m *MemoryService) GetUserAccountID(ctx context.Context) (string, error) {
+	return m.user.AccountID, nil
+}
+
+// WhoAmI returns the service's user
+func (m *MemoryService) WhoAmI(ctx context.Context) (*User, error) {
+	user := m.user
+	return &user, nil
+}
+
+// SearchIssues returns up to max of the known issues. The JQL query is not evaluated.
+func (m *MemoryService) SearchIssues(ctx context.Context, jql string, max int) (Issues, error) {
+	issues := Issues{}
+	for _, issue := range m.issues {
+		if max > 0 && len(issues) >= max {
+			break
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// GetIssue returns a known issue by key
+func (m *MemoryService) GetIssue(ctx context.Context, issueKey string) (*Issue, error) {
+	for _, issue := range m.issues {
+		if issue.Key == issueKey {
+			return &issue, nil
+		}
+	}
+	return nil, &InvalidIssueKeyError{IssueKey: issueKey}
+}
+
+// GetWorklogs returns the IDs of a user's worklogs on an issue
+func (m *MemoryService) GetWorklogs(ctx context.Context, issueKey, userID string) ([]string, error) {
+	worklogs, err := m.ListWorklogs(ctx, issueKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var worklogIDs []string
+	for _, worklog := range worklogs {
+		if worklog.AuthorAccountID == userID {
+			worklogIDs = append(worklogIDs, worklog.ID)
+		}
+	}
+	return worklogIDs, nil
+}
+
+// AddWorklog logs hours to an issue, starting at 08:30 on the given DD.MM.YYYY date or today
+func (m *MemoryService) AddWorklog(ctx context.Context, issueKey, worklogTime string, dateStr *string) (*Worklog, error) {
+	hours, err := validateWorklogHours(worklogTime)
+	if err != nil {
+		return nil, err
+	}
+
+	started, err := worklogStart(dateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.AddWorklogAt(ctx, issueKey, started, time.Duration(hours*float64(time.Hour)))
+}
+
+// AddWorklogAt logs a duration to an issue starting at the given time
+func (m *MemoryService) AddWorklogAt(ctx context.Context, issueKey string, started time.Time, duration time.Duration) (*Worklog, error) {
+	return m.AddWorklogWithComment(ctx, issueKey, started, duration, "")
+}
+
+// AddWorklogWithComment logs a duration to an issue starting at the given time, with a comment
+func (m *MemoryService) AddWorklogWithComment(ctx context.Context, issueKey string, started time.Time, duration time.Duration, comment string) (*Worklog, error) {
+	if err := validateWorklogDuration(duration); err != nil {
+		return nil, err
+	}
+	if err := m.validateIssueKey(ctx, issueKey); err != nil {
+		return nil, err
+	}
+
+	worklog := m.add(issueKey, started, duration, comment)
+	log.Infof("Added worklog of %s to %s", convertHoursToJiraFormat(duration.Hours()), issueKey)
+	return &worklog, nil
+}
+
+// ListWorklogs returns the worklogs on an issue
+func (m *MemoryService) ListWorklogs(ctx context.Context, issueKey string) (Worklogs, error) {
+	if err := m.validateIssueKey(ctx, issueKey); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	worklogs := Worklogs{}
+	worklogs = append(worklogs, m.worklogs[issueKey]...)
+	return worklogs, nil
+}
+
+// UpdateWorklog changes the hours and/or date of a worklog, leaving unset values as they are.
+// A new date keeps the original start time of day.
+func (m *MemoryService) UpdateWorklog(ctx context.Context, issueKey, worklogID, worklogTime string, dateStr *string) (*Worklog, error) {
+	duration, date, err := parseWorklogUpdate(worklogTime, dateStr)
+	if err != nil {
+		return nil, err
+	}
+	return m.UpdateWorklogDuration(ctx, issueKey, worklogID, duration, date)
+}
+
+// UpdateWorklogDuration changes the duration and/or day of a worklog, leaving zero values as they are.
+// A new date keeps the original start time of day.
+func (m *MemoryService) UpdateWorklogDuration(ctx context.Context, issueKey, worklogID string, duration time.Duration, date time.Time) (*Worklog, error) {
+	if duration != 0 {
+		if err := validateWorklogDuration(duration); err != nil {
+			return nil, err
+		}
+	}
+	seconds := int(duration.Seconds())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	worklog := m.find(issueKey, worklogID)
+	if worklog == nil {
+		return nil, &NotFoundError{APIError{TempooError: TempooError{Message: fmt.Sprintf("Worklog %s not found on %s", worklogID, issueKey)}, StatusCode: 404}}
+	}
+
+	if seconds > 0 {
+		worklog.TimeSpentSeconds = seconds
+		worklog.TimeSpent = formatTimeSpent(seconds)
+	}
+	if !date.IsZero() {
+		started := worklog.Started
+		worklog.Started = time.Date(date.Year(), date.Month(), date.Day(),
+			started.Hour(), started.Minute(), started.Second(), started.Nanosecond(), started.Location())
+	}
+
+	log.Infof("Updated worklog %s on %s", worklogID, issueKey)
+	updated := *worklog
+	return &updated, nil
+}
+
+// DeleteWorklog deletes a worklog from an issue
+func (m *MemoryService) DeleteWorklog(ctx context.Context, issueKey, worklogID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	worklogs := m.worklogs[issueKey]
+	for i, worklog := range worklogs {
+		if worklog.ID == worklogID {
+			m.worklogs[issueKey] = append(worklogs[:i:i], worklogs[i+1:]...)
+			log.Infof("Deleted worklog %s for %s", worklogID, issueKey)
+			return nil
+		}
+	}
+	return &NotFoundError{APIError{TempooError: TempooError{Message: fmt.Sprintf("Worklog %s not found on %s", worklogID, issueKey)}, StatusCode: 404}}
+}
+
+// add stores a new worklog by the service's user and returns it
+func (m *MemoryService) add(issueKey string, started time.Time, duration time.Duration, comment string) Worklog {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	seconds := int(duration.Seconds())
+	worklog := Worklog{
+		ID:               strconv.Itoa(m.nextID),
+		IssueKey:         issueKey,
+		AuthorAccountID:  m.user.AccountID,
+		AuthorName:       m.user.DisplayName,
+		Started:          started,
+		TimeSpentSeconds: seconds,
+		TimeSpent:        formatTimeSpent(seconds),
+		Comment:          comment,
+	}
+	m.worklogs[issueKey] = append(m.worklogs[issueKey], worklog)
+	return worklog
+}
+
+// find returns the stored worklog with the given ID, or nil. Callers hold the lock.
+func (m *MemoryService) find(issueKey, worklogID string) *Worklog {
+	for i := range m.worklogs[issueKey] {
+		if m.worklogs[issueKey][i].ID == worklogID {
+			return &m.worklogs[issueKey][i]
+		}
+	}
+	return nil
+}
+
+// validateIssueKey rejects issues the service does not know
+func (m *MemoryService) validateIssueKey(ctx context.Context, issueKey string) error {
+	for _, issue := range m.issues {
+		if issue.Key == issueKey {
+			return nil
+		}
+	}
+	return &InvalidIssueKeyError{IssueKey: issueKey}
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestMemoryService() *MemoryService {
+	return NewMemoryService(User{AccountID: "user-1", DisplayName: "Test User"}, Issue{Key: "TEST-1", Summary: "Test issue", Status: "To Do"})
+}
+
+func TestMemoryService_Worklogs(t *testing.T) {
+	service := newTestMemoryService()
+
+	date := "01.07.2025"
+	added, err := service.AddWorklog(t.Context(), "TEST-1", "1.5", &date)
+	if err != nil {
+		t.Fatalf("AddWorklog() error = %v", err)
+	}
+	if added.TimeSpent != "1h 30m" || added.AuthorName != "Test User" || added.Started.Hour() != 8 {
+		t.Errorf("Unexpected worklog %+v", added)
+	}
+
+	newDate := "03.07.2025"
+	updated, err := service.UpdateWorklog(t.Context(), "TEST-1", added.ID, "2", &newDate)
+	if err != nil {
+		t.Fatalf("UpdateWorklog() error = %v", err)
+	}
+	if updated.TimeSpentSeconds != 7200 || !updated.Started.Equal(time.Date(2025, 7, 3, 8, 30, 0, 751000000, time.UTC)) {
+		t.Errorf("Unexpected updated worklog %+v", updated)
+	}
+
+	if _, err := service.UpdateWorklogDuration(t.Context(), "TEST-1", added.ID, 30*time.Second, time.Time{}); err == nil {
+		t.Error("Expected an error updating to less than a minute")
+	}
+
+	ids, err := service.GetWorklogs(t.Context(), "TEST-1", "user-1")
+	if err != nil || len(ids) != 1 || ids[0] != added.ID {
+		t.Fatalf("GetWorklogs() = %v, %v", ids, err)
+	}
+
+	if err := service.DeleteWorklog(t.Context(), "TEST-1", added.ID); err != nil {
+		t.Fatalf("DeleteWorklog() error = %v", err)
+	}
+	worklogs, _ := service.ListWorklogs(t.Context(), "TEST-1")
+	if len(worklogs) != 0 {
+		t.Errorf("Expected no worklogs after delete, got %+v", worklogs)
+	}
+
+	var notFound *NotFoundError
+	if err := service.DeleteWorklog(t.Context(), "TEST-1", added.ID); !errors.As(err, &notFound) {
+		t.Errorf("Expected NotFoundError deleting twice, got %v", err)
+	}
+}
+
+func TestMemoryService_UnknownIssue(t *testing.T) {
+	service := newTestMemoryService()
+
+	var keyErr *InvalidIssueKeyError
+	if _, err := service.AddWorklog(t.Context(), "NOPE-1", "1", nil); !errors.As(err, &keyErr) {
+		t.Errorf("Expected InvalidIssueKeyError, got %v", err)
+	}
+}
+
+func TestMemoryService_WhoAmIAndSearch(t *testing.T) {
+	service := NewDemoService()
+
+	user, err := service.WhoAmI(t.Context())
+	if err != nil || user.AccountID != "demo-user" {
+		t.Errorf("WhoAmI() = %+v, %v", user, err)
+	}
+
+	issues, err := service.SearchIssues(t.Context(), "assignee = currentUser()", 2)
+	if err != nil || len(issues) != 2 || issues[0].Key != "DEMO-1" {
+		t.Errorf("SearchIssues() = %+v, %v", issues, err)
+	}
+
+	worklogs, _ := service.ListWorklogs(t.Context(), "DEMO-2")
+	if len(worklogs) != 1 {
+		t.Errorf("Expected a sample worklog on DEMO-2, got %+v", worklogs)
+	}
+}
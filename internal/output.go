@@ -0,0 +1,522 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// output formats supported by Printer
+const (
+	OutputText  = "text"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputTable = "table"
+	OutputCSV   = "csv"
+)
+
+// outputTimeFormat is the layout for timestamps in table, CSV and text output
+const outputTimeFormat = "2006-01-02 15:04"
+
+// Result is the output of a command. JSON and YAML output encode the value itself,
+// table and CSV output use its columns and rows.
+type Result interface {
+	Columns() []string
+	Rows() [][]string
+}
+
+// Texter is implemented by results with a human readable text form.
+// Results without one print nothing in text output, where the log lines already describe them.
+type Texter interface {
+	Text() string
+}
+
+// Printer writes command results to stdout in the selected format
+type Printer struct {
+	format string
+	w      io.Writer
+}
+
+// NewPrinter creates a printer writing results to w in format, where an empty format means text
+func NewPrinter(format string, w io.Writer) (*Printer, error) {
+	switch format {
+	case "":
+		format = OutputText
+	case OutputText, OutputJSON, OutputYAML, OutputTable, OutputCSV:
+	default:
+		return nil, &TempooError{Message: fmt.Sprintf("Invalid output format '%s'. Expected text, json, yaml, table or csv", format)}
+	}
+	return &Printer{format: format, w: w}, nil
+}
+
+// Print writes a result in the printer's format
+func (p *Printer) Print(result Result) error {
+	switch p.format {
+	case OutputJSON:
+		encoder := json.NewEncoder(p.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+
+	case OutputYAML:
+		// go through JSON so both formats share one schema, decoding into a node to keep the field order
+		data, err := json.Marshal(result)
+		if err != nil {
+			return &TempooError{Message: "Failed to encode output", Cause: err}
+		}
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return &TempooError{Message: "Failed to encode output", Cause: err}
+		}
+		clearStyle(&node)
+		encoder := yaml.NewEncoder(p.w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&node); err != nil {
+			return &TempooError{Message: "Failed to encode output", Cause: err}
+		}
+		return encoder.Close()
+
+	case OutputTable:
+		tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+		header := make([]string, len(result.Columns()))
+		for i, column := range result.Columns() {
+			header[i] = strings.ToUpper(column)
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, row := range result.Rows() {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+
+	case OutputCSV:
+		cw := csv.NewWriter(p.w)
+		cw.Write(result.Columns())
+		cw.WriteAll(result.Rows())
+		return cw.Error()
+
+	default:
+		if texter, ok := result.(Texter); ok {
+			if text := texter.Text(); text != "" {
+				_, err := fmt.Fprintln(p.w, text)
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Columns implements Result
+func (w Worklog) Columns() []string {
+	return []string{"id", "issue_key", "started", "time_spent_seconds", "time_spent", "author"}
+}
+
+// Rows implements Result
+func (w Worklog) Rows() [][]string {
+	return [][]string{w.row()}
+}
+
+func (w Worklog) row() []string {
+	started := ""
+	if !w.Started.IsZero() {
+		started = w.Started.Format(outputTimeFormat)
+	}
+	return []string{w.ID, w.IssueKey, started, strconv.Itoa(w.TimeSpentSeconds), w.TimeSpent, w.AuthorName}
+}
+
+// Columns implements Result
+func (w Worklogs) Columns() []string {
+	return Worklog{}.Columns()
+}
+
+// Rows implements Result
+func (w Worklogs) Rows() [][]string {
+	rows := [][]string{}
+	for _, worklog := range w {
+		rows = append(rows, worklog.row())
+	}
+	return rows
+}
+
+// Text implements Texter
+func (w Worklogs) Text() string {
+	var lines []string
+	for i, worklog := range w {
+		timeDisplay := worklog.TimeSpent
+		if timeDisplay == "" {
+			timeDisplay = "Unknown"
+		}
+		dateStr := "Unknown"
+		if !worklog.Started.IsZero() {
+			dateStr = worklog.Started.Format("02.01.2006")
+		}
+		authorName := worklog.AuthorName
+		if authorName == "" {
+			authorName = "Unknown"
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s - %s (by %s) [ID: %s]", i+1, timeDisplay, dateStr, authorName, worklog.ID))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Columns implements Result
+func (s TimerStatus) Columns() []string {
+	return []string{"issue_key", "state", "started_at", "elapsed_seconds"}
+}
+
+// Rows implements Result
+func (s TimerStatus) Rows() [][]string {
+	return [][]string{s.row()}
+}
+
+func (s TimerStatus) row() []string {
+	return []string{s.IssueKey, s.State, s.StartedAt.Format(outputTimeFormat), strconv.Itoa(s.ElapsedSeconds)}
+}
+
+// Columns implements Result
+func (s TimerStatuses) Columns() []string {
+	return TimerStatus{}.Columns()
+}
+
+// Rows implements Result
+func (s TimerStatuses) Rows() [][]string {
+	rows := [][]string{}
+	for _, status := range s {
+		rows = append(rows, status.row())
+	}
+	return rows
+}
+
+// Text implements Texter
+func (s TimerStatuses) Text() string {
+	var lines []string
+	for _, status := range s {
+		elapsed := time.Duration(status.ElapsedSeconds) * time.Second
+		lines = append(lines, fmt.Sprintf("%s - %s %s (started %s)", status.IssueKey, elapsed, status.State, status.StartedAt.Format("02.01.2006 15:04")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Columns implements Result
+func (o QueuedOperations) Columns() []string {
+	return []string{"id", "op", "issue_key", "worklog_id", "started", "time_spent_seconds", "queued_at", "attempts", "conflict", "last_error"}
+}
+
+// Rows implements Result
+func (o QueuedOperations) Rows() [][]string {
+	rows := [][]string{}
+	for _, op := range o {
+		started := ""
+		if !op.Started.IsZero() {
+			started = op.Started.Format(outputTimeFormat)
+		}
+		rows = append(rows, []string{
+			op.ID, op.Op, op.IssueKey, op.WorklogID, started, strconv.Itoa(op.TimeSpentSeconds),
+			op.QueuedAt.Format(outputTimeFormat), strconv.Itoa(op.Attempts), strconv.FormatBool(op.Conflict), op.LastError,
+		})
+	}
+	return rows
+}
+
+// Text implements Texter
+func (o QueuedOperations) Text() string {
+	var lines []string
+	for _, op := range o {
+		detail := "worklog " + op.WorklogID
+		if op.Op == OpAddWorklog {
+			detail = formatTimeSpent(op.TimeSpentSeconds) + " on " + op.Started.Format("02.01.2006")
+		}
+
+		state := "pending"
+		if op.Conflict {
+			state = "conflict: " + op.LastError
+		} else if op.LastError != "" {
+			state = "failed: " + op.LastError
+		}
+
+		lines = append(lines, fmt.Sprintf("[%s] %s %s %s (queued %s, %s)", op.ID, op.Op, op.IssueKey, detail, op.QueuedAt.Format("02.01.2006 15:04"), state))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Columns implements Result
+func (r SyncResult) Columns() []string {
+	return []string{"applied", "duplicates", "conflicts", "pending"}
+}
+
+// Rows implements Result
+func (r SyncResult) Rows() [][]string {
+	return [][]string{{strconv.Itoa(r.Applied), strconv.Itoa(r.Duplicates), strconv.Itoa(r.Conflicts), strconv.Itoa(r.Pending)}}
+}
+
+// Columns implements Result
+func (r *DeleteResult) Columns() []string {
+	return []string{"issue_key", "worklog_id", "status", "error"}
+}
+
+// Rows implements Result
+func (r *DeleteResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, id := range r.Deleted {
+		rows = append(rows, []string{r.IssueKey, id, "deleted", ""})
+	}
+	for _, id := range r.Queued {
+		rows = append(rows, []string{r.IssueKey, id, "queued", ""})
+	}
+	for _, failed := range r.Failed {
+		rows = append(rows, []string{r.IssueKey, failed.WorklogID, "failed", failed.Error})
+	}
+	return rows
+}
+
+// Text implements Texter, listing the deletions that were queued or failed
+func (r *DeleteResult) Text() string {
+	var lines []string
+	for _, id := range r.Queued {
+		lines = append(lines, fmt.Sprintf("Queued deletion of worklog %s until Jira is reachable", id))
+	}
+	for _, failed := range r.Failed {
+		lines = append(lines, fmt.Sprintf("Failed to delete worklog %s: %s", failed.WorklogID, failed.Error))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Columns implements Result
+func (d *Draft) Columns() []string {
+	return []string{"date", "start", "issue_key", "hours", "comment", "note"}
+}
+
+// Rows implements Result
+func (d *Draft) Rows() [][]string {
+	rows := [][]string{}
+	for _, worklog := range d.Worklogs {
+		rows = append(rows, []string{worklog.Date, worklog.Start, worklog.IssueKey, strconv.FormatFloat(worklog.Hours, 'f', -1, 64), worklog.Comment, worklog.Note})
+	}
+	return rows
+}
+
+// Text implements Texter
+func (d *Draft) Text() string {
+	var lines []string
+	for _, worklog := range d.Worklogs {
+		line := worklog.Date
+		if worklog.Start != "" {
+			line += " " + worklog.Start
+		}
+		line += fmt.Sprintf(" %s %s", worklog.IssueKey, convertHoursToJiraFormat(worklog.Hours))
+		if worklog.Comment != "" {
+			line += fmt.Sprintf(" %q", worklog.Comment)
+		}
+		if worklog.Note != "" {
+			line += " - " + worklog.Note
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Columns implements Result
+func (r *ImportResult) Columns() []string {
+	return []string{"date", "issue_key", "worklog_id", "time_spent", "status", "error"}
+}
+
+// Rows implements Result
+func (r *ImportResult) Rows() [][]string {
+	rows := [][]string{}
+	for _, worklog := range r.Added {
+		rows = append(rows, []string{worklog.Started.Format(draftDateFormat), worklog.IssueKey, worklog.ID, worklog.TimeSpent, "added", ""})
+	}
+	for _, failed := range r.Failed {
+		rows = append(rows, []string{failed.Date, failed.IssueKey, "", convertHoursToJiraFormat(failed.Hours), "failed", failed.Error})
+	}
+	return rows
+}
+
+// Text implements Texter, listing the worklogs that failed
+func (r *ImportResult) Text() string {
+	var lines []string
+	for _, failed := range r.Failed {
+		lines = append(lines, fmt.Sprintf("Failed to log %s to %s on %s: %s", convertHoursToJiraFormat(failed.Hours), failed.IssueKey, failed.Date, failed.Error))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Columns implements Result
+func (h HistoryEntries) Columns() []string {
+	return []string{"id", "at", "op", "issue_key", "worklog_id", "undone"}
+}
+
+// Rows implements Result
+func (h HistoryEntries) Rows() [][]string {
+	rows := [][]string{}
+	for _, entry := range h {
+		rows = append(rows, []string{entry.ID, entry.At.Format(outputTimeFormat), entry.Op, entry.IssueKey, entry.WorklogID, strconv.FormatBool(entry.UndoneAt != nil)})
+	}
+	return rows
+}
+
+// Text implements Texter
+func (h HistoryEntries) Text() string {
+	var lines []string
+	for _, entry := range h {
+		state := ""
+		if entry.UndoneAt != nil {
+			state = " (undone)"
+		}
+		lines = append(lines, fmt.Sprintf("[%s] %s %s worklog %s on %s%s", entry.ID, entry.At.Format("02.01.2006 15:04"), entry.Op, entry.WorklogID, entry.IssueKey, state))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Columns implements Result
+func (t *Timesheet) Columns() []string {
+	return []string{"from", "to", "status", "required_seconds", "logged_seconds", "reviewer"}
+}
+
+// Rows implements Result
+func (t *Timesheet) Rows() [][]string {
+	return [][]string{{t.From, t.To, t.Status, strconv.Itoa(t.RequiredSeconds), strconv.Itoa(t.LoggedSeconds), t.Reviewer}}
+}
+
+// Text implements Texter
+func (t *Timesheet) Text() string {
+	reviewer := t.Reviewer
+	if reviewer == "" {
+		reviewer = "none assigned"
+	}
+
+	lines := []string{
+		fmt.Sprintf("Timesheet %s to %s: %s", t.From, t.To, strings.ReplaceAll(t.Status, "_", " ")),
+		fmt.Sprintf("Logged %s of %s required", formatTimeSpent(t.LoggedSeconds), formatTimeSpent(t.RequiredSeconds)),
+		fmt.Sprintf("Reviewer: %s", reviewer),
+	}
+	if missing := t.MissingSeconds(); missing > 0 {
+		lines[1] += fmt.Sprintf(" (%s missing)", formatTimeSpent(missing))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Columns implements Result
+func (u *User) Columns() []string {
+	return []string{"account_id", "display_name", "email", "time_zone"}
+}
+
+// Rows implements Result
+func (u *User) Rows() [][]string {
+	return [][]string{{u.AccountID, u.DisplayName, u.Email, u.TimeZone}}
+}
+
+// Text implements Texter
+func (u *User) Text() string {
+	if u.Email == "" {
+		return fmt.Sprintf("%s [ID: %s]", u.DisplayName, u.AccountID)
+	}
+	return fmt.Sprintf("%s <%s> [ID: %s]", u.DisplayName, u.Email, u.AccountID)
+}
+
+// Columns implements Result
+func (i Issues) Columns() []string {
+	return []string{"key", "summary", "status"}
+}
+
+// Rows implements Result
+func (i Issues) Rows() [][]string {
+	rows := [][]string{}
+	for _, issue := range i {
+		rows = append(rows, []string{issue.Key, issue.Summary, issue.Status})
+	}
+	return rows
+}
+
+// Text implements Texter
+func (i Issues) Text() string {
+	var lines []string
+	for _, issue := range i {
+		lines = append(lines, fmt.Sprintf("%s - %s (%s)", issue.Key, issue.Summary, issue.Status))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Columns implements Result
+func (a Alias) Columns() []string {
+	return []string{"name", "issue_key", "summary"}
+}
+
+// Rows implements Result
+func (a Alias) Rows() [][]string {
+	return [][]string{{a.Name, a.IssueKey, a.Summary}}
+}
+
+// Text implements Texter
+func (a Alias) Text() string {
+	if a.Summary == "" {
+		return fmt.Sprintf("%s = %s", a.Name, a.IssueKey)
+	}
+	return fmt.Sprintf("%s = %s (%s)", a.Name, a.IssueKey, a.Summary)
+}
+
+// Columns implements Result
+func (a Aliases) Columns() []string {
+	return Alias{}.Columns()
+}
+
+// Rows implements Result
+func (a Aliases) Rows() [][]string {
+	rows := [][]string{}
+	for _, alias := range a {
+		rows = append(rows, alias.Rows()...)
+	}
+	return rows
+}
+
+// Text implements Texter
+func (a Aliases) Text() string {
+	var lines []string
+	for _, alias := range a {
+		lines = append(lines, alias.Text())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// clearStyle resets the JSON flow style and quoting on a decoded node tree so it encodes as block YAML
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// Columns implements Result
+func (c GridChanges) Columns() []string {
+	return []string{"op", "issue_key", "date", "hours", "worklog_id", "status", "error"}
+}
+
+// Rows implements Result
+func (c GridChanges) Rows() [][]string {
+	rows := [][]string{}
+	for _, change := range c {
+		rows = append(rows, []string{change.Op, change.IssueKey, change.Date, strconv.FormatFloat(change.Hours, 'f', -1, 64), change.WorklogID, change.Status, change.Error})
+	}
+	return rows
+}
+
+// Text implements Texter
+func (c GridChanges) Text() string {
+	var lines []string
+	for _, change := range c {
+		line := fmt.Sprintf("%-6s %s on %s, %s", change.Op, change.IssueKey, change.Date, convertHoursToJiraFormat(change.Hours))
+		if change.Comment != "" {
+			line += fmt.Sprintf(", losing comment %q", change.Comment)
+		}
+		if change.Error != "" {
+			line += " - failed: " + change.Error
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
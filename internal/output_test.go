@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testWorklogs = Worklogs{
+	{
+		ID:               "10001",
+		IssueKey:         "TEST-1",
+		AuthorAccountID:  "user-1",
+		AuthorName:       "Test User",
+		Started:          time.Date(2025, 7, 1, 8, 30, 0, 0, time.UTC),
+		TimeSpentSeconds: 5400,
+		TimeSpent:        "1h 30m",
+	},
+}
+
+func TestNewPrinter_InvalidFormat(t *testing.T) {
+	if _, err := NewPrinter("xml", &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestPrinter_Print(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected []string
+	}{
+		{
+			format:   OutputText,
+			expected: []string{"1. 1h 30m - 01.07.2025 (by Test User) [ID: 10001]"},
+		},
+		{
+			format:   "",
+			expected: []string{"1. 1h 30m - 01.07.2025 (by Test User) [ID: 10001]"},
+		},
+		{
+			format:   OutputTable,
+			expected: []string{"ID     ISSUE_KEY", "10001  TEST-1"},
+		},
+		{
+			format:   OutputCSV,
+			expected: []string{"id,issue_key,started,time_spent_seconds,time_spent,author\n10001,TEST-1,2025-07-01 08:30,5400,1h 30m,Test User\n"},
+		},
+		{
+			format:   OutputYAML,
+			expected: []string{"- id: \"10001\"\n  issue_key: TEST-1\n", "time_spent_seconds: 5400"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			printer, err := NewPrinter(tt.format, &buf)
+			if err != nil {
+				t.Fatalf("NewPrinter() error = %v", err)
+			}
+			if err := printer.Print(testWorklogs); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(buf.String(), expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPrinter_PrintJSON(t *testing.T) {
+	var buf bytes.Buffer
+	printer, _ := NewPrinter(OutputJSON, &buf)
+	if err := printer.Print(testWorklogs); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, buf.String())
+	}
+	if len(decoded) != 1 || decoded[0]["id"] != "10001" || decoded[0]["time_spent_seconds"] != float64(5400) {
+		t.Errorf("Unexpected JSON output: %s", buf.String())
+	}
+}
+
+func TestPrinter_EmptyListIsArray(t *testing.T) {
+	var buf bytes.Buffer
+	printer, _ := NewPrinter(OutputJSON, &buf)
+	printer.Print(Worklogs{})
+
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("Expected empty JSON array, got %q", buf.String())
+	}
+}
+
+func TestPrinter_TextWithoutTexterPrintsNothing(t *testing.T) {
+	var buf bytes.Buffer
+	printer, _ := NewPrinter(OutputText, &buf)
+	printer.Print(SyncResult{Applied: 1})
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no text output, got %q", buf.String())
+	}
+}
+
+func TestNewWorklog(t *testing.T) {
+	worklog := newWorklog("TEST-1", WorklogData{
+		"id":               "10001",
+		"started":          "2025-07-01T08:30:00.000+0000",
+		"timeSpentSeconds": float64(5400),
+		"author":           map[string]interface{}{"accountId": "user-1", "displayName": "Test User"},
+	})
+
+	// Jira's +0000 offset parses as a fixed zone rather than UTC
+	worklog.Started = worklog.Started.UTC()
+
+	if worklog != testWorklogs[0] {
+		t.Errorf("newWorklog() = %+v, want %+v", worklog, testWorklogs[0])
+	}
+}
@@ -1,12 +1,17 @@
 package internal
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/apex/log"
+	"github.com/go-resty/resty/v2"
 )
 
 // parseDateString parses date string in DD.MM.YYYY format
@@ -35,89 +40,186 @@ func parseDateString(dateStr string) (time.Time, error) {
 	return date, nil
 }
 
-func (t *Tempoo) validateIssueKey(issueKey string) error {
-	issueURL := fmt.Sprintf("%s/issue/%s", JiraAPIRootURL, issueKey)
-	log.Debugf("Validating issue key: %s", issueURL)
+// worklogStart returns the start of a worklog added for a DD.MM.YYYY date, or today if none is given
+func worklogStart(dateStr *string) (time.Time, error) {
+	// Use current date if none provided
+	var workDate time.Time
+	if dateStr == nil || *dateStr == "" {
+		workDate = time.Now()
+	} else {
+		parsedDate, err := parseDateString(*dateStr)
+		if err != nil {
+			return time.Time{}, err
+		}
+		workDate = parsedDate
+	}
+
+	// create timestamp for 08:30 AM on the specified date
+	return time.Date(
+		workDate.Year(), workDate.Month(), workDate.Day(),
+		8, 30, 0, 751000000, // 08:30:00.751
+		time.UTC,
+	), nil
+}
 
-	resp, err := t.client.R().Get(issueURL)
+// validateWorklogDuration rejects durations too short to log
+func validateWorklogDuration(duration time.Duration) error {
+	if duration < time.Minute {
+		return &TempooError{Message: fmt.Sprintf("Worklog duration must be at least 1 minute, got %s", duration)}
+	}
+	return nil
+}
+
+func (t *Tempoo) validateIssueKey(ctx context.Context, issueKey string) error {
+	if t.cache != nil {
+		if _, ok := t.cache.Issue(t.cacheScope(), issueKey); ok {
+			t.log().Debugf("Issue key %s is cached as valid", issueKey)
+			return nil
+		}
+	}
+
+	issueURL := fmt.Sprintf("%s/issue/%s", t.apiRoot(), issueKey)
+	t.log().Debugf("Validating issue key: %s", issueURL)
+
+	// only the summary is needed, to cache it alongside the key
+	resp, err := t.request(ctx).SetQueryParam("fields", "summary").Get(issueURL)
 	if err != nil {
-		log.Errorf("Request failed: %v", err)
+		t.log().Errorf("Request failed: %v", err)
 		return &TempooError{Message: "API request failed", Cause: err}
 	}
 
-	if resp.StatusCode() != 200 {
+	if resp.StatusCode() == 404 {
 		return &InvalidIssueKeyError{IssueKey: issueKey}
 	}
-	log.Debugf("Validated issue key: %s", issueKey)
+	if resp.StatusCode() != 200 {
+		return newAPIError(fmt.Sprintf("Failed to validate issue key %s", issueKey), resp)
+	}
+	t.log().Debugf("Validated issue key: %s", issueKey)
 
+	if t.cache != nil {
+		var issue struct {
+			Fields struct {
+				Summary string `json:"summary"`
+			} `json:"fields"`
+		}
+		json.Unmarshal(resp.Body(), &issue)
+		t.cache.SetIssue(t.cacheScope(), issueKey, issue.Fields.Summary)
+	}
 	return nil
 }
 
-func (t *Tempoo) printWorklogs(worklogs []interface{}) {
+// newWorklog converts a worklog returned by the Jira API into the shape tempoo outputs
+func newWorklog(issueKey string, worklog WorklogData) Worklog {
+	result := Worklog{ID: worklogID(worklog), IssueKey: issueKey}
+
+	// Use timeSpentSeconds for accurate time calculation
+	if timeSpentSeconds, ok := worklog["timeSpentSeconds"].(float64); ok && timeSpentSeconds > 0 {
+		result.TimeSpentSeconds = int(timeSpentSeconds)
+		result.TimeSpent = formatTimeSpent(result.TimeSpentSeconds)
+	} else if timeSpent, ok := worklog["timeSpent"].(string); ok {
+		// Fallback to timeSpent string
+		result.TimeSpent = timeSpent
+	}
+
+	if startedStr, ok := worklog["started"].(string); ok {
+		if started, err := time.Parse(jiraTimestampFormat, startedStr); err == nil {
+			result.Started = started
+		}
+	}
+
+	if author, ok := worklog["author"].(map[string]interface{}); ok {
+		result.AuthorAccountID, _ = author["accountId"].(string)
+		result.AuthorName, _ = author["displayName"].(string)
+	}
+
+	result.Comment = adfText(worklog["comment"])
 
-	log.Infof("Total worklogs found: %d", len(worklogs))
+	return result
+}
 
-	for i, worklogInterface := range worklogs {
-		worklog, ok := worklogInterface.(map[string]interface{})
-		if !ok {
-			continue
+// adfDocument wraps plain text in an Atlassian Document Format document, one paragraph per line
+func adfDocument(text string) map[string]interface{} {
+	paragraphs := []interface{}{}
+	for _, line := range strings.Split(text, "\n") {
+		paragraph := map[string]interface{}{"type": "paragraph", "content": []interface{}{}}
+		if line != "" {
+			paragraph["content"] = []interface{}{map[string]interface{}{"type": "text", "text": line}}
 		}
+		paragraphs = append(paragraphs, paragraph)
+	}
+	return map[string]interface{}{"type": "doc", "version": 1, "content": paragraphs}
+}
 
-		// Use timeSpentSeconds for accurate time calculation
-		timeSpentSeconds, ok := worklog["timeSpentSeconds"].(float64)
-		var timeDisplay string
-		if ok && timeSpentSeconds > 0 {
-			hours := timeSpentSeconds / 3600
-			if hours == float64(int(hours)) {
-				// Whole hours
-				timeDisplay = fmt.Sprintf("%.0fh", hours)
-			} else {
-				// Hours with minutes
-				wholeHours := int(hours)
-				minutes := int((hours - float64(wholeHours)) * 60)
-				timeDisplay = fmt.Sprintf("%dh %dm", wholeHours, minutes)
+// adfText returns the plain text of an Atlassian Document Format document, one line per paragraph
+func adfText(node interface{}) string {
+	doc, ok := node.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	var lines []string
+	for _, block := range adfContent(doc) {
+		var line strings.Builder
+		var collect func(n map[string]interface{})
+		collect = func(n map[string]interface{}) {
+			if text, ok := n["text"].(string); ok {
+				line.WriteString(text)
 			}
-		} else {
-			// Fallback to timeSpent string
-			timeSpent, ok := worklog["timeSpent"].(string)
-			if ok {
-				timeDisplay = timeSpent
-			} else {
-				timeDisplay = "Unknown"
+			for _, child := range adfContent(n) {
+				collect(child)
 			}
 		}
+		collect(block)
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
 
-		// Extract other fields...
-		worklogID := "Unknown"
-		if id, ok := worklog["id"].(string); ok {
-			worklogID = id
-		} else if id, ok := worklog["id"].(float64); ok {
-			worklogID = fmt.Sprintf("%.0f", id)
+// adfContent returns the child nodes of an Atlassian Document Format node
+func adfContent(node map[string]interface{}) []map[string]interface{} {
+	items, _ := node["content"].([]interface{})
+	var children []map[string]interface{}
+	for _, item := range items {
+		if child, ok := item.(map[string]interface{}); ok {
+			children = append(children, child)
 		}
+	}
+	return children
+}
 
-		startedStr, ok := worklog["started"].(string)
-		var dateStr string
-		if ok {
-			if startedTime, err := time.Parse("2006-01-02T15:04:05.000-0700", startedStr); err == nil {
-				dateStr = startedTime.Format("02.01.2006")
-			} else {
-				dateStr = "Unknown"
-			}
-		} else {
-			dateStr = "Unknown"
-		}
+// formatTimeSpent formats seconds as whole hours ("2h") or hours and minutes ("1h 30m")
+func formatTimeSpent(seconds int) string {
+	hours := seconds / 3600
+	minutes := (seconds % 3600) / 60
+	if minutes == 0 {
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
 
-		authorName := "Unknown"
-		if author, ok := worklog["author"].(map[string]interface{}); ok {
-			if displayName, ok := author["displayName"].(string); ok {
-				authorName = displayName
-			}
+// parseWorklogUpdate validates the hours and DD.MM.YYYY date of a worklog edit, returning zero values for the unset ones
+func parseWorklogUpdate(hoursStr string, dateStr *string) (time.Duration, time.Time, error) {
+	var duration time.Duration
+	if hoursStr != "" {
+		hours, err := validateWorklogHours(hoursStr)
+		if err != nil {
+			return 0, time.Time{}, err
 		}
+		duration = time.Duration(hours * float64(time.Hour))
+	}
 
-		log.Infof("  %d. %s - %s (by %s) [ID: %s]", i+1, timeDisplay, dateStr, authorName, worklogID)
+	var date time.Time
+	if dateStr != nil && *dateStr != "" {
+		var err error
+		if date, err = parseDateString(*dateStr); err != nil {
+			return 0, time.Time{}, err
+		}
 	}
+	return duration, date, nil
 }
 
+// maxWorklogHours is the most a single worklog logged with add-worklog or from a draft may take
+const maxWorklogHours = 8
+
 // validateWorklogHours validates that the hours input is in the correct format
 // Accepts whole numbers or .5 increments between 0.5 and 8 hours
 func validateWorklogHours(hoursStr string) (float64, error) {
@@ -133,8 +235,8 @@ func validateWorklogHours(hoursStr string) (float64, error) {
 	}
 
 	// Check maximum value
-	if hours > 8 {
-		return 0, &TempooError{Message: fmt.Sprintf("Hours cannot exceed 8, got %.1f", hours)}
+	if hours > maxWorklogHours {
+		return 0, &TempooError{Message: fmt.Sprintf("Hours cannot exceed %d, got %.1f", maxWorklogHours, hours)}
 	}
 
 	// Check that it's either a whole number or .5 increment
@@ -158,3 +260,107 @@ func convertHoursToJiraFormat(hours float64) string {
 		return fmt.Sprintf("%dh %dm", wholeHours, minutes)
 	}
 }
+
+// sendWorklog posts a worklog to an issue and returns the raw response
+func (t *Tempoo) sendWorklog(ctx context.Context, issueKey string, started time.Time, timeSpentSeconds int, comment string) (*resty.Response, error) {
+	payload := map[string]interface{}{
+		"timeSpentSeconds": timeSpentSeconds,
+		"started":          started.Format(jiraTimestampFormat),
+	}
+	if comment != "" {
+		payload["comment"] = adfDocument(comment)
+	}
+	return t.sendWorklogPayload(ctx, issueKey, payload)
+}
+
+// sendWorklogPayload posts a raw worklog payload to an issue and returns the raw response
+func (t *Tempoo) sendWorklogPayload(ctx context.Context, issueKey string, payload map[string]interface{}) (*resty.Response, error) {
+	return t.request(ctx).
+		SetBody(payload).
+		Post(fmt.Sprintf("%s/issue/%s/worklog", t.apiRoot(), issueKey))
+}
+
+// sendWorklogUpdate replaces fields of an existing worklog and returns the raw response
+func (t *Tempoo) sendWorklogUpdate(ctx context.Context, issueKey, worklogID string, payload map[string]interface{}) (*resty.Response, error) {
+	return t.request(ctx).
+		SetBody(payload).
+		Put(fmt.Sprintf("%s/issue/%s/worklog/%s", t.apiRoot(), issueKey, worklogID))
+}
+
+// getWorklog fetches a single worklog, returning the response so callers can inspect the status
+func (t *Tempoo) getWorklog(ctx context.Context, issueKey, worklogID string) (*resty.Response, WorklogData, error) {
+	resp, err := t.request(ctx).Get(fmt.Sprintf("%s/issue/%s/worklog/%s", t.apiRoot(), issueKey, worklogID))
+	if err != nil || resp.StatusCode() != 200 {
+		return resp, nil, err
+	}
+
+	var worklog WorklogData
+	if err := json.Unmarshal(resp.Body(), &worklog); err != nil {
+		return resp, nil, &TempooError{Message: "Failed to parse worklog data", Cause: err}
+	}
+	return resp, worklog, nil
+}
+
+// recordCreated journals a worklog Jira just created, taken from the creation response body, and returns it
+func (t *Tempoo) recordCreated(issueKey string, resp *resty.Response) *Worklog {
+	var created WorklogData
+	if err := json.Unmarshal(resp.Body(), &created); err != nil {
+		t.log().Warnf("Failed to parse added worklog: %v", err)
+		return &Worklog{IssueKey: issueKey}
+	}
+	t.record(&HistoryEntry{Op: OpAddWorklog, IssueKey: issueKey, WorklogID: worklogID(created), Worklog: created})
+
+	worklog := newWorklog(issueKey, created)
+	return &worklog
+}
+
+// sendWorklogDeletion deletes a worklog from an issue and returns the raw response
+func (t *Tempoo) sendWorklogDeletion(ctx context.Context, issueKey, worklogID string) (*resty.Response, error) {
+	return t.request(ctx).Delete(fmt.Sprintf("%s/issue/%s/worklog/%s", t.apiRoot(), issueKey, worklogID))
+}
+
+// getIssueWorklogs fetches the raw worklogs of an issue, following Jira's pagination, and returns the
+// last response so callers can inspect the status
+func (t *Tempoo) getIssueWorklogs(ctx context.Context, issueKey string) (*resty.Response, []map[string]interface{}, error) {
+	var worklogs []map[string]interface{}
+	for {
+		resp, err := t.request(ctx).
+			SetQueryParam("startAt", strconv.Itoa(len(worklogs))).
+			Get(fmt.Sprintf("%s/issue/%s/worklog", t.apiRoot(), issueKey))
+		if err != nil || resp.StatusCode() != 200 {
+			return resp, nil, err
+		}
+
+		var page struct {
+			Total    int                      `json:"total"`
+			Worklogs []map[string]interface{} `json:"worklogs"`
+		}
+		if err := json.Unmarshal(resp.Body(), &page); err != nil {
+			return resp, nil, &TempooError{Message: "Failed to parse worklog data", Cause: err}
+		}
+		worklogs = append(worklogs, page.Worklogs...)
+
+		if len(page.Worklogs) == 0 || len(worklogs) >= page.Total {
+			return resp, worklogs, nil
+		}
+		t.log().Debugf("Fetched %d of %d worklogs for %s", len(worklogs), page.Total, issueKey)
+	}
+}
+
+// isTransportError reports whether err is a transport failure, meaning the request never got a response from Jira.
+// The request may still have reached Jira, for example when the response timed out.
+func isTransportError(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// isUnreachable reports whether err is a failure to resolve or connect to Jira, meaning the request was never
+// sent, so the change can be queued without risk of it having been applied already
+func isUnreachable(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
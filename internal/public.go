@@ -1,80 +1,161 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
-
-	"github.com/apex/log"
 )
 
-func (t *Tempoo) GetUserAccountID() (string, error) {
-	log.Info("Getting current user Atlassian account ID...")
+func (t *Tempoo) GetUserAccountID(ctx context.Context) (string, error) {
+	t.log().Info("Getting current user Atlassian account ID...")
+
+	user, err := t.WhoAmI(ctx)
+	if err != nil {
+		return "", err
+	}
+	t.log().Infof("Current user Atlassian account ID: %s", user.AccountID)
+
+	return user.AccountID, nil
+}
+
+// WhoAmI returns the Jira user the credentials belong to
+func (t *Tempoo) WhoAmI(ctx context.Context) (*User, error) {
+	if t.cache != nil {
+		if user, ok := t.cache.Identity(t.cacheScope()); ok {
+			t.log().Debugf("Using cached user %s", user.AccountID)
+			return user, nil
+		}
+	}
 
-	resp, err := t.client.R().Get(fmt.Sprintf("%s/myself", JiraAPIRootURL))
+	resp, err := t.request(ctx).Get(fmt.Sprintf("%s/myself", t.apiRoot()))
 	if err != nil {
-		log.Errorf("Request failed: %v", err)
-		return "", &TempooError{Message: "API request failed", Cause: err}
+		t.log().Errorf("Request failed: %v", err)
+		return nil, &TempooError{Message: "API request failed", Cause: err}
 	}
-	log.Debugf("Response: %s", resp.StatusCode())
 
 	if resp.StatusCode() != 200 {
-		return "", &TempooError{Message: fmt.Sprintf("Failed to get user info: %s", resp.Status())}
+		return nil, newAPIError("Failed to get user info", resp)
 	}
 
 	var userData JiraResponse
 	if err := json.Unmarshal(resp.Body(), &userData); err != nil {
-		return "", &TempooError{Message: "Failed to parse user data", Cause: err}
+		return nil, &TempooError{Message: "Failed to parse user data", Cause: err}
 	}
 
 	accountID, ok := userData["accountId"].(string)
 	if !ok || accountID == "" {
-		return "", &TempooError{Message: "Account ID not found in user data"}
+		return nil, &TempooError{Message: "Account ID not found in user data"}
 	}
-	log.Infof("Current user Atlassian account ID: %s", accountID)
 
-	return accountID, nil
+	user := &User{AccountID: accountID}
+	user.DisplayName, _ = userData["displayName"].(string)
+	user.Email, _ = userData["emailAddress"].(string)
+	user.TimeZone, _ = userData["timeZone"].(string)
+
+	if t.cache != nil {
+		t.cache.SetIdentity(t.cacheScope(), user)
+	}
+	return user, nil
 }
 
-func (t *Tempoo) GetWorklogs(issueKey, userID string) ([]string, error) {
-	log.Infof("Getting worklogs for %s", issueKey)
+// SearchIssues returns up to max issues matching a JQL query
+func (t *Tempoo) SearchIssues(ctx context.Context, jql string, max int) (Issues, error) {
+	t.log().Debugf("Searching issues: %s", jql)
+
+	resp, err := t.request(ctx).
+		SetQueryParams(map[string]string{
+			"jql":        jql,
+			"fields":     "summary,status",
+			"maxResults": fmt.Sprintf("%d", max),
+		}).
+		Get(fmt.Sprintf("%s/search/jql", t.apiRoot()))
+	if err != nil {
+		t.log().Errorf("Request failed: %v", err)
+		return nil, &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() != 200 {
+		return nil, newAPIError("Failed to search issues", resp)
+	}
 
-	if err := t.validateIssueKey(issueKey); err != nil {
-		return nil, err
+	var result struct {
+		Issues []struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Summary string `json:"summary"`
+				Status  struct {
+					Name string `json:"name"`
+				} `json:"status"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, &TempooError{Message: "Failed to parse search results", Cause: err}
 	}
 
-	resp, err := t.client.R().Get(fmt.Sprintf("%s/issue/%s/worklog", JiraAPIRootURL, issueKey))
+	issues := Issues{}
+	for _, issue := range result.Issues {
+		issues = append(issues, Issue{Key: issue.Key, Summary: issue.Fields.Summary, Status: issue.Fields.Status.Name})
+	}
+	return issues, nil
+}
+
+// GetIssue returns an issue by key, failing with an InvalidIssueKeyError if it does not exist
+func (t *Tempoo) GetIssue(ctx context.Context, issueKey string) (*Issue, error) {
+	t.log().Debugf("Getting issue %s", issueKey)
+
+	resp, err := t.request(ctx).
+		SetQueryParam("fields", "summary,status").
+		Get(fmt.Sprintf("%s/issue/%s", t.apiRoot(), issueKey))
 	if err != nil {
-		log.Errorf("Request failed: %v", err)
+		t.log().Errorf("Request failed: %v", err)
 		return nil, &TempooError{Message: "API request failed", Cause: err}
 	}
-
+	if resp.StatusCode() == 404 {
+		return nil, &InvalidIssueKeyError{IssueKey: issueKey}
+	}
 	if resp.StatusCode() != 200 {
-		return nil, &TempooError{Message: fmt.Sprintf("Failed to get worklogs: %s", resp.Status())}
+		return nil, newAPIError(fmt.Sprintf("Failed to get issue %s", issueKey), resp)
 	}
 
-	var responseData JiraResponse
-	if err := json.Unmarshal(resp.Body(), &responseData); err != nil {
-		return nil, &TempooError{Message: "Failed to parse worklog data", Cause: err}
+	var result struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string `json:"summary"`
+			Status  struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, &TempooError{Message: "Failed to parse issue", Cause: err}
 	}
 
-	worklogsInterface, ok := responseData["worklogs"]
-	if !ok {
-		return []string{}, nil
+	if t.cache != nil {
+		t.cache.SetIssue(t.cacheScope(), result.Key, result.Fields.Summary)
 	}
+	return &Issue{Key: result.Key, Summary: result.Fields.Summary, Status: result.Fields.Status.Name}, nil
+}
+
+func (t *Tempoo) GetWorklogs(ctx context.Context, issueKey, userID string) ([]string, error) {
+	t.log().Infof("Getting worklogs for %s", issueKey)
 
-	worklogs, ok := worklogsInterface.([]interface{})
-	if !ok {
-		return []string{}, nil
+	if err := t.validateIssueKey(ctx, issueKey); err != nil {
+		return nil, err
 	}
 
-	var worklogsForUser []string
-	for _, worklogInterface := range worklogs {
-		worklog, ok := worklogInterface.(map[string]interface{})
-		if !ok {
-			continue
-		}
+	resp, worklogs, err := t.getIssueWorklogs(ctx, issueKey)
+	if err != nil {
+		t.log().Errorf("Request failed: %v", err)
+		return nil, &TempooError{Message: "API request failed", Cause: err}
+	}
 
+	if resp.StatusCode() != 200 {
+		return nil, newAPIError("Failed to get worklogs", resp)
+	}
+
+	var worklogsForUser []string
+	for _, worklog := range worklogs {
 		// check if this worklog belongs to the user
 		author, ok := worklog["author"].(map[string]interface{})
 		if !ok {
@@ -97,139 +178,222 @@ func (t *Tempoo) GetWorklogs(issueKey, userID string) ([]string, error) {
 		worklogsForUser = append(worklogsForUser, worklogIDStr)
 	}
 
-	log.Debugf("Found %d worklogs for user %s", len(worklogsForUser), userID)
+	t.log().Debugf("Found %d worklogs for user %s", len(worklogsForUser), userID)
 	return worklogsForUser, nil
 }
 
-func (t *Tempoo) AddWorklog(issueKey, worklogTime string, dateStr *string) error {
-	log.Infof("Adding worklog to %s", issueKey)
+func (t *Tempoo) AddWorklog(ctx context.Context, issueKey, worklogTime string, dateStr *string) (*Worklog, error) {
+	t.log().Infof("Adding worklog to %s", issueKey)
 
 	// Validate and parse the worklog hours
 	hours, err := validateWorklogHours(worklogTime)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Convert hours to Jira format
-	jiraTimeFormat := convertHoursToJiraFormat(hours)
-	log.Debugf("Converted %s hours to Jira format: %s", worklogTime, jiraTimeFormat)
-
-	// Use current date if none provided
-	var workDate time.Time
-	if dateStr == nil || *dateStr == "" {
-		workDate = time.Now()
-	} else {
-		parsedDate, err := parseDateString(*dateStr)
-		if err != nil {
-			return err
-		}
-		workDate = parsedDate
+	started, err := worklogStart(dateStr)
+	if err != nil {
+		return nil, err
 	}
 
-	// create ISO timestamp for 08:30 AM on the specified date
-	started := time.Date(
-		workDate.Year(), workDate.Month(), workDate.Day(),
-		8, 30, 0, 751000000, // 08:30:00.751
-		time.UTC,
-	).Format("2006-01-02T15:04:05.000-0700")
+	t.log().Debugf("Started timestamp: %s", started.Format(jiraTimestampFormat))
 
-	log.Debugf("Started timestamp: %s", started)
+	return t.postWorklog(ctx, issueKey, started, time.Duration(hours*float64(time.Hour)), "")
+}
+
+// AddWorklogAt adds a worklog of the given duration to an issue, starting at the given time.
+// The duration is sent in whole seconds, so callers are expected to round it first.
+func (t *Tempoo) AddWorklogAt(ctx context.Context, issueKey string, started time.Time, duration time.Duration) (*Worklog, error) {
+	return t.AddWorklogWithComment(ctx, issueKey, started, duration, "")
+}
 
-	payload := map[string]string{
-		"timeSpent": jiraTimeFormat,
-		"started":   started,
+// AddWorklogWithComment adds a worklog of the given duration to an issue, starting at the given time,
+// with a plain text comment. An empty comment adds none.
+func (t *Tempoo) AddWorklogWithComment(ctx context.Context, issueKey string, started time.Time, duration time.Duration, comment string) (*Worklog, error) {
+	t.log().Infof("Adding worklog to %s", issueKey)
+
+	if err := validateWorklogDuration(duration); err != nil {
+		return nil, err
 	}
 
-	log.Debugf("Payload: %+v", payload)
+	return t.postWorklog(ctx, issueKey, started, duration, comment)
+}
 
-	resp, err := t.client.R().
-		SetBody(payload).
-		Post(fmt.Sprintf("%s/issue/%s/worklog", JiraAPIRootURL, issueKey))
+// postWorklog sends a worklog to the issue, queueing it instead if Jira is unreachable and the offline queue is enabled
+func (t *Tempoo) postWorklog(ctx context.Context, issueKey string, started time.Time, duration time.Duration, comment string) (*Worklog, error) {
+	seconds := int(duration.Seconds())
 
+	resp, err := t.sendWorklog(ctx, issueKey, started, seconds, comment)
 	if err != nil {
-		log.Errorf("Request failed: %v", err)
-		return &TempooError{Message: "API request failed", Cause: err}
+		t.log().Errorf("Request failed: %v", err)
+		if t.queue != nil && isUnreachable(err) {
+			err := t.queue.Enqueue(&QueuedOperation{
+				Op:               OpAddWorklog,
+				IssueKey:         issueKey,
+				Started:          started,
+				TimeSpentSeconds: seconds,
+				Comment:          comment,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &Worklog{IssueKey: issueKey, Started: started, TimeSpentSeconds: seconds, TimeSpent: formatTimeSpent(seconds), Comment: comment, Queued: true}, nil
+		}
+		return nil, &TempooError{Message: "API request failed", Cause: err}
 	}
 
 	if resp.StatusCode() == 201 {
-		log.Infof("Added worklog of %s hours to %s", worklogTime, issueKey)
-		return nil
+		created := t.recordCreated(issueKey, resp)
+		t.log().Infof("Added worklog of %s to %s", convertHoursToJiraFormat(duration.Hours()), issueKey)
+		return created, nil
 	}
 
-	return &TempooError{Message: fmt.Sprintf("Failed to add worklog: %s", resp.Status())}
+	return nil, newAPIError("Failed to add worklog", resp)
 }
 
-func (t *Tempoo) DeleteWorklog(issueKey, worklogID string) error {
-	log.Debugf("Deleting worklog %s for %s", worklogID, issueKey)
+// DeleteWorklog deletes a worklog. With the offline queue enabled, a deletion that cannot reach Jira is
+// queued and a *QueuedError returned.
+func (t *Tempoo) DeleteWorklog(ctx context.Context, issueKey, worklogID string) error {
+	t.log().Debugf("Deleting worklog %s for %s", worklogID, issueKey)
 
-	resp, err := t.client.R().Delete(fmt.Sprintf("%s/issue/%s/worklog/%s", JiraAPIRootURL, issueKey, worklogID))
+	// capture the worklog before it is gone, so the deletion can be undone
+	var snapshot WorklogData
+	if t.history != nil {
+		if _, worklog, err := t.getWorklog(ctx, issueKey, worklogID); err == nil {
+			snapshot = worklog
+		}
+	}
+
+	resp, err := t.sendWorklogDeletion(ctx, issueKey, worklogID)
 	if err != nil {
-		log.Errorf("Request failed: %v", err)
+		t.log().Errorf("Request failed: %v", err)
+		if t.queue != nil && isUnreachable(err) {
+			if err := t.queue.Enqueue(&QueuedOperation{
+				Op:        OpDeleteWorklog,
+				IssueKey:  issueKey,
+				WorklogID: worklogID,
+			}); err != nil {
+				return err
+			}
+			return &QueuedError{Op: OpDeleteWorklog, IssueKey: issueKey, WorklogID: worklogID}
+		}
 		return &TempooError{Message: "API request failed", Cause: err}
 	}
 
 	if resp.StatusCode() != 204 {
-		return &TempooError{Message: fmt.Sprintf("Failed to delete worklog: %s", resp.Status())}
+		return newAPIError("Failed to delete worklog", resp)
 	}
 
-	log.Infof("Deleted worklog %s for %s", worklogID, issueKey)
+	if snapshot != nil {
+		t.record(&HistoryEntry{Op: OpDeleteWorklog, IssueKey: issueKey, WorklogID: worklogID, Worklog: snapshot})
+	} else if t.history != nil {
+		t.log().Warnf("Could not capture worklog %s before deleting it, the deletion cannot be undone", worklogID)
+	}
+
+	t.log().Infof("Deleted worklog %s for %s", worklogID, issueKey)
 	return nil
 }
 
-// ListWorklogs lists all worklogs for a given issue key for the current user
-func (t *Tempoo) ListWorklogs(issueKey string) error {
-	log.Infof("Listing worklogs for %s", issueKey)
+// UpdateWorklog changes the hours and/or date of an existing worklog, leaving unset values as they are.
+// A new date keeps the original start time of day. Edits are never queued offline: they start from the
+// worklog as Jira has it, which cannot be read while Jira is unreachable.
+func (t *Tempoo) UpdateWorklog(ctx context.Context, issueKey, worklogID, worklogTime string, dateStr *string) (*Worklog, error) {
+	duration, date, err := parseWorklogUpdate(worklogTime, dateStr)
+	if err != nil {
+		return nil, err
+	}
+	return t.UpdateWorklogDuration(ctx, issueKey, worklogID, duration, date)
+}
 
-	// validate the issue key
-	if err := t.validateIssueKey(issueKey); err != nil {
-		return err
+// UpdateWorklogDuration changes the duration and/or day of an existing worklog, leaving zero values as they are.
+// A new date keeps the original start time of day.
+func (t *Tempoo) UpdateWorklogDuration(ctx context.Context, issueKey, worklogID string, duration time.Duration, date time.Time) (*Worklog, error) {
+	if duration != 0 {
+		if err := validateWorklogDuration(duration); err != nil {
+			return nil, err
+		}
 	}
+	t.log().Infof("Updating worklog %s on %s", worklogID, issueKey)
 
-	// get current user's account ID
-	userID, err := t.GetUserAccountID()
+	resp, previous, err := t.getWorklog(ctx, issueKey, worklogID)
 	if err != nil {
-		return fmt.Errorf("failed to get user account ID: %w", err)
+		t.log().Errorf("Request failed: %v", err)
+		return nil, &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() != 200 {
+		return nil, newAPIError(fmt.Sprintf("Failed to get worklog %s", worklogID), resp)
 	}
-	log.Debugf("User ID: %s", userID)
 
-	// get the worklogs for the issue
-	resp, err := t.client.R().Get(fmt.Sprintf("%s/issue/%s/worklog", JiraAPIRootURL, issueKey))
-	if err != nil {
-		log.Errorf("Request failed: %v", err)
-		return &TempooError{Message: "API request failed", Cause: err}
+	payload := recreatePayload(previous)
+	delete(payload, "comment")
+	delete(payload, "visibility")
+
+	if duration != 0 {
+		payload["timeSpentSeconds"] = int(duration.Seconds())
 	}
 
-	// check if the request was successful
+	if !date.IsZero() {
+		startedStr, _ := previous["started"].(string)
+		started, err := time.Parse(jiraTimestampFormat, startedStr)
+		if err != nil {
+			return nil, &TempooError{Message: fmt.Sprintf("Failed to parse start of worklog %s", worklogID), Cause: err}
+		}
+		started = time.Date(date.Year(), date.Month(), date.Day(),
+			started.Hour(), started.Minute(), started.Second(), started.Nanosecond(), started.Location())
+		payload["started"] = started.Format(jiraTimestampFormat)
+	}
+
+	resp, err = t.sendWorklogUpdate(ctx, issueKey, worklogID, payload)
+	if err != nil {
+		t.log().Errorf("Request failed: %v", err)
+		return nil, &TempooError{Message: "API request failed", Cause: err}
+	}
 	if resp.StatusCode() != 200 {
-		return &TempooError{Message: fmt.Sprintf("Failed to list worklogs: %s", resp.Status())}
+		return nil, newAPIError("Failed to update worklog", resp)
 	}
 
-	// responseData is a map[string]interface{} type
-	var responseData JiraResponse
-	// unmarshal the response body into responseData
-	if err := json.Unmarshal(resp.Body(), &responseData); err != nil {
-		return &TempooError{Message: "Failed to parse worklog data", Cause: err}
+	var updated WorklogData
+	if err := json.Unmarshal(resp.Body(), &updated); err != nil {
+		return nil, &TempooError{Message: "Failed to parse worklog data", Cause: err}
 	}
+	t.record(&HistoryEntry{Op: OpEditWorklog, IssueKey: issueKey, WorklogID: worklogID, Worklog: updated, Previous: previous})
+
+	t.log().Infof("Updated worklog %s on %s", worklogID, issueKey)
+	worklog := newWorklog(issueKey, updated)
+	return &worklog, nil
+}
 
-	// parse responseData as a list of worklogs
-	worklogsInterface, ok := responseData["worklogs"]
-	if !ok {
-		return &TempooError{Message: "Worklogs not found in response data"}
+// ListWorklogs returns all worklogs for a given issue key for the current user
+func (t *Tempoo) ListWorklogs(ctx context.Context, issueKey string) (Worklogs, error) {
+	t.log().Infof("Listing worklogs for %s", issueKey)
+
+	// validate the issue key
+	if err := t.validateIssueKey(ctx, issueKey); err != nil {
+		return nil, err
 	}
 
-	worklogs, ok := worklogsInterface.([]interface{})
-	if !ok {
-		return &TempooError{Message: "Worklogs not found in response data"}
+	// get current user's account ID
+	userID, err := t.GetUserAccountID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user account ID: %w", err)
 	}
+	t.log().Debugf("User ID: %s", userID)
 
-	// filter worklogs for the current user
-	var userWorklogs []interface{}
-	for _, worklogInterface := range worklogs {
-		worklog, ok := worklogInterface.(map[string]interface{})
-		if !ok {
-			continue
-		}
+	// get the worklogs for the issue
+	resp, worklogs, err := t.getIssueWorklogs(ctx, issueKey)
+	if err != nil {
+		t.log().Errorf("Request failed: %v", err)
+		return nil, &TempooError{Message: "API request failed", Cause: err}
+	}
 
+	// check if the request was successful
+	if resp.StatusCode() != 200 {
+		return nil, newAPIError("Failed to list worklogs", resp)
+	}
+
+	// filter worklogs for the current user
+	userWorklogs := Worklogs{}
+	for _, worklog := range worklogs {
 		// check if this worklog belongs to the user
 		author, ok := worklog["author"].(map[string]interface{})
 		if !ok {
@@ -242,16 +406,14 @@ func (t *Tempoo) ListWorklogs(issueKey string) error {
 		}
 
 		// this worklog belongs to the current user
-		userWorklogs = append(userWorklogs, worklogInterface)
+		userWorklogs = append(userWorklogs, newWorklog(issueKey, worklog))
 	}
 
 	if len(userWorklogs) == 0 {
-		log.Infof("No worklogs found for issue %s for current user", issueKey)
-		return nil
+		t.log().Infof("No worklogs found for issue %s for current user", issueKey)
 	} else {
-		log.Infof("Found %d worklog(s) for issue %s for current user:", len(userWorklogs), issueKey)
-		t.printWorklogs(userWorklogs)
+		t.log().Infof("Found %d worklog(s) for issue %s for current user", len(userWorklogs), issueKey)
 	}
 
-	return nil
+	return userWorklogs, nil
 }
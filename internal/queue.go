@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// QueueFileName is the name of the offline queue journal inside the tempoo home directory
+const QueueFileName = "queue.jsonl"
+
+// QueuedOperation is a worklog change waiting to be replayed against Jira
+type QueuedOperation struct {
+	ID               string    `json:"id"`
+	Op               string    `json:"op"`
+	IssueKey         string    `json:"issue_key"`
+	Started          time.Time `json:"started,omitempty"`
+	TimeSpentSeconds int       `json:"time_spent_seconds,omitempty"`
+	WorklogID        string    `json:"worklog_id,omitempty"`
+	Comment          string    `json:"comment,omitempty"`
+	QueuedAt         time.Time `json:"queued_at"`
+	Attempts         int       `json:"attempts"`
+	LastError        string    `json:"last_error,omitempty"`
+	// Conflict marks operations Jira rejected, which are skipped by sync until dropped
+	Conflict bool `json:"conflict,omitempty"`
+}
+
+// QueuedOperations is a list of queued operations
+type QueuedOperations []*QueuedOperation
+
+// Queue is a durable, append-only journal of operations waiting for Jira to become reachable
+type Queue struct {
+	path string
+}
+
+// NewQueue creates a queue backed by the journal file in dir
+func NewQueue(dir string) *Queue {
+	return &Queue{path: filepath.Join(dir, QueueFileName)}
+}
+
+// Enqueue appends an operation to the journal and flushes it to disk
+func (q *Queue) Enqueue(op *QueuedOperation) error {
+	op.ID = newOperationID()
+	op.QueuedAt = time.Now()
+
+	err := withFileLock(q.path, func() error {
+		return appendJSONLine(q.path, op)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Warnf("Jira is unreachable, queued %s of %s as %s. Run 'tempoo sync' when back online", op.Op, op.IssueKey, op.ID)
+	return nil
+}
+
+// List returns the queued operations in the order they were queued
+func (q *Queue) List() (QueuedOperations, error) {
+	var ops QueuedOperations
+	err := withFileLock(q.path, func() error {
+		var err error
+		ops, err = readJSONLines[QueuedOperation](q.path)
+		return err
+	})
+	return ops, err
+}
+
+// Drop removes the operations with the given IDs, or every operation if all is set, returning the number removed
+func (q *Queue) Drop(ids []string, all bool) (int, error) {
+	wanted := map[string]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	dropped := 0
+	err := q.update(func(ops []*QueuedOperation) ([]*QueuedOperation, error) {
+		var kept []*QueuedOperation
+		for _, op := range ops {
+			if all || wanted[op.ID] {
+				dropped++
+				delete(wanted, op.ID)
+				continue
+			}
+			kept = append(kept, op)
+		}
+
+		for id := range wanted {
+			return nil, &TempooError{Message: fmt.Sprintf("No queued operation with ID %s", id)}
+		}
+		return kept, nil
+	})
+	return dropped, err
+}
+
+// update loads the journal, applies fn and rewrites the journal atomically, all while holding the lock
+func (q *Queue) update(fn func([]*QueuedOperation) ([]*QueuedOperation, error)) error {
+	return withFileLock(q.path, func() error {
+		ops, err := readJSONLines[QueuedOperation](q.path)
+		if err != nil {
+			return err
+		}
+
+		ops, err = fn(ops)
+		if err != nil {
+			return err
+		}
+		return writeJSONLines(q.path, ops)
+	})
+}
+
+// newOperationID returns a short random ID for a queued operation
+func newOperationID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
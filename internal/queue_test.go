@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQueue_EnqueueAndList(t *testing.T) {
+	queue := NewQueue(t.TempDir())
+	started := time.Date(2025, 7, 1, 8, 30, 0, 0, time.UTC)
+
+	if err := queue.Enqueue(&QueuedOperation{Op: OpAddWorklog, IssueKey: "TEST-1", Started: started, TimeSpentSeconds: 3600}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := queue.Enqueue(&QueuedOperation{Op: OpDeleteWorklog, IssueKey: "TEST-2", WorklogID: "10001"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	ops, err := queue.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("Expected 2 queued operations, got %d", len(ops))
+	}
+
+	// order is preserved and IDs are assigned
+	if ops[0].IssueKey != "TEST-1" || ops[1].IssueKey != "TEST-2" {
+		t.Errorf("Expected queue order TEST-1, TEST-2, got %s, %s", ops[0].IssueKey, ops[1].IssueKey)
+	}
+	if ops[0].ID == "" || ops[0].ID == ops[1].ID {
+		t.Errorf("Expected unique IDs, got %q and %q", ops[0].ID, ops[1].ID)
+	}
+	if !ops[0].Started.Equal(started) || ops[0].TimeSpentSeconds != 3600 {
+		t.Errorf("Expected payload to round-trip, got %+v", ops[0])
+	}
+}
+
+func TestQueue_ListEmpty(t *testing.T) {
+	ops, err := NewQueue(t.TempDir()).List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(ops) != 0 {
+		t.Errorf("Expected empty queue, got %d operations", len(ops))
+	}
+}
+
+func TestQueue_Drop(t *testing.T) {
+	queue := NewQueue(t.TempDir())
+	for _, key := range []string{"TEST-1", "TEST-2", "TEST-3"} {
+		queue.Enqueue(&QueuedOperation{Op: OpDeleteWorklog, IssueKey: key, WorklogID: "1"})
+	}
+	ops, _ := queue.List()
+
+	dropped, err := queue.Drop([]string{ops[1].ID}, false)
+	if err != nil {
+		t.Fatalf("Drop() error = %v", err)
+	}
+	if dropped != 1 {
+		t.Errorf("Expected 1 dropped, got %d", dropped)
+	}
+
+	// unknown IDs are rejected without touching the queue
+	if _, err := queue.Drop([]string{"missing"}, false); err == nil {
+		t.Error("Expected error dropping an unknown ID")
+	}
+
+	ops, _ = queue.List()
+	if len(ops) != 2 || ops[0].IssueKey != "TEST-1" || ops[1].IssueKey != "TEST-3" {
+		t.Errorf("Expected TEST-1 and TEST-3 to remain, got %+v", ops)
+	}
+
+	dropped, err = queue.Drop(nil, true)
+	if err != nil {
+		t.Fatalf("Drop() error = %v", err)
+	}
+	if dropped != 2 {
+		t.Errorf("Expected 2 dropped, got %d", dropped)
+	}
+}
+
+func TestQueue_SkipsTornLines(t *testing.T) {
+	dir := t.TempDir()
+	queue := NewQueue(dir)
+	queue.Enqueue(&QueuedOperation{Op: OpDeleteWorklog, IssueKey: "TEST-1", WorklogID: "1"})
+
+	// simulate a crash in the middle of an append
+	f, err := os.OpenFile(filepath.Join(dir, QueueFileName), os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":"abc","op":"add","iss`)
+	f.Close()
+
+	ops, err := queue.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(ops) != 1 {
+		t.Errorf("Expected the intact operation only, got %d", len(ops))
+	}
+}
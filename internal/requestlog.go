@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/apex/log"
+	"github.com/go-resty/resty/v2"
+)
+
+// logRequests logs every request client makes at debug level as structured fields:
+// method, URL, status, latency, retries and body sizes. Credentials are redacted.
+func logRequests(client *resty.Client, logger func() log.Interface) {
+	client.OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		fields := requestFields(resp.Request)
+		fields["status"] = resp.StatusCode()
+		fields["latency_ms"] = resp.Time().Milliseconds()
+		fields["response_bytes"] = len(resp.Body())
+		logger().WithFields(fields).Debug("HTTP request")
+		return nil
+	})
+	client.OnError(func(req *resty.Request, err error) {
+		fields := requestFields(req)
+		fields["latency_ms"] = time.Since(req.Time).Milliseconds()
+		logger().WithFields(fields).WithError(err).Debug("HTTP request failed")
+	})
+}
+
+// requestFields returns the log fields describing a request
+func requestFields(req *resty.Request) log.Fields {
+	fields := log.Fields{
+		"method":  req.Method,
+		"url":     redactURL(req.URL),
+		"retries": max(req.Attempt-1, 0),
+	}
+	if raw := req.RawRequest; raw != nil {
+		fields["url"] = redactURL(raw.URL.String())
+		fields["request_bytes"] = raw.ContentLength
+		if len(raw.Header) > 0 {
+			fields["headers"] = redactHeaders(raw.Header)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"tempoo/internal/fakejira"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+)
+
+func TestLogRequests(t *testing.T) {
+	server := fakejira.New()
+	defer server.Close()
+	server.AddUser(fakejira.User{AccountID: "user-1", Email: "test@example.com", APIToken: "test-token"})
+
+	handler := memory.New()
+	logger := &log.Logger{Handler: handler, Level: log.DebugLevel}
+	client, _ := NewClient(ClientOptions{BaseURL: server.APIURL(), Email: "test@example.com", APIToken: "test-token", Logger: logger})
+	if _, err := client.WhoAmI(t.Context()); err != nil {
+		t.Fatalf("WhoAmI() error = %v", err)
+	}
+
+	var entry *log.Entry
+	for _, e := range handler.Entries {
+		if e.Message == "HTTP request" {
+			entry = e
+		}
+	}
+	if entry == nil {
+		t.Fatalf("Expected an HTTP request log entry, got %+v", handler.Entries)
+	}
+	if entry.Fields["method"] != "GET" || entry.Fields["url"] != server.APIURL()+"/myself" || entry.Fields["status"] != 200 || entry.Fields["retries"] != 0 {
+		t.Errorf("Unexpected fields %v", entry.Fields)
+	}
+	for _, field := range []string{"latency_ms", "request_bytes", "response_bytes"} {
+		if _, ok := entry.Fields[field]; !ok {
+			t.Errorf("Missing field %s in %v", field, entry.Fields)
+		}
+	}
+	if logged := fmt.Sprint(entry.Fields); strings.Contains(logged, "Basic ") || !strings.Contains(logged, redacted) {
+		t.Errorf("Expected the Authorization header to be redacted, got %s", logged)
+	}
+}
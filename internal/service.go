@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// WorklogService is the set of worklog operations the CLI needs, implemented by each backend.
+// Requests are bound to the context each method is given.
+type WorklogService interface {
+	// AddWorklog logs hours to an issue on a DD.MM.YYYY date, or today if dateStr is nil
+	AddWorklog(ctx context.Context, issueKey, hours string, dateStr *string) (*Worklog, error)
+	// AddWorklogAt logs a duration to an issue starting at the given time
+	AddWorklogAt(ctx context.Context, issueKey string, started time.Time, duration time.Duration) (*Worklog, error)
+	// AddWorklogWithComment logs a duration to an issue starting at the given time, with a comment
+	AddWorklogWithComment(ctx context.Context, issueKey string, started time.Time, duration time.Duration, comment string) (*Worklog, error)
+	// ListWorklogs returns the current user's worklogs on an issue
+	ListWorklogs(ctx context.Context, issueKey string) (Worklogs, error)
+	// UpdateWorklog changes the hours and/or date of a worklog, leaving unset values as they are
+	UpdateWorklog(ctx context.Context, issueKey, worklogID, hours string, dateStr *string) (*Worklog, error)
+	// UpdateWorklogDuration changes the duration and/or day of a worklog, leaving zero values as they are
+	UpdateWorklogDuration(ctx context.Context, issueKey, worklogID string, duration time.Duration, date time.Time) (*Worklog, error)
+	// DeleteWorklog deletes a worklog from an issue
+	DeleteWorklog(ctx context.Context, issueKey, worklogID string) error
+	// GetUserAccountID returns the Atlassian account ID of the current user
+	GetUserAccountID(ctx context.Context) (string, error)
+	// GetWorklogs returns the IDs of a user's worklogs on an issue
+	GetWorklogs(ctx context.Context, issueKey, userID string) ([]string, error)
+	// WhoAmI returns the current user
+	WhoAmI(ctx context.Context) (*User, error)
+	// SearchIssues returns up to max issues matching a JQL query
+	SearchIssues(ctx context.Context, jql string, max int) (Issues, error)
+	// GetIssue returns an issue by key, failing with an InvalidIssueKeyError if it does not exist
+	GetIssue(ctx context.Context, issueKey string) (*Issue, error)
+}
+
+var (
+	_ WorklogService = (*Tempoo)(nil)
+	_ WorklogService = (*TempoClient)(nil)
+	_ WorklogService = (*MemoryService)(nil)
+)
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// commit spacing heuristics, variables so tests can change them
+var (
+	// sessionGap is the longest pause between two commits on a day still counted as work on the later one
+	sessionGap = 2 * time.Hour
+	// sessionLead is the time credited to the first commit of a session, spent before it was made
+	sessionLead = 30 * time.Minute
+)
+
+// issueKeyInText finds a Jira issue key in a commit message
+var issueKeyInText = regexp.MustCompile(`\b` + DefaultBranchPattern + `\b`)
+
+// gitLogFieldSeparator separates the fields of a commit in the git log output
+const gitLogFieldSeparator = "\x1f"
+
+// Commit is a commit read from a local git repository
+type Commit struct {
+	Repo    string
+	Hash    string
+	Time    time.Time
+	Subject string
+	// Ref is the ref the commit was reached from, such as refs/heads/feature/INF-88-fix-thing
+	Ref string
+}
+
+// ReadCommits returns the commits by author in a local git repository made during the range of days,
+// reached from any branch. It runs git, which must be installed. An empty author means the
+// user.email configured for the repository.
+func ReadCommits(repo, author string, days DateRange) ([]Commit, error) {
+	if author == "" {
+		email, err := runGit(repo, "config", "user.email")
+		if err != nil {
+			return nil, err
+		}
+		author = strings.TrimSpace(email)
+	}
+
+	// git filters by commit date, which is never before the author date but can be well after it
+	// when commits are rebased, so only the start of the range is left to git
+	output, err := runGit(repo, "log", "--all", "--source", "--no-merges",
+		"--author="+author,
+		"--since="+days.Start().Format(time.RFC3339),
+		"--format="+strings.Join([]string{"%H", "%aI", "%S", "%s"}, gitLogFieldSeparator))
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.SplitN(line, gitLogFieldSeparator, 4)
+		if len(fields) != 4 {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil || !days.Contains(at) {
+			continue
+		}
+		commits = append(commits, Commit{Repo: repo, Hash: fields[0], Time: at, Ref: fields[2], Subject: fields[3]})
+	}
+	return commits, nil
+}
+
+// runGit runs a git command in a repository and returns its output
+func runGit(repo string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", &TempooError{Message: "Reading commits needs git to be installed", Cause: err}
+		}
+		return "", &TempooError{Message: fmt.Sprintf("git %s failed in %s: %s", args[0], repo, strings.TrimSpace(stderr.String())), Cause: err}
+	}
+	return stdout.String(), nil
+}
+
+// CommitIssueKey returns the issue key a commit was made for, taken from its message or else from
+// the name of the branch it was reached from
+func CommitIssueKey(commit Commit, git GitConfig) (string, bool) {
+	if issueKey := issueKeyInText.FindString(commit.Subject); issueKey != "" {
+		return issueKey, true
+	}
+	return git.IssueKeyFromBranch(branchName(commit.Ref))
+}
+
+// branchName returns the branch name of a local or remote branch ref
+func branchName(ref string) string {
+	if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+		return name
+	}
+	if name, ok := strings.CutPrefix(ref, "refs/remotes/"); ok {
+		// drop the remote name
+		if _, branch, found := strings.Cut(name, "/"); found {
+			return branch
+		}
+	}
+	return ref
+}
+
+// SuggestWorklogs estimates the time spent per issue and day from the spacing of commits, across repositories.
+// A commit is credited with the time since the previous commit that day, unless that is more than sessionGap,
+// in which case it starts a new session and is credited sessionLead. Commits without an issue key still
+// count for spacing but are not logged. Durations are rounded with the configured rounding.
+func SuggestWorklogs(commits []Commit, git GitConfig, rounding RoundingConfig) *Draft {
+	sorted := append([]Commit(nil), commits...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	builder := draftBuilder{}
+	var previous time.Time
+	for _, commit := range sorted {
+		credited := sessionLead
+		if gap := commit.Time.Sub(previous); calendarDay(commit.Time).Equal(calendarDay(previous)) && gap <= sessionGap {
+			credited = gap
+		}
+		previous = commit.Time
+
+		if issueKey, ok := CommitIssueKey(commit, git); ok {
+			builder.add(commit.Time, issueKey, credited, filepath.Base(commit.Repo))
+		}
+	}
+	return builder.draft(rounding, summariseCommits)
+}
+
+// summariseCommits describes the commits behind a suggested worklog from the repositories they were made in
+func summariseCommits(repos []string) string {
+	var names []string
+	seen := map[string]bool{}
+	for _, repo := range repos {
+		if !seen[repo] {
+			seen[repo] = true
+			names = append(names, repo)
+		}
+	}
+	return fmt.Sprintf("%d commit(s) in %s", len(repos), strings.Join(names, ", "))
+}
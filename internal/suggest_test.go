@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSuggestWorklogs(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.July, day, hour, minute, 0, 0, time.Local)
+	}
+	commits := []Commit{
+		// a session on INF-88 from 09:00, the first commit credited the session lead
+		{Repo: "/src/api", Time: at(1, 9, 0), Subject: "INF-88 start the fix", Ref: "refs/heads/main"},
+		{Repo: "/src/api", Time: at(1, 10, 30), Subject: "more work", Ref: "refs/heads/feature/INF-88-fix-thing"},
+		// after a long pause, a new session credited the lead only
+		{Repo: "/src/web", Time: at(1, 15, 0), Subject: "Fix typo", Ref: "refs/remotes/origin/OPS-7-typo"},
+		// no issue key, only used for spacing
+		{Repo: "/src/web", Time: at(2, 9, 0), Subject: "tidy up", Ref: "refs/heads/main"},
+		{Repo: "/src/web", Time: at(2, 10, 0), Subject: "OPS-7 done", Ref: "refs/heads/main"},
+	}
+
+	draft := SuggestWorklogs(commits, GitConfig{}, RoundingConfig{GranularityMinutes: 30, Mode: RoundNearest})
+
+	expected := []DraftWorklog{
+		{Date: "01.07.2025", IssueKey: "INF-88", Hours: 2, Note: "2 commit(s) in api"},
+		{Date: "01.07.2025", IssueKey: "OPS-7", Hours: 0.5, Note: "1 commit(s) in web"},
+		{Date: "02.07.2025", IssueKey: "OPS-7", Hours: 1, Note: "1 commit(s) in web"},
+	}
+	if len(draft.Worklogs) != len(expected) {
+		t.Fatalf("Expected %d worklogs, got %+v", len(expected), draft.Worklogs)
+	}
+	for i := range expected {
+		if draft.Worklogs[i] != expected[i] {
+			t.Errorf("Worklog %d: expected %+v, got %+v", i, expected[i], draft.Worklogs[i])
+		}
+	}
+}
+
+func TestReadCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	git := func(env []string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(cmd.Environ(), env...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	commit := func(email, date, message string) {
+		env := []string{"GIT_AUTHOR_EMAIL=" + email, "GIT_AUTHOR_NAME=Dev", "GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date}
+		git(env, "commit", "--allow-empty", "-q", "-m", message)
+	}
+
+	git(nil, "init", "-q", "-b", "feature/INF-88-fix-thing")
+	git(nil, "config", "user.email", "me@example.com")
+	git(nil, "config", "user.name", "Me")
+	commit("me@example.com", "2025-06-30T12:00:00", "before the range")
+	commit("me@example.com", "2025-07-01T09:00:00", "first")
+	commit("someone@example.com", "2025-07-01T10:00:00", "not mine")
+	commit("me@example.com", "2025-07-02T11:00:00", "second")
+
+	days := DateRange{From: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2025, time.July, 2, 0, 0, 0, 0, time.UTC)}
+	commits, err := ReadCommits(repo, "", days)
+	if err != nil {
+		t.Fatalf("ReadCommits failed: %v", err)
+	}
+
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 commits, got %+v", commits)
+	}
+	for _, commit := range commits {
+		if commit.Ref != "refs/heads/feature/INF-88-fix-thing" {
+			t.Errorf("Expected the commit to be reached from the branch, got %s", commit.Ref)
+		}
+		if issueKey, _ := CommitIssueKey(commit, GitConfig{}); issueKey != "INF-88" {
+			t.Errorf("Expected issue key INF-88 from the branch, got %s", issueKey)
+		}
+	}
+
+	if _, err := ReadCommits(filepath.Join(repo, "missing"), "me@example.com", days); err == nil {
+		t.Error("Expected an error outside a repository")
+	}
+}
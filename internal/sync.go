@@ -0,0 +1,249 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// retry policy for replaying queued operations, variables so tests can shorten them
+var (
+	syncAttempts = 3
+	syncBackoff  = 2 * time.Second
+)
+
+// replayOutcome classifies the result of replaying one queued operation
+type replayOutcome int
+
+const (
+	// replayApplied means Jira accepted the operation
+	replayApplied replayOutcome = iota
+	// replayDuplicate means the change was already present in Jira
+	replayDuplicate
+	// replayConflict means Jira rejected the operation and retrying will not help
+	replayConflict
+	// replayRetry means the failure is transient and the operation should be retried
+	replayRetry
+)
+
+// SyncResult summarises a replay of the offline queue
+type SyncResult struct {
+	Applied    int `json:"applied"`
+	Duplicates int `json:"duplicates"`
+	Conflicts  int `json:"conflicts"`
+	Pending    int `json:"pending"`
+}
+
+// SyncQueue replays the queued operations in order.
+// Transient failures are retried with backoff; if they persist the sync stops so later operations keep their order.
+// Operations Jira rejects are flagged as conflicts and left in the queue for the user to inspect.
+// Syncs of the same queue are serialized, so a concurrent sync cannot replay an operation twice.
+func (t *Tempoo) SyncQueue(ctx context.Context, q *Queue) (*SyncResult, error) {
+	var result *SyncResult
+	err := withFileLock(q.syncPath(), func() error {
+		var err error
+		result, err = t.syncQueue(ctx, q)
+		return err
+	})
+	return result, err
+}
+
+// syncQueue replays the queue while holding its sync lock
+func (t *Tempoo) syncQueue(ctx context.Context, q *Queue) (*SyncResult, error) {
+	result := &SyncResult{}
+
+	ops, err := q.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		t.log().Info("Offline queue is empty")
+		return result, nil
+	}
+	t.log().Infof("Replaying %d queued operation(s)", len(ops))
+
+	var userID string
+	for i, op := range ops {
+		// a long replay keeps its sync lock from being broken as stale
+		touchLock(q.syncPath())
+
+		if op.Conflict {
+			t.log().Warnf("Skipping %s %s of %s, flagged as conflict: %s", op.ID, op.Op, op.IssueKey, op.LastError)
+			result.Conflicts++
+			continue
+		}
+
+		// only additions need the user ID, for duplicate detection
+		if op.Op == OpAddWorklog && userID == "" {
+			if userID, err = t.GetUserAccountID(ctx); err != nil {
+				result.Pending = len(ops) - i
+				return result, err
+			}
+		}
+
+		outcome, err := t.replayWithRetry(ctx, op, userID)
+		switch outcome {
+		case replayApplied:
+			t.log().Infof("Replayed %s %s of %s", op.ID, op.Op, op.IssueKey)
+			result.Applied++
+			err = q.complete(op.ID)
+		case replayDuplicate:
+			t.log().Infof("Dropped %s %s of %s, already applied in Jira", op.ID, op.Op, op.IssueKey)
+			result.Duplicates++
+			err = q.complete(op.ID)
+		case replayConflict:
+			t.log().Warnf("Conflict replaying %s %s of %s: %v", op.ID, op.Op, op.IssueKey, err)
+			result.Conflicts++
+			err = q.fail(op, err, true)
+		default:
+			result.Pending = len(ops) - i
+			if failErr := q.fail(op, err, false); failErr != nil {
+				return result, failErr
+			}
+			return result, &TempooError{Message: fmt.Sprintf("Sync stopped at %s with %d operation(s) pending", op.ID, result.Pending), Cause: err}
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// replayWithRetry replays an operation, retrying transient failures with exponential backoff
+func (t *Tempoo) replayWithRetry(ctx context.Context, op *QueuedOperation, userID string) (replayOutcome, error) {
+	backoff := syncBackoff
+	for attempt := 1; ; attempt++ {
+		op.Attempts++
+		outcome, err := t.replay(ctx, op, userID)
+		if outcome != replayRetry || attempt >= syncAttempts {
+			return outcome, err
+		}
+
+		t.log().Warnf("Attempt %d for %s failed: %v, retrying in %s", attempt, op.ID, err, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// replay sends one queued operation to Jira
+func (t *Tempoo) replay(ctx context.Context, op *QueuedOperation, userID string) (replayOutcome, error) {
+	switch op.Op {
+	case OpAddWorklog:
+		resp, worklogs, err := t.getIssueWorklogs(ctx, op.IssueKey)
+		if outcome, err := classifyReplay(resp, err, 200); outcome != replayApplied {
+			return outcome, err
+		}
+		if hasMatchingWorklog(worklogs, userID, op.Started, op.TimeSpentSeconds) {
+			return replayDuplicate, nil
+		}
+
+		resp, err = t.sendWorklog(ctx, op.IssueKey, op.Started, op.TimeSpentSeconds, op.Comment)
+		outcome, err := classifyReplay(resp, err, 201)
+		if outcome == replayApplied {
+			t.recordCreated(op.IssueKey, resp)
+		}
+		return outcome, err
+
+	case OpDeleteWorklog:
+		// fetching first both detects worklogs that are already gone and captures the worklog for undo
+		resp, snapshot, err := t.getWorklog(ctx, op.IssueKey, op.WorklogID)
+		if err == nil && resp.StatusCode() == 404 {
+			return replayDuplicate, nil
+		}
+		if outcome, err := classifyReplay(resp, err, 200); outcome != replayApplied {
+			return outcome, err
+		}
+
+		resp, err = t.sendWorklogDeletion(ctx, op.IssueKey, op.WorklogID)
+		if err == nil && resp.StatusCode() == 404 {
+			return replayDuplicate, nil
+		}
+		outcome, err := classifyReplay(resp, err, 204)
+		if outcome == replayApplied {
+			t.record(&HistoryEntry{Op: OpDeleteWorklog, IssueKey: op.IssueKey, WorklogID: op.WorklogID, Worklog: snapshot})
+		}
+		return outcome, err
+	}
+
+	return replayConflict, &TempooError{Message: fmt.Sprintf("Unknown queued operation '%s'", op.Op)}
+}
+
+// classifyReplay maps a response to a replay outcome, treating the expected status as success
+func classifyReplay(resp *resty.Response, err error, expected int) (replayOutcome, error) {
+	if err != nil {
+		// a request that timed out may have been applied, which the next attempt detects as a duplicate
+		if isTransportError(err) {
+			return replayRetry, &TempooError{Message: "API request failed", Cause: err}
+		}
+		return replayConflict, err
+	}
+
+	switch status := resp.StatusCode(); {
+	case status == expected:
+		return replayApplied, nil
+	case status == 429 || status >= 500:
+		return replayRetry, &TempooError{Message: fmt.Sprintf("Jira responded %s", resp.Status())}
+	default:
+		return replayConflict, &TempooError{Message: fmt.Sprintf("Jira responded %s", resp.Status())}
+	}
+}
+
+// hasMatchingWorklog reports whether the user already has a worklog with the same start minute and duration
+func hasMatchingWorklog(worklogs []map[string]interface{}, userID string, started time.Time, timeSpentSeconds int) bool {
+	for _, worklog := range worklogs {
+		author, ok := worklog["author"].(map[string]interface{})
+		if !ok || author["accountId"] != userID {
+			continue
+		}
+
+		seconds, ok := worklog["timeSpentSeconds"].(float64)
+		if !ok || int(seconds) != timeSpentSeconds {
+			continue
+		}
+
+		startedStr, ok := worklog["started"].(string)
+		if !ok {
+			continue
+		}
+		existing, err := time.Parse(jiraTimestampFormat, startedStr)
+		if err != nil {
+			continue
+		}
+		if existing.Truncate(time.Minute).Equal(started.Truncate(time.Minute)) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncPath is the path whose lock is held while the queue is replayed.
+// It is separate from the journal's own lock, so operations can still be queued during a sync.
+func (q *Queue) syncPath() string {
+	return q.path + ".sync"
+}
+
+// complete removes a replayed operation from the queue
+func (q *Queue) complete(id string) error {
+	_, err := q.Drop([]string{id}, false)
+	return err
+}
+
+// fail records a failed replay attempt, flagging the operation as a conflict if retrying cannot help
+func (q *Queue) fail(failed *QueuedOperation, cause error, conflict bool) error {
+	return q.update(func(ops []*QueuedOperation) ([]*QueuedOperation, error) {
+		for _, op := range ops {
+			if op.ID != failed.ID {
+				continue
+			}
+			op.Attempts = failed.Attempts
+			op.Conflict = conflict
+			if cause != nil {
+				op.LastError = cause.Error()
+			}
+		}
+		return ops, nil
+	})
+}
@@ -0,0 +1,237 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// redirectTransport sends every request to a test server, whatever host it was addressed to
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestTempoo returns a Tempoo whose requests are served by handler
+func newTestTempoo(t *testing.T, handler http.Handler) *Tempoo {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	target, _ := url.Parse(server.URL)
+	client := resty.New().SetTransport(&redirectTransport{target: target})
+	return &Tempoo{email: "test@example.com", apiToken: "test-token", client: client}
+}
+
+func TestSyncQueue(t *testing.T) {
+	syncBackoff = time.Millisecond
+	started := time.Date(2025, 7, 1, 8, 30, 0, 0, time.UTC)
+
+	var posted []string
+	attempts := map[string]int{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/3/myself", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"accountId": "user-1"})
+	})
+	mux.HandleFunc("GET /rest/api/3/issue/{key}/worklog", func(w http.ResponseWriter, r *http.Request) {
+		worklogs := []map[string]interface{}{}
+		if r.PathValue("key") == "DUP-1" {
+			worklogs = append(worklogs, map[string]interface{}{
+				"id":               "100",
+				"author":           map[string]string{"accountId": "user-1"},
+				"started":          started.Format(jiraTimestampFormat),
+				"timeSpentSeconds": 3600,
+			})
+		}
+		if r.PathValue("key") == "GONE-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"worklogs": worklogs})
+	})
+	mux.HandleFunc("POST /rest/api/3/issue/{key}/worklog", func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("key")
+		attempts[key]++
+		// the flaky issue fails once before succeeding
+		if key == "FLAKY-1" && attempts[key] == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		posted = append(posted, key)
+		w.WriteHeader(http.StatusCreated)
+	})
+	// the queued deletion targets a worklog that is already gone
+	mux.HandleFunc("/rest/api/3/issue/{key}/worklog/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	tempoo := newTestTempoo(t, mux)
+	queue := NewQueue(t.TempDir())
+	for _, key := range []string{"NEW-1", "DUP-1", "FLAKY-1", "GONE-1"} {
+		queue.Enqueue(&QueuedOperation{Op: OpAddWorklog, IssueKey: key, Started: started, TimeSpentSeconds: 3600})
+	}
+	queue.Enqueue(&QueuedOperation{Op: OpDeleteWorklog, IssueKey: "NEW-1", WorklogID: "42"})
+
+	result, err := tempoo.SyncQueue(t.Context(), queue)
+	if err != nil {
+		t.Fatalf("SyncQueue() error = %v", err)
+	}
+
+	expected := SyncResult{Applied: 2, Duplicates: 2, Conflicts: 1}
+	if *result != expected {
+		t.Errorf("SyncQueue() = %+v, want %+v", *result, expected)
+	}
+	if strings.Join(posted, ",") != "NEW-1,FLAKY-1" {
+		t.Errorf("Expected NEW-1 and FLAKY-1 to be posted in order, got %v", posted)
+	}
+
+	// only the conflict is left behind, flagged for the user
+	ops, _ := queue.List()
+	if len(ops) != 1 || ops[0].IssueKey != "GONE-1" || !ops[0].Conflict {
+		t.Errorf("Expected GONE-1 to remain as a conflict, got %+v", ops)
+	}
+}
+
+func TestSyncQueue_StopsWhenStillUnreachable(t *testing.T) {
+	syncBackoff = time.Millisecond
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/3/issue/{key}/worklog/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	tempoo := newTestTempoo(t, mux)
+	queue := NewQueue(t.TempDir())
+	queue.Enqueue(&QueuedOperation{Op: OpDeleteWorklog, IssueKey: "TEST-1", WorklogID: "1"})
+	queue.Enqueue(&QueuedOperation{Op: OpDeleteWorklog, IssueKey: "TEST-2", WorklogID: "2"})
+
+	result, err := tempoo.SyncQueue(t.Context(), queue)
+	if err == nil {
+		t.Fatal("Expected sync to fail while Jira keeps erroring")
+	}
+	if result.Pending != 2 {
+		t.Errorf("Expected 2 pending operations, got %d", result.Pending)
+	}
+
+	ops, _ := queue.List()
+	if len(ops) != 2 || ops[0].Attempts != syncAttempts || ops[0].Conflict {
+		t.Errorf("Expected both operations kept with attempts recorded, got %+v", ops[0])
+	}
+}
+
+func TestSyncQueue_Concurrent(t *testing.T) {
+	var posts atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/3/myself", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"accountId": "user-1"})
+	})
+	mux.HandleFunc("GET /rest/api/3/issue/{key}/worklog", func(w http.ResponseWriter, r *http.Request) {
+		// slow enough for both syncs to look for duplicates before either posts
+		time.Sleep(50 * time.Millisecond)
+		json.NewEncoder(w).Encode(map[string]interface{}{"worklogs": []interface{}{}})
+	})
+	mux.HandleFunc("POST /rest/api/3/issue/{key}/worklog", func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	tempoo := newTestTempoo(t, mux)
+	queue := NewQueue(t.TempDir())
+	queue.Enqueue(&QueuedOperation{Op: OpAddWorklog, IssueKey: "TEST-1", Started: time.Now(), TimeSpentSeconds: 3600})
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := tempoo.SyncQueue(t.Context(), queue); err != nil {
+				t.Errorf("SyncQueue() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if posts.Load() != 1 {
+		t.Errorf("Expected the queued worklog to be posted once, got %d", posts.Load())
+	}
+	if ops, _ := queue.List(); len(ops) != 0 {
+		t.Errorf("Expected the queue to be empty, got %+v", ops)
+	}
+}
+
+func TestPostWorklog_QueuesWhenUnreachable(t *testing.T) {
+	queue := NewQueue(t.TempDir())
+
+	// nothing listens on this port, so the request fails in transport
+	client := resty.New().SetTransport(&redirectTransport{target: &url.URL{Scheme: "http", Host: "127.0.0.1:1"}})
+	tempoo := &Tempoo{client: client}
+	tempoo.EnableOfflineQueue(queue)
+
+	worklog, err := tempoo.AddWorklogAt(t.Context(), "TEST-1", time.Now(), time.Hour)
+	if err != nil {
+		t.Fatalf("Expected the worklog to be queued, got %v", err)
+	}
+	if !worklog.Queued || worklog.TimeSpentSeconds != 3600 {
+		t.Errorf("Expected a queued one hour worklog, got %+v", worklog)
+	}
+
+	ops, _ := queue.List()
+	if len(ops) != 1 || ops[0].Op != OpAddWorklog || ops[0].TimeSpentSeconds != 3600 {
+		t.Errorf("Expected a queued add of one hour, got %+v", ops)
+	}
+}
+
+func TestPostWorklog_TimeoutIsNotQueued(t *testing.T) {
+	queue := NewQueue(t.TempDir())
+	// Jira receives the worklog but answers too late, so it may have been added
+	release := make(chan struct{})
+	defer close(release)
+	tempoo := newTestTempoo(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	tempoo.client.SetTimeout(20 * time.Millisecond)
+	tempoo.EnableOfflineQueue(queue)
+
+	if _, err := tempoo.AddWorklogAt(t.Context(), "TEST-1", time.Now(), time.Hour); err == nil {
+		t.Fatal("Expected the timed out request to fail")
+	}
+	if ops, _ := queue.List(); len(ops) != 0 {
+		t.Errorf("Expected nothing queued, got %+v", ops)
+	}
+}
+
+func TestWhoAmIAndSearchIssues(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/3/myself", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"accountId": "user-1", "displayName": "Test User", "emailAddress": "test@example.com"})
+	})
+	mux.HandleFunc("GET /rest/api/3/search/jql", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("jql") != "assignee = currentUser()" || r.URL.Query().Get("maxResults") != "10" {
+			t.Errorf("Unexpected search %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"issues":[{"key":"TEST-1","fields":{"summary":"Test issue","status":{"name":"In Progress"}}}]}`))
+	})
+	tempoo := newTestTempoo(t, mux)
+
+	user, err := tempoo.WhoAmI(t.Context())
+	if err != nil || *user != (User{AccountID: "user-1", DisplayName: "Test User", Email: "test@example.com"}) {
+		t.Errorf("WhoAmI() = %+v, %v", user, err)
+	}
+
+	issues, err := tempoo.SearchIssues(t.Context(), "assignee = currentUser()", 10)
+	if err != nil || len(issues) != 1 || issues[0] != (Issue{Key: "TEST-1", Summary: "Test issue", Status: "In Progress"}) {
+		t.Errorf("SearchIssues() = %+v, %v", issues, err)
+	}
+}
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// weekdayNames maps the accepted names of weekdays in templates to the weekday
+var weekdayNames = map[string]time.Weekday{
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+	"sun": time.Sunday, "sunday": time.Sunday,
+}
+
+// Template is a named set of recurring worklogs, logged for a week with apply-template
+type Template struct {
+	Worklogs []TemplateWorklog `yaml:"worklogs"`
+}
+
+// TemplateWorklog is a worklog of a template, logged on each of its days
+type TemplateWorklog struct {
+	// IssueKey is an issue key or alias
+	IssueKey string  `yaml:"issue_key"`
+	Hours    float64 `yaml:"hours"`
+	// Comment is logged with the worklog
+	Comment string `yaml:"comment"`
+	// Days are the weekdays to log on, such as mon or friday, defaulting to Monday to Friday
+	Days []string `yaml:"days"`
+}
+
+// validateTemplates checks every template worklog has an issue key, a loggable duration and known days
+func validateTemplates(templates map[string]Template) error {
+	for name, template := range templates {
+		for i, worklog := range template.Worklogs {
+			if worklog.IssueKey == "" {
+				return &TempooError{Message: fmt.Sprintf("Worklog %d of template '%s' needs an issue key", i+1, name)}
+			}
+			if err := validateWorklogDuration(time.Duration(worklog.Hours * float64(time.Hour))); err != nil {
+				return &TempooError{Message: fmt.Sprintf("Invalid hours in worklog %d of template '%s'", i+1, name), Cause: err}
+			}
+			for _, day := range worklog.Days {
+				if _, ok := weekdayNames[strings.ToLower(day)]; !ok {
+					return &TempooError{Message: fmt.Sprintf("Invalid day '%s' in worklog %d of template '%s'. Expected a weekday such as mon or monday", day, i+1, name)}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Template returns the template called name
+func (c *Config) Template(name string) (Template, error) {
+	template, ok := c.Templates[name]
+	if !ok {
+		names := make([]string, 0, len(c.Templates))
+		for name := range c.Templates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return Template{}, &TempooError{Message: fmt.Sprintf("Template '%s' is not defined in the config file, which has no templates", name)}
+		}
+		return Template{}, &TempooError{Message: fmt.Sprintf("Template '%s' is not defined in the config file. Available: %s", name, strings.Join(names, ", "))}
+	}
+	return template, nil
+}
+
+// loggedOn reports whether the template worklog is logged on a weekday
+func (w TemplateWorklog) loggedOn(weekday time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return weekday != time.Saturday && weekday != time.Sunday
+	}
+	for _, day := range w.Days {
+		if weekdayNames[strings.ToLower(day)] == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Draft materialises the template's worklogs for each day of a range, by day and in template order
+func (t Template) Draft(days DateRange) *Draft {
+	draft := &Draft{Worklogs: []DraftWorklog{}}
+	for day := days.From; !day.After(days.To); day = day.AddDate(0, 0, 1) {
+		for _, worklog := range t.Worklogs {
+			if !worklog.loggedOn(day.Weekday()) {
+				continue
+			}
+			draft.Worklogs = append(draft.Worklogs, DraftWorklog{
+				Date:     day.Format(draftDateFormat),
+				IssueKey: worklog.IssueKey,
+				Hours:    worklog.Hours,
+				Comment:  worklog.Comment,
+			})
+		}
+	}
+	return draft
+}
+
+// WithoutExisting returns the draft without the worklogs the current user already logged: the same issue on the
+// same day for the same time, and with the same comment when the draft worklog has one. Each logged worklog
+// accounts for one draft worklog, so a draft can be imported again without logging twice.
+func WithoutExisting(ctx context.Context, service WorklogService, draft *Draft) (*Draft, error) {
+	logged := map[string]Worklogs{}
+	remaining := &Draft{Worklogs: []DraftWorklog{}}
+	for _, worklog := range draft.Worklogs {
+		existing, ok := logged[worklog.IssueKey]
+		if !ok {
+			var err error
+			if existing, err = service.ListWorklogs(ctx, worklog.IssueKey); err != nil {
+				return nil, err
+			}
+		}
+
+		if i := matchingWorklog(existing, worklog); i >= 0 {
+			log.Infof("Skipping %s on %s, it is already logged", worklog.IssueKey, worklog.Date)
+			existing = append(existing[:i:i], existing[i+1:]...)
+		} else {
+			remaining.Worklogs = append(remaining.Worklogs, worklog)
+		}
+		logged[worklog.IssueKey] = existing
+	}
+	return remaining, nil
+}
+
+// matchingWorklog returns the index of the logged worklog a draft worklog would duplicate, or -1 if there is none
+func matchingWorklog(existing Worklogs, worklog DraftWorklog) int {
+	seconds := int(worklog.Duration().Seconds())
+	for i, w := range existing {
+		if w.Started.Format(draftDateFormat) != worklog.Date || w.TimeSpentSeconds != seconds {
+			continue
+		}
+		if worklog.Comment != "" && w.Comment != worklog.Comment {
+			continue
+		}
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTemplateDraft(t *testing.T) {
+	template := Template{Worklogs: []TemplateWorklog{
+		{IssueKey: "standup", Hours: 0.25, Comment: "Daily standup"},
+		{IssueKey: "INF-1", Hours: 1, Days: []string{"mon", "Friday"}},
+	}}
+	// a Thursday
+	week, err := ParseWeek("03.07.2025", time.Now())
+	if err != nil {
+		t.Fatalf("ParseWeek failed: %v", err)
+	}
+
+	draft := template.Draft(week)
+
+	var got []string
+	for _, worklog := range draft.Worklogs {
+		got = append(got, worklog.Date+" "+worklog.IssueKey)
+	}
+	want := []string{
+		"30.06.2025 standup", "30.06.2025 INF-1",
+		"01.07.2025 standup", "02.07.2025 standup", "03.07.2025 standup",
+		"04.07.2025 standup", "04.07.2025 INF-1",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+			break
+		}
+	}
+	if draft.Worklogs[0].Comment != "Daily standup" || draft.Worklogs[0].Hours != 0.25 {
+		t.Errorf("Unexpected worklog %+v", draft.Worklogs[0])
+	}
+}
+
+func TestWithoutExisting(t *testing.T) {
+	service := NewMemoryService(User{AccountID: "user-1"}, Issue{Key: "INF-1"}, Issue{Key: "INF-2"})
+	date := "01.07.2025"
+	if _, err := service.AddWorklog(t.Context(), "INF-1", "2", &date); err != nil {
+		t.Fatalf("AddWorklog failed: %v", err)
+	}
+	draft := &Draft{Worklogs: []DraftWorklog{
+		{Date: "01.07.2025", IssueKey: "INF-1", Hours: 2},
+		{Date: "01.07.2025", IssueKey: "INF-1", Hours: 2},
+		{Date: "01.07.2025", IssueKey: "INF-1", Hours: 1},
+		{Date: "02.07.2025", IssueKey: "INF-1", Hours: 2},
+		{Date: "01.07.2025", IssueKey: "INF-2", Hours: 2},
+	}}
+
+	remaining, err := WithoutExisting(t.Context(), service, draft)
+	if err != nil {
+		t.Fatalf("WithoutExisting failed: %v", err)
+	}
+	if len(remaining.Worklogs) != 4 || remaining.Worklogs[1].Hours != 1 || remaining.Worklogs[2].Date != "02.07.2025" || remaining.Worklogs[3].IssueKey != "INF-2" {
+		t.Errorf("Unexpected remaining worklogs %+v", remaining.Worklogs)
+	}
+}
+
+func TestWithoutExisting_Comment(t *testing.T) {
+	service := NewMemoryService(User{AccountID: "user-1"}, Issue{Key: "INF-1"})
+	started := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	if _, err := service.AddWorklogWithComment(t.Context(), "INF-1", started, time.Hour, "Standup"); err != nil {
+		t.Fatalf("AddWorklogWithComment failed: %v", err)
+	}
+	draft := &Draft{Worklogs: []DraftWorklog{
+		{Date: "01.07.2025", IssueKey: "INF-1", Hours: 1, Comment: "Planning"},
+		{Date: "01.07.2025", IssueKey: "INF-1", Hours: 1, Comment: "Standup"},
+	}}
+
+	remaining, err := WithoutExisting(t.Context(), service, draft)
+	if err != nil {
+		t.Fatalf("WithoutExisting failed: %v", err)
+	}
+	if len(remaining.Worklogs) != 1 || remaining.Worklogs[0].Comment != "Planning" {
+		t.Errorf("Unexpected remaining worklogs %+v", remaining.Worklogs)
+	}
+}
+
+func TestLoadConfigFile_InvalidTemplate(t *testing.T) {
+	for _, content := range []string{
+		"templates:\n  week:\n    worklogs:\n      - hours: 1\n",
+		"templates:\n  week:\n    worklogs:\n      - issue_key: INF-1\n",
+		"templates:\n  week:\n    worklogs:\n      - issue_key: INF-1\n        hours: 1\n        days: [someday]\n",
+	} {
+		path := t.TempDir() + "/" + ConfigFileName
+		writeFile(t, path, content)
+		if _, err := LoadConfigFile(path); err == nil {
+			t.Errorf("Expected an error for %q", content)
+		}
+	}
+}
+
+func TestConfigTemplate_Unknown(t *testing.T) {
+	config := DefaultConfig()
+	config.Templates = map[string]Template{"week": {}, "oncall": {}}
+
+	if _, err := config.Template("week"); err != nil {
+		t.Errorf("Template failed: %v", err)
+	}
+	_, err := config.Template("holiday")
+	if err == nil || err.Error() != "Template 'holiday' is not defined in the config file. Available: oncall, week" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -0,0 +1,462 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/go-resty/resty/v2"
+)
+
+// TempoClient keeps worklogs in Tempo through the Tempo Cloud REST API, using Jira to resolve
+// issue keys to the issue IDs Tempo expects and to identify the current user
+type TempoClient struct {
+	jira    *Tempoo
+	client  *resty.Client
+	cache   *tempoCache
+	baseURL string        // root URL of the Tempo API, TempoAPIRootURL when empty
+	logger  log.Interface // logger for the client's messages, the global apex/log logger when nil
+}
+
+// tempoCache holds what a Tempo client looks up once and reuses across requests, which may run concurrently
+type tempoCache struct {
+	mu sync.Mutex
+	// accountID is the current user's account ID, which every worklog written to Tempo needs
+	accountID string
+	// workAttributes, accounts and accountField are the definitions worklog attributes are resolved with;
+	// accountField is nil until looked up, and empty when Jira has no account field
+	workAttributes []tempoWorkAttribute
+	accounts       []tempoAccount
+	accountField   *string
+}
+
+// TempoOptions configures a Tempo client
+type TempoOptions struct {
+	// BaseURL is the root URL of the Tempo REST API, defaulting to TempoAPIRootURL
+	BaseURL string
+	// APIToken is the Tempo bearer token
+	APIToken string
+	// HTTPClient sends the requests, defaulting to a new client
+	HTTPClient *http.Client
+	// Logger receives the client's log messages, defaulting to the global apex/log logger
+	Logger log.Interface
+	// Timeout bounds each request, defaulting to 10 seconds
+	Timeout time.Duration
+}
+
+// tempoWorklog is a worklog as returned by the Tempo API
+type tempoWorklog struct {
+	TempoWorklogID int `json:"tempoWorklogId"`
+	Issue          struct {
+		ID int `json:"id"`
+	} `json:"issue"`
+	TimeSpentSeconds int    `json:"timeSpentSeconds"`
+	StartDate        string `json:"startDate"`
+	StartTime        string `json:"startTime"`
+	Description      string `json:"description"`
+	Author           struct {
+		AccountID string `json:"accountId"`
+	} `json:"author"`
+	Attributes struct {
+		Values []tempoAttributeValue `json:"values"`
+	} `json:"attributes"`
+}
+
+// tempoWorklogPayload is the body of a Tempo worklog create or update request
+type tempoWorklogPayload struct {
+	IssueID          int                   `json:"issueId"`
+	AuthorAccountID  string                `json:"authorAccountId"`
+	TimeSpentSeconds int                   `json:"timeSpentSeconds"`
+	StartDate        string                `json:"startDate"`
+	StartTime        string                `json:"startTime"`
+	Description      string                `json:"description,omitempty"`
+	Attributes       []tempoAttributeValue `json:"attributes,omitempty"`
+}
+
+// tempoPage is one page of a Tempo listing
+type tempoPage[T any] struct {
+	Metadata struct {
+		Next string `json:"next"`
+	} `json:"metadata"`
+	Results []T `json:"results"`
+}
+
+// NewTempoClient creates a client for the Tempo API, reading the bearer token from TEMPO_API_TOKEN
+func NewTempoClient(jira *Tempoo) (*TempoClient, error) {
+	apiToken := os.Getenv("TEMPO_API_TOKEN")
+	if apiToken == "" {
+		return nil, &TempooError{Message: "TEMPO_API_TOKEN environment variable is not set"}
+	}
+	log.Debug("Read TEMPO_API_TOKEN from env")
+
+	return NewTempoClientWithOptions(jira, TempoOptions{APIToken: apiToken, Logger: jira.logger})
+}
+
+// NewTempoClientWithOptions creates a client for the Tempo API from explicit options,
+// using jira to resolve issue keys and identify the current user
+func NewTempoClientWithOptions(jira *Tempoo, opts TempoOptions) (*TempoClient, error) {
+	if opts.APIToken == "" {
+		return nil, &TempooError{Message: "Tempo API token is required"}
+	}
+
+	client := newRestyClient(opts.HTTPClient, opts.Timeout)
+	client.SetAuthToken(opts.APIToken)
+
+	c := &TempoClient{
+		jira:    jira,
+		client:  client,
+		cache:   &tempoCache{},
+		baseURL: strings.TrimSuffix(opts.BaseURL, "/"),
+		logger:  opts.Logger,
+	}
+	logRequests(client, c.log)
+
+	log.Debug("Tempo client initialized")
+	return c, nil
+}
+
+// request starts a Tempo API request bound to ctx
+func (c *TempoClient) request(ctx context.Context) *resty.Request {
+	return c.client.R().SetContext(ctx)
+}
+
+// apiRoot returns the root URL of the Tempo API the client talks to
+func (c *TempoClient) apiRoot() string {
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	return TempoAPIRootURL
+}
+
+// log returns the client's logger
+func (c *TempoClient) log() log.Interface {
+	if c.logger != nil {
+		return c.logger
+	}
+	return log.Log
+}
+
+// GetUserAccountID returns the Atlassian account ID of the current user, as known to Jira
+func (c *TempoClient) GetUserAccountID(ctx context.Context) (string, error) {
+	c.cache.mu.Lock()
+	accountID := c.cache.accountID
+	c.cache.mu.Unlock()
+	if accountID != "" {
+		return accountID, nil
+	}
+
+	accountID, err := c.jira.GetUserAccountID(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.cache.mu.Lock()
+	c.cache.accountID = accountID
+	c.cache.mu.Unlock()
+	return accountID, nil
+}
+
+// WhoAmI returns the current user, as known to Jira
+func (c *TempoClient) WhoAmI(ctx context.Context) (*User, error) {
+	return c.jira.WhoAmI(ctx)
+}
+
+// SearchIssues returns up to max Jira issues matching a JQL query
+func (c *TempoClient) SearchIssues(ctx context.Context, jql string, max int) (Issues, error) {
+	return c.jira.SearchIssues(ctx, jql, max)
+}
+
+// GetIssue returns a Jira issue by key
+func (c *TempoClient) GetIssue(ctx context.Context, issueKey string) (*Issue, error) {
+	return c.jira.GetIssue(ctx, issueKey)
+}
+
+// GetWorklogs returns the IDs of a user's Tempo worklogs on an issue
+func (c *TempoClient) GetWorklogs(ctx context.Context, issueKey, userID string) ([]string, error) {
+	c.log().Infof("Getting worklogs for %s", issueKey)
+
+	worklogs, err := c.issueWorklogs(ctx, issueKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var worklogIDs []string
+	for _, worklog := range worklogs {
+		if worklog.Author.AccountID == userID {
+			worklogIDs = append(worklogIDs, strconv.Itoa(worklog.TempoWorklogID))
+		}
+	}
+
+	c.log().Debugf("Found %d worklogs for user %s", len(worklogIDs), userID)
+	return worklogIDs, nil
+}
+
+// AddWorklog logs hours to an issue in Tempo, starting at 08:30 on the given DD.MM.YYYY date or today
+func (c *TempoClient) AddWorklog(ctx context.Context, issueKey, worklogTime string, dateStr *string) (*Worklog, error) {
+	return c.AddWorklogWithAttributes(ctx, issueKey, worklogTime, dateStr, "", nil)
+}
+
+// AddWorklogAt logs a duration to an issue in Tempo, starting at the given time
+func (c *TempoClient) AddWorklogAt(ctx context.Context, issueKey string, started time.Time, duration time.Duration) (*Worklog, error) {
+	return c.AddWorklogWithComment(ctx, issueKey, started, duration, "")
+}
+
+// AddWorklogWithComment logs a duration to an issue in Tempo, starting at the given time, with the comment as description
+func (c *TempoClient) AddWorklogWithComment(ctx context.Context, issueKey string, started time.Time, duration time.Duration, comment string) (*Worklog, error) {
+	c.log().Infof("Adding worklog to %s", issueKey)
+
+	if err := validateWorklogDuration(duration); err != nil {
+		return nil, err
+	}
+
+	return c.postWorklog(ctx, issueKey, started, duration, comment, "", nil)
+}
+
+// postWorklog creates a Tempo worklog for the current user with the given description, account and work attributes
+func (c *TempoClient) postWorklog(ctx context.Context, issueKey string, started time.Time, duration time.Duration, description, account string, attributes map[string]string) (*Worklog, error) {
+	issueID, err := c.issueID(ctx, issueKey)
+	if err != nil {
+		return nil, err
+	}
+
+	accountID, err := c.GetUserAccountID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	attributeValues, err := c.resolveAttributes(ctx, issueKey, account, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := tempoWorklogPayload{
+		IssueID:          issueID,
+		AuthorAccountID:  accountID,
+		TimeSpentSeconds: int(duration.Seconds()),
+		StartDate:        started.Format(tempoDateFormat),
+		StartTime:        started.Format(tempoTimeFormat),
+		Description:      description,
+		Attributes:       attributeValues,
+	}
+
+	resp, err := c.request(ctx).SetBody(payload).Post(fmt.Sprintf("%s/worklogs", c.apiRoot()))
+	if err != nil {
+		c.log().Errorf("Request failed: %v", err)
+		return nil, &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() != 200 && resp.StatusCode() != 201 {
+		return nil, newAPIError("Failed to add worklog", resp)
+	}
+
+	var created tempoWorklog
+	if err := json.Unmarshal(resp.Body(), &created); err != nil {
+		return nil, &TempooError{Message: "Failed to parse worklog data", Cause: err}
+	}
+
+	c.log().Infof("Added worklog of %s to %s", convertHoursToJiraFormat(duration.Hours()), issueKey)
+	worklog := created.toWorklog(issueKey)
+	return &worklog, nil
+}
+
+// ListWorklogs returns the current user's Tempo worklogs on an issue
+func (c *TempoClient) ListWorklogs(ctx context.Context, issueKey string) (Worklogs, error) {
+	c.log().Infof("Listing worklogs for %s", issueKey)
+
+	worklogs, err := c.issueWorklogs(ctx, issueKey)
+	if err != nil {
+		return nil, err
+	}
+
+	accountID, err := c.GetUserAccountID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userWorklogs := Worklogs{}
+	for _, worklog := range worklogs {
+		if worklog.Author.AccountID == accountID {
+			userWorklogs = append(userWorklogs, worklog.toWorklog(issueKey))
+		}
+	}
+
+	if len(userWorklogs) == 0 {
+		c.log().Infof("No worklogs found for current user in %s", issueKey)
+	}
+	return userWorklogs, nil
+}
+
+// UpdateWorklog changes the hours and/or date of a Tempo worklog, leaving unset values as they are.
+// A new date keeps the original start time of day.
+func (c *TempoClient) UpdateWorklog(ctx context.Context, issueKey, worklogID, worklogTime string, dateStr *string) (*Worklog, error) {
+	duration, date, err := parseWorklogUpdate(worklogTime, dateStr)
+	if err != nil {
+		return nil, err
+	}
+	return c.UpdateWorklogDuration(ctx, issueKey, worklogID, duration, date)
+}
+
+// UpdateWorklogDuration changes the duration and/or day of a Tempo worklog, leaving zero values as they are
+func (c *TempoClient) UpdateWorklogDuration(ctx context.Context, issueKey, worklogID string, duration time.Duration, date time.Time) (*Worklog, error) {
+	if duration != 0 {
+		if err := validateWorklogDuration(duration); err != nil {
+			return nil, err
+		}
+	}
+	c.log().Infof("Updating worklog %s on %s", worklogID, issueKey)
+
+	resp, err := c.request(ctx).Get(fmt.Sprintf("%s/worklogs/%s", c.apiRoot(), worklogID))
+	if err != nil {
+		c.log().Errorf("Request failed: %v", err)
+		return nil, &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() != 200 {
+		return nil, newAPIError(fmt.Sprintf("Failed to get worklog %s", worklogID), resp)
+	}
+
+	var previous tempoWorklog
+	if err := json.Unmarshal(resp.Body(), &previous); err != nil {
+		return nil, &TempooError{Message: "Failed to parse worklog data", Cause: err}
+	}
+
+	// Tempo replaces the whole worklog, so start from its current values
+	payload := tempoWorklogPayload{
+		IssueID:          previous.Issue.ID,
+		AuthorAccountID:  previous.Author.AccountID,
+		TimeSpentSeconds: previous.TimeSpentSeconds,
+		StartDate:        previous.StartDate,
+		StartTime:        previous.StartTime,
+		Description:      previous.Description,
+		Attributes:       previous.Attributes.Values,
+	}
+
+	if duration != 0 {
+		payload.TimeSpentSeconds = int(duration.Seconds())
+	}
+
+	if !date.IsZero() {
+		payload.StartDate = date.Format(tempoDateFormat)
+	}
+
+	resp, err = c.request(ctx).SetBody(payload).Put(fmt.Sprintf("%s/worklogs/%s", c.apiRoot(), worklogID))
+	if err != nil {
+		c.log().Errorf("Request failed: %v", err)
+		return nil, &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() != 200 {
+		return nil, newAPIError("Failed to update worklog", resp)
+	}
+
+	var updated tempoWorklog
+	if err := json.Unmarshal(resp.Body(), &updated); err != nil {
+		return nil, &TempooError{Message: "Failed to parse worklog data", Cause: err}
+	}
+
+	c.log().Infof("Updated worklog %s on %s", worklogID, issueKey)
+	worklog := updated.toWorklog(issueKey)
+	return &worklog, nil
+}
+
+// DeleteWorklog deletes a Tempo worklog
+func (c *TempoClient) DeleteWorklog(ctx context.Context, issueKey, worklogID string) error {
+	c.log().Debugf("Deleting worklog %s for %s", worklogID, issueKey)
+
+	resp, err := c.request(ctx).Delete(fmt.Sprintf("%s/worklogs/%s", c.apiRoot(), worklogID))
+	if err != nil {
+		c.log().Errorf("Request failed: %v", err)
+		return &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() != 204 {
+		return newAPIError("Failed to delete worklog", resp)
+	}
+
+	c.log().Infof("Deleted worklog %s for %s", worklogID, issueKey)
+	return nil
+}
+
+// issueID resolves an issue key to the numeric issue ID Tempo identifies issues by
+func (c *TempoClient) issueID(ctx context.Context, issueKey string) (int, error) {
+	resp, err := c.jira.request(ctx).
+		SetQueryParam("fields", "id").
+		Get(fmt.Sprintf("%s/issue/%s", c.jira.apiRoot(), issueKey))
+	if err != nil {
+		c.log().Errorf("Request failed: %v", err)
+		return 0, &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() == 404 {
+		return 0, &InvalidIssueKeyError{IssueKey: issueKey}
+	}
+	if resp.StatusCode() != 200 {
+		return 0, newAPIError(fmt.Sprintf("Failed to validate issue key %s", issueKey), resp)
+	}
+
+	var issue struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(resp.Body(), &issue); err != nil {
+		return 0, &TempooError{Message: "Failed to parse issue data", Cause: err}
+	}
+
+	id, err := strconv.Atoi(issue.ID)
+	if err != nil {
+		return 0, &TempooError{Message: fmt.Sprintf("Unexpected ID '%s' for issue %s", issue.ID, issueKey), Cause: err}
+	}
+	c.log().Debugf("Issue %s has ID %d", issueKey, id)
+	return id, nil
+}
+
+// issueWorklogs returns every Tempo worklog on an issue, following the pagination links
+func (c *TempoClient) issueWorklogs(ctx context.Context, issueKey string) ([]tempoWorklog, error) {
+	issueID, err := c.issueID(ctx, issueKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTempoPages[tempoWorklog](ctx, c, fmt.Sprintf("%s/worklogs/issue/%d", c.apiRoot(), issueID), "Failed to list worklogs")
+}
+
+// getTempoPages fetches every result of a Tempo listing, following the pagination links
+func getTempoPages[T any](ctx context.Context, c *TempoClient, url, failure string) ([]T, error) {
+	var results []T
+	for next := url; next != ""; {
+		resp, err := c.request(ctx).Get(next)
+		if err != nil {
+			c.log().Errorf("Request failed: %v", err)
+			return nil, &TempooError{Message: "API request failed", Cause: err}
+		}
+		if resp.StatusCode() != 200 {
+			return nil, newAPIError(failure, resp)
+		}
+
+		var page tempoPage[T]
+		if err := json.Unmarshal(resp.Body(), &page); err != nil {
+			return nil, &TempooError{Message: "Failed to parse Tempo response", Cause: err}
+		}
+		results = append(results, page.Results...)
+		next = page.Metadata.Next
+	}
+	return results, nil
+}
+
+// toWorklog converts a Tempo worklog into the shape tempoo outputs
+func (w tempoWorklog) toWorklog(issueKey string) Worklog {
+	worklog := Worklog{
+		ID:               strconv.Itoa(w.TempoWorklogID),
+		IssueKey:         issueKey,
+		AuthorAccountID:  w.Author.AccountID,
+		TimeSpentSeconds: w.TimeSpentSeconds,
+		TimeSpent:        formatTimeSpent(w.TimeSpentSeconds),
+		Comment:          w.Description,
+	}
+
+	// Tempo keeps the start as a local date and time without a zone
+	if started, err := time.ParseInLocation(tempoDateFormat+" "+tempoTimeFormat, w.StartDate+" "+w.StartTime, time.Local); err == nil {
+		worklog.Started = started
+	}
+	return worklog
+}
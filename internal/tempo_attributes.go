@@ -0,0 +1,367 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tempo work attribute types
+const (
+	tempoAttributeAccount    = "ACCOUNT"
+	tempoAttributeCheckbox   = "CHECKBOX"
+	tempoAttributeStaticList = "STATIC_LIST"
+	tempoAttributeNumeric    = "INPUT_NUMERIC"
+)
+
+const (
+	// tempoAccountFieldType is the schema type of the Jira custom field linking an issue to a Tempo account
+	tempoAccountFieldType = "io.tempo.jira__account"
+	// tempoAccountOpen is the status of accounts that accept new worklogs
+	tempoAccountOpen = "OPEN"
+)
+
+// tempoWorkAttribute is the definition of a Tempo work attribute
+type tempoWorkAttribute struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	// Values are the allowed values of a static list, with Names holding their display names
+	Values []string          `json:"values"`
+	Names  map[string]string `json:"names"`
+}
+
+// tempoAccount is a Tempo account time can be billed to
+type tempoAccount struct {
+	ID     int    `json:"id"`
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// tempoAttributeValue is the value of a work attribute on a worklog
+type tempoAttributeValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// AddWorklogWithAttributes logs hours to an issue in Tempo like AddWorklog, billing it to account and
+// setting work attributes given by key or name. Without an account, the account linked to the issue is used
+// whenever attributes are sent, that is when some are given or Tempo requires one.
+func (c *TempoClient) AddWorklogWithAttributes(ctx context.Context, issueKey, worklogTime string, dateStr *string, account string, attributes map[string]string) (*Worklog, error) {
+	c.log().Infof("Adding worklog to %s", issueKey)
+
+	hours, err := validateWorklogHours(worklogTime)
+	if err != nil {
+		return nil, err
+	}
+
+	started, err := worklogStart(dateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.postWorklog(ctx, issueKey, started, time.Duration(hours*float64(time.Hour)), "", account, attributes)
+}
+
+// resolveAttributes validates the requested account and work attributes against Tempo and returns
+// them as worklog attribute values, defaulting the account to the one linked to the issue. A worklog
+// without an account or attributes gets none, sparing the lookups, unless Tempo requires an attribute.
+func (c *TempoClient) resolveAttributes(ctx context.Context, issueKey, account string, attributes map[string]string) ([]tempoAttributeValue, error) {
+	definitions, err := c.workAttributeDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if account == "" && len(attributes) == 0 && !hasRequiredAttribute(definitions) {
+		return nil, nil
+	}
+
+	values := map[string]string{}
+	for _, name := range sortedKeys(attributes) {
+		definition, err := findWorkAttribute(definitions, name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := c.validateAttributeValue(ctx, definition, attributes[name])
+		if err != nil {
+			return nil, err
+		}
+		values[definition.Key] = value
+	}
+
+	// the account is itself a work attribute in Tempo
+	var accountAttribute *tempoWorkAttribute
+	for i := range definitions {
+		if definitions[i].Type == tempoAttributeAccount {
+			accountAttribute = &definitions[i]
+			break
+		}
+	}
+
+	if account != "" {
+		if accountAttribute == nil {
+			return nil, &TempooError{Message: "Tempo has no account work attribute, so worklogs cannot be billed to an account"}
+		}
+		key, err := c.validateAccount(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		values[accountAttribute.Key] = key
+	} else if accountAttribute != nil && values[accountAttribute.Key] == "" {
+		key, err := c.issueAccount(ctx, issueKey)
+		if err != nil {
+			return nil, err
+		}
+		if key != "" {
+			c.log().Infof("Billing to account %s linked to %s", key, issueKey)
+			values[accountAttribute.Key] = key
+		}
+	}
+
+	for _, definition := range definitions {
+		if definition.Required && values[definition.Key] == "" {
+			return nil, &TempooError{Message: fmt.Sprintf("Work attribute '%s' is required. Set it with --attribute '%s=...'", definition.Name, definition.Name)}
+		}
+	}
+
+	result := []tempoAttributeValue{}
+	for _, key := range sortedKeys(values) {
+		result = append(result, tempoAttributeValue{Key: key, Value: values[key]})
+	}
+	return result, nil
+}
+
+// workAttributeDefinitions returns the work attributes defined in Tempo, fetched once per client
+func (c *TempoClient) workAttributeDefinitions(ctx context.Context) ([]tempoWorkAttribute, error) {
+	c.cache.mu.Lock()
+	cached := c.cache.workAttributes
+	c.cache.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	definitions, err := getTempoPages[tempoWorkAttribute](ctx, c, fmt.Sprintf("%s/work-attributes", c.apiRoot()), "Failed to get work attributes")
+	if err != nil {
+		return nil, err
+	}
+	definitions = append([]tempoWorkAttribute{}, definitions...)
+	c.cache.mu.Lock()
+	c.cache.workAttributes = definitions
+	c.cache.mu.Unlock()
+	return definitions, nil
+}
+
+// hasRequiredAttribute reports whether any work attribute must be set on every worklog
+func hasRequiredAttribute(definitions []tempoWorkAttribute) bool {
+	for _, definition := range definitions {
+		if definition.Required {
+			return true
+		}
+	}
+	return false
+}
+
+// tempoAccounts returns the accounts defined in Tempo, fetched once per client
+func (c *TempoClient) tempoAccounts(ctx context.Context) ([]tempoAccount, error) {
+	c.cache.mu.Lock()
+	cached := c.cache.accounts
+	c.cache.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	accounts, err := getTempoPages[tempoAccount](ctx, c, fmt.Sprintf("%s/accounts", c.apiRoot()), "Failed to get accounts")
+	if err != nil {
+		return nil, err
+	}
+	accounts = append([]tempoAccount{}, accounts...)
+	c.cache.mu.Lock()
+	c.cache.accounts = accounts
+	c.cache.mu.Unlock()
+	return accounts, nil
+}
+
+// findWorkAttribute returns the work attribute with the given key or, case-insensitively, name
+func findWorkAttribute(definitions []tempoWorkAttribute, name string) (tempoWorkAttribute, error) {
+	var available []string
+	for _, definition := range definitions {
+		if definition.Key == name || strings.EqualFold(definition.Name, name) {
+			return definition, nil
+		}
+		available = append(available, definition.Name)
+	}
+	return tempoWorkAttribute{}, &TempooError{Message: fmt.Sprintf("Unknown work attribute '%s'. Expected one of: %s", name, strings.Join(available, ", "))}
+}
+
+// validateAttributeValue checks a value against the type of its work attribute, returning the value Tempo expects
+func (c *TempoClient) validateAttributeValue(ctx context.Context, definition tempoWorkAttribute, value string) (string, error) {
+	switch definition.Type {
+	case tempoAttributeAccount:
+		return c.validateAccount(ctx, value)
+
+	case tempoAttributeCheckbox:
+		checked, err := strconv.ParseBool(value)
+		switch strings.ToLower(value) {
+		case "yes":
+			checked, err = true, nil
+		case "no":
+			checked, err = false, nil
+		}
+		if err != nil {
+			return "", &TempooError{Message: fmt.Sprintf("Invalid value '%s' for work attribute '%s'. Expected true or false", value, definition.Name)}
+		}
+		return strconv.FormatBool(checked), nil
+
+	case tempoAttributeNumeric:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", &TempooError{Message: fmt.Sprintf("Invalid value '%s' for work attribute '%s'. Expected a number", value, definition.Name)}
+		}
+		return value, nil
+
+	case tempoAttributeStaticList:
+		// accept either the stored value or its display name
+		for _, allowed := range definition.Values {
+			if allowed == value || strings.EqualFold(definition.Names[allowed], value) {
+				return allowed, nil
+			}
+		}
+		var allowed []string
+		for _, v := range definition.Values {
+			if name := definition.Names[v]; name != "" {
+				allowed = append(allowed, name)
+			} else {
+				allowed = append(allowed, v)
+			}
+		}
+		return "", &TempooError{Message: fmt.Sprintf("Invalid value '%s' for work attribute '%s'. Expected one of: %s", value, definition.Name, strings.Join(allowed, ", "))}
+	}
+
+	return value, nil
+}
+
+// validateAccount checks that an account key exists and is open, returning the key
+func (c *TempoClient) validateAccount(ctx context.Context, key string) (string, error) {
+	accounts, err := c.tempoAccounts(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, account := range accounts {
+		if !strings.EqualFold(account.Key, key) {
+			continue
+		}
+		if account.Status != tempoAccountOpen {
+			return "", &TempooError{Message: fmt.Sprintf("Account %s (%s) is %s and does not accept worklogs", account.Key, account.Name, strings.ToLower(account.Status))}
+		}
+		return account.Key, nil
+	}
+	return "", &TempooError{Message: fmt.Sprintf("Unknown account '%s'", key)}
+}
+
+// issueAccount returns the key of the Tempo account linked to an issue, or an empty string if there is none
+func (c *TempoClient) issueAccount(ctx context.Context, issueKey string) (string, error) {
+	fieldID, err := c.accountFieldID(ctx)
+	if err != nil || fieldID == "" {
+		return "", err
+	}
+
+	resp, err := c.jira.request(ctx).
+		SetQueryParam("fields", fieldID).
+		Get(fmt.Sprintf("%s/issue/%s", c.jira.apiRoot(), issueKey))
+	if err != nil {
+		c.log().Errorf("Request failed: %v", err)
+		return "", &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() != 200 {
+		return "", newAPIError(fmt.Sprintf("Failed to get account of issue %s", issueKey), resp)
+	}
+
+	var issue struct {
+		Fields map[string]*struct {
+			ID int `json:"id"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(resp.Body(), &issue); err != nil {
+		return "", &TempooError{Message: "Failed to parse issue data", Cause: err}
+	}
+	linked := issue.Fields[fieldID]
+	if linked == nil {
+		return "", nil
+	}
+
+	accounts, err := c.tempoAccounts(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, account := range accounts {
+		if account.ID == linked.ID {
+			return account.Key, nil
+		}
+	}
+	c.log().Warnf("Account %d linked to %s was not found in Tempo", linked.ID, issueKey)
+	return "", nil
+}
+
+// accountFieldID returns the ID of the Jira custom field holding an issue's Tempo account,
+// or an empty string if Jira has no such field. It is looked up once per client.
+func (c *TempoClient) accountFieldID(ctx context.Context) (string, error) {
+	c.cache.mu.Lock()
+	cached := c.cache.accountField
+	c.cache.mu.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+
+	fieldID, err := c.lookupAccountFieldID(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.cache.mu.Lock()
+	c.cache.accountField = &fieldID
+	c.cache.mu.Unlock()
+	return fieldID, nil
+}
+
+// lookupAccountFieldID finds the Jira custom field holding an issue's Tempo account
+func (c *TempoClient) lookupAccountFieldID(ctx context.Context) (string, error) {
+	resp, err := c.jira.request(ctx).Get(fmt.Sprintf("%s/field", c.jira.apiRoot()))
+	if err != nil {
+		c.log().Errorf("Request failed: %v", err)
+		return "", &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() != 200 {
+		return "", newAPIError("Failed to get Jira fields", resp)
+	}
+
+	var fields []struct {
+		ID     string `json:"id"`
+		Schema struct {
+			Custom string `json:"custom"`
+		} `json:"schema"`
+	}
+	if err := json.Unmarshal(resp.Body(), &fields); err != nil {
+		return "", &TempooError{Message: "Failed to parse Jira fields", Cause: err}
+	}
+
+	for _, field := range fields {
+		if field.Schema.Custom == tempoAccountFieldType {
+			return field.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// sortedKeys returns the keys of a map in order, so requests are built deterministically
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,326 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// newTestTempoClient returns a TempoClient whose Jira and Tempo requests are both served by handler
+func newTestTempoClient(t *testing.T, handler http.Handler) *TempoClient {
+	jira := newTestTempoo(t, handler)
+	client := resty.New().SetTransport(jira.client.GetClient().Transport)
+	return &TempoClient{jira: jira, client: client, cache: &tempoCache{}}
+}
+
+// newTempoMux serves the Jira endpoints the Tempo client depends on
+func newTempoMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/3/myself", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"accountId": "user-1"})
+	})
+	mux.HandleFunc("GET /rest/api/3/issue/{key}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("key") != "TEST-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     "10001",
+			"key":    "TEST-1",
+			"fields": map[string]interface{}{"customfield_10020": map[string]interface{}{"id": 1, "value": "Client A"}},
+		})
+	})
+	mux.HandleFunc("GET /rest/api/3/field", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":"summary","schema":{"type":"string"}},{"id":"customfield_10020","schema":{"custom":"io.tempo.jira__account"}}]`))
+	})
+	mux.HandleFunc("GET /4/work-attributes", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results":[
+			{"key":"_Account_","name":"Account","type":"ACCOUNT","required":false},
+			{"key":"_Billable_","name":"Billable","type":"CHECKBOX","required":false},
+			{"key":"_Activity_","name":"Activity type","type":"STATIC_LIST","required":false,"values":["dev","qa"],"names":{"dev":"Development","qa":"QA"}}
+		]}`))
+	})
+	mux.HandleFunc("GET /4/accounts", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results":[
+			{"id":1,"key":"ACC","name":"Client A","status":"OPEN"},
+			{"id":2,"key":"OLD","name":"Old client","status":"CLOSED"}
+		]}`))
+	})
+	return mux
+}
+
+func TestTempoClient_AddWorklog(t *testing.T) {
+	var payload tempoWorklogPayload
+	mux := newTempoMux()
+	mux.HandleFunc("POST /4/worklogs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			t.Error("Expected an Authorization header")
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tempoWorklogId":   42,
+			"issue":            map[string]int{"id": payload.IssueID},
+			"timeSpentSeconds": payload.TimeSpentSeconds,
+			"startDate":        payload.StartDate,
+			"startTime":        payload.StartTime,
+			"author":           map[string]string{"accountId": payload.AuthorAccountID},
+		})
+	})
+	client := newTestTempoClient(t, mux)
+	client.client.SetAuthToken("tempo-token")
+
+	date := "01.07.2025"
+	worklog, err := client.AddWorklog(t.Context(), "TEST-1", "1.5", &date)
+	if err != nil {
+		t.Fatalf("AddWorklog() error = %v", err)
+	}
+
+	// no work attribute is required, so none are sent
+	expected := tempoWorklogPayload{
+		IssueID: 10001, AuthorAccountID: "user-1", TimeSpentSeconds: 5400, StartDate: "2025-07-01", StartTime: "08:30:00",
+	}
+	if !reflect.DeepEqual(payload, expected) {
+		t.Errorf("Payload = %+v, want %+v", payload, expected)
+	}
+	if worklog.ID != "42" || worklog.IssueKey != "TEST-1" || worklog.TimeSpent != "1h 30m" {
+		t.Errorf("Unexpected worklog %+v", worklog)
+	}
+}
+
+func TestTempoClient_AddWorklogWithAttributes(t *testing.T) {
+	tests := []struct {
+		name        string
+		account     string
+		attributes  map[string]string
+		expected    []tempoAttributeValue
+		expectError string
+	}{
+		{
+			name:       "account and attributes by name",
+			account:    "acc",
+			attributes: map[string]string{"billable": "yes", "Activity type": "QA"},
+			expected:   []tempoAttributeValue{{Key: "_Account_", Value: "ACC"}, {Key: "_Activity_", Value: "qa"}, {Key: "_Billable_", Value: "true"}},
+		},
+		{
+			name:       "attribute by key",
+			attributes: map[string]string{"_Activity_": "dev"},
+			expected:   []tempoAttributeValue{{Key: "_Account_", Value: "ACC"}, {Key: "_Activity_", Value: "dev"}},
+		},
+		{
+			name:        "closed account",
+			account:     "OLD",
+			expectError: "Account OLD (Old client) is closed and does not accept worklogs",
+		},
+		{
+			name:        "unknown account",
+			account:     "NOPE",
+			expectError: "Unknown account 'NOPE'",
+		},
+		{
+			name:        "unknown attribute",
+			attributes:  map[string]string{"Colour": "red"},
+			expectError: "Unknown work attribute 'Colour'. Expected one of: Account, Billable, Activity type",
+		},
+		{
+			name:        "value outside static list",
+			attributes:  map[string]string{"Activity type": "Sleeping"},
+			expectError: "Invalid value 'Sleeping' for work attribute 'Activity type'. Expected one of: Development, QA",
+		},
+		{
+			name:        "invalid checkbox",
+			attributes:  map[string]string{"Billable": "maybe"},
+			expectError: "Invalid value 'maybe' for work attribute 'Billable'. Expected true or false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload tempoWorklogPayload
+			mux := newTempoMux()
+			mux.HandleFunc("POST /4/worklogs", func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&payload)
+				json.NewEncoder(w).Encode(map[string]interface{}{"tempoWorklogId": 42})
+			})
+			client := newTestTempoClient(t, mux)
+
+			_, err := client.AddWorklogWithAttributes(t.Context(), "TEST-1", "1", nil, tt.account, tt.attributes)
+			if tt.expectError != "" {
+				if err == nil || err.Error() != tt.expectError {
+					t.Errorf("Expected error %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddWorklogWithAttributes() error = %v", err)
+			}
+			if !reflect.DeepEqual(payload.Attributes, tt.expected) {
+				t.Errorf("Attributes = %+v, want %+v", payload.Attributes, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTempoClient_AddWorklog_RequiredAccount(t *testing.T) {
+	var payload tempoWorklogPayload
+	requests := map[string]int{}
+	mux := http.NewServeMux()
+	mux.Handle("/", newTempoMux())
+	mux.HandleFunc("GET /4/work-attributes", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Write([]byte(`{"results":[{"key":"_Account_","name":"Account","type":"ACCOUNT","required":true}]}`))
+	})
+	mux.HandleFunc("GET /rest/api/3/field", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Write([]byte(`[{"id":"customfield_10020","schema":{"custom":"io.tempo.jira__account"}}]`))
+	})
+	mux.HandleFunc("GET /4/accounts", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Write([]byte(`{"results":[{"id":1,"key":"ACC","name":"Client A","status":"OPEN"}]}`))
+	})
+	mux.HandleFunc("POST /4/worklogs", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		json.NewEncoder(w).Encode(map[string]interface{}{"tempoWorklogId": 42, "issue": map[string]int{"id": payload.IssueID}})
+	})
+	client := newTestTempoClient(t, mux)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.AddWorklog(t.Context(), "TEST-1", "1", nil); err != nil {
+			t.Fatalf("AddWorklog() error = %v", err)
+		}
+		// the account linked to the issue fills the required account
+		if !reflect.DeepEqual(payload.Attributes, []tempoAttributeValue{{Key: "_Account_", Value: "ACC"}}) {
+			t.Errorf("Attributes = %+v", payload.Attributes)
+		}
+	}
+	// the definitions are fetched once per client
+	for path, count := range requests {
+		if count != 1 {
+			t.Errorf("Expected 1 request to %s, got %d", path, count)
+		}
+	}
+}
+
+func TestTempoClient_AddWorklog_InvalidIssueKey(t *testing.T) {
+	client := newTestTempoClient(t, newTempoMux())
+
+	_, err := client.AddWorklog(t.Context(), "NOPE-1", "1", nil)
+	if _, ok := err.(*InvalidIssueKeyError); !ok {
+		t.Errorf("Expected InvalidIssueKeyError, got %v", err)
+	}
+}
+
+func TestTempoClient_ListWorklogs(t *testing.T) {
+	mux := newTempoMux()
+	mux.HandleFunc("GET /4/worklogs/issue/10001", func(w http.ResponseWriter, r *http.Request) {
+		page := map[string]interface{}{
+			"results": []map[string]interface{}{
+				{"tempoWorklogId": 1, "timeSpentSeconds": 3600, "startDate": "2025-07-01", "startTime": "09:00:00", "author": map[string]string{"accountId": "user-1"}},
+				{"tempoWorklogId": 2, "timeSpentSeconds": 3600, "startDate": "2025-07-01", "startTime": "10:00:00", "author": map[string]string{"accountId": "user-2"}},
+			},
+		}
+		if r.URL.Query().Get("offset") == "" {
+			page["metadata"] = map[string]string{"next": TempoAPIRootURL + "/worklogs/issue/10001?offset=2"}
+		} else {
+			page["results"] = []map[string]interface{}{
+				{"tempoWorklogId": 3, "timeSpentSeconds": 1800, "startDate": "2025-07-02", "startTime": "08:30:00", "author": map[string]string{"accountId": "user-1"}},
+			}
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+	client := newTestTempoClient(t, mux)
+
+	worklogs, err := client.ListWorklogs(t.Context(), "TEST-1")
+	if err != nil {
+		t.Fatalf("ListWorklogs() error = %v", err)
+	}
+	if len(worklogs) != 2 || worklogs[0].ID != "1" || worklogs[1].ID != "3" {
+		t.Fatalf("Expected the current user's worklogs from both pages, got %+v", worklogs)
+	}
+
+	expectedStart := time.Date(2025, 7, 2, 8, 30, 0, 0, time.Local)
+	if !worklogs[1].Started.Equal(expectedStart) {
+		t.Errorf("Started = %v, want %v", worklogs[1].Started, expectedStart)
+	}
+}
+
+func TestTempoClient_UpdateWorklog(t *testing.T) {
+	var payload tempoWorklogPayload
+	mux := newTempoMux()
+	mux.HandleFunc("GET /4/worklogs/42", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tempoWorklogId":   42,
+			"issue":            map[string]int{"id": 10001},
+			"timeSpentSeconds": 3600,
+			"startDate":        "2025-07-01",
+			"startTime":        "09:15:00",
+			"description":      "Working on the issue",
+			"author":           map[string]string{"accountId": "user-1"},
+			"attributes":       map[string]interface{}{"values": []map[string]string{{"key": "_Billable_", "value": "true"}}},
+		})
+	})
+	mux.HandleFunc("PUT /4/worklogs/42", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		json.NewEncoder(w).Encode(map[string]interface{}{"tempoWorklogId": 42, "timeSpentSeconds": payload.TimeSpentSeconds})
+	})
+	client := newTestTempoClient(t, mux)
+
+	date := "03.07.2025"
+	if _, err := client.UpdateWorklog(t.Context(), "TEST-1", "42", "", &date); err != nil {
+		t.Fatalf("UpdateWorklog() error = %v", err)
+	}
+
+	expected := tempoWorklogPayload{
+		IssueID: 10001, AuthorAccountID: "user-1", TimeSpentSeconds: 3600, StartDate: "2025-07-03", StartTime: "09:15:00",
+		Description: "Working on the issue", Attributes: []tempoAttributeValue{{Key: "_Billable_", Value: "true"}},
+	}
+	if !reflect.DeepEqual(payload, expected) {
+		t.Errorf("Payload = %+v, want %+v", payload, expected)
+	}
+}
+
+func TestTempoClient_DeleteWorklog(t *testing.T) {
+	mux := newTempoMux()
+	mux.HandleFunc("DELETE /4/worklogs/42", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /4/worklogs/43", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[{"message":"Worklog not found"}]}`))
+	})
+	mux.HandleFunc("DELETE /4/worklogs/44", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	client := newTestTempoClient(t, mux)
+
+	if err := client.DeleteWorklog(t.Context(), "TEST-1", "42"); err != nil {
+		t.Errorf("DeleteWorklog() error = %v", err)
+	}
+
+	err := client.DeleteWorklog(t.Context(), "TEST-1", "43")
+	if _, ok := err.(*NotFoundError); !ok {
+		t.Fatalf("Expected NotFoundError, got %v", err)
+	}
+	if err.Error() != "Failed to delete worklog: 404 Not Found: Worklog not found" {
+		t.Errorf("Unexpected error message %q", err.Error())
+	}
+
+	// a rejected Tempo token is not blamed on the Jira credentials
+	err = client.DeleteWorklog(t.Context(), "TEST-1", "44")
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || authErr.Hint != tempoStatusHints[http.StatusUnauthorized] {
+		t.Errorf("Expected an AuthError with the Tempo hint, got %v", err)
+	}
+}
+
+func TestNewTempoClient_MissingToken(t *testing.T) {
+	t.Setenv("TEMPO_API_TOKEN", "")
+
+	if _, err := NewTempoClient(&Tempoo{}); err == nil {
+		t.Error("Expected error when TEMPO_API_TOKEN is not set")
+	}
+}
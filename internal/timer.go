@@ -0,0 +1,287 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// TimersFileName is the name of the running timers state file inside the tempoo home directory
+const TimersFileName = "timers.json"
+
+// timerStopStaleAfter is the age after which a stop that never finished, e.g. because its process was killed,
+// may be taken over by another stop
+const timerStopStaleAfter = 2 * time.Minute
+
+// Timer is a running or paused stopwatch for a single Jira issue
+type Timer struct {
+	IssueKey  string    `json:"issue_key"`
+	StartedAt time.Time `json:"started_at"`
+	// ResumedAt is when the current run began, nil while the timer is paused
+	ResumedAt *time.Time `json:"resumed_at,omitempty"`
+	// Accumulated is the time recorded by runs that ended with a pause
+	Accumulated time.Duration `json:"accumulated"`
+	// Stopping is set while a stop is logging the timer, so another stop cannot log it twice
+	Stopping *TimerStop `json:"stopping,omitempty"`
+}
+
+// TimerStop records which process is stopping a timer and since when, so an interrupted stop can be taken over
+type TimerStop struct {
+	PID   int       `json:"pid"`
+	Since time.Time `json:"since"`
+}
+
+// Paused reports whether the timer is currently paused
+func (t *Timer) Paused() bool {
+	return t.ResumedAt == nil
+}
+
+// Elapsed returns the total running time of the timer up to now, excluding paused periods
+func (t *Timer) Elapsed(now time.Time) time.Duration {
+	if t.Paused() {
+		return t.Accumulated
+	}
+	return t.Accumulated + now.Sub(*t.ResumedAt)
+}
+
+// TimerStatus describes a timer at a point in time, in the shape tempoo outputs it
+type TimerStatus struct {
+	IssueKey       string    `json:"issue_key"`
+	State          string    `json:"state"`
+	StartedAt      time.Time `json:"started_at"`
+	ElapsedSeconds int       `json:"elapsed_seconds"`
+}
+
+// TimerStatuses is a list of timer statuses
+type TimerStatuses []TimerStatus
+
+// Status describes the timer as of now
+func (t *Timer) Status(now time.Time) TimerStatus {
+	state := "running"
+	if t.Paused() {
+		state = "paused"
+	}
+	return TimerStatus{
+		IssueKey:       t.IssueKey,
+		State:          state,
+		StartedAt:      t.StartedAt,
+		ElapsedSeconds: int(t.Elapsed(now).Seconds()),
+	}
+}
+
+// TimerStore persists timers in a JSON file guarded by a lock file, so concurrent invocations are safe
+type TimerStore struct {
+	path string
+}
+
+// NewTimerStore creates a timer store backed by the timers file in dir
+func NewTimerStore(dir string) *TimerStore {
+	return &TimerStore{path: filepath.Join(dir, TimersFileName)}
+}
+
+// Start starts a new timer for issueKey
+func (s *TimerStore) Start(issueKey string, now time.Time) (*Timer, error) {
+	var timer *Timer
+	err := s.update(func(timers map[string]*Timer) error {
+		if _, ok := timers[issueKey]; ok {
+			return &TempooError{Message: fmt.Sprintf("A timer is already running for %s", issueKey)}
+		}
+		timer = &Timer{IssueKey: issueKey, StartedAt: now, ResumedAt: &now}
+		timers[issueKey] = timer
+		return nil
+	})
+	return timer, err
+}
+
+// Pause pauses the timer for issueKey, or the only timer if issueKey is empty
+func (s *TimerStore) Pause(issueKey string, now time.Time) (*Timer, error) {
+	var timer *Timer
+	err := s.update(func(timers map[string]*Timer) error {
+		var err error
+		if timer, err = selectTimer(timers, issueKey); err != nil {
+			return err
+		}
+		if timer.Paused() {
+			return &TempooError{Message: fmt.Sprintf("Timer for %s is already paused", timer.IssueKey)}
+		}
+		timer.Accumulated = timer.Elapsed(now)
+		timer.ResumedAt = nil
+		return nil
+	})
+	return timer, err
+}
+
+// Resume resumes the paused timer for issueKey, or the only timer if issueKey is empty
+func (s *TimerStore) Resume(issueKey string, now time.Time) (*Timer, error) {
+	var timer *Timer
+	err := s.update(func(timers map[string]*Timer) error {
+		var err error
+		if timer, err = selectTimer(timers, issueKey); err != nil {
+			return err
+		}
+		if !timer.Paused() {
+			return &TempooError{Message: fmt.Sprintf("Timer for %s is not paused", timer.IssueKey)}
+		}
+		timer.ResumedAt = &now
+		return nil
+	})
+	return timer, err
+}
+
+// Stop stops the timer for issueKey, or the only timer if issueKey is empty, and hands it to commit.
+// The timer is only removed if commit succeeds, so a failed upload can be retried. commit runs without
+// holding the lock, so slow requests do not hold up other invocations; the timer is marked as stopping meanwhile.
+// A stop that has not finished after timerStopStaleAfter was interrupted, and the next stop takes it over.
+func (s *TimerStore) Stop(issueKey string, now time.Time, commit func(*Timer) error) (*Timer, error) {
+	var stopped Timer
+	mark := &TimerStop{PID: os.Getpid(), Since: now}
+	err := s.update(func(timers map[string]*Timer) error {
+		timer, err := selectTimer(timers, issueKey)
+		if err != nil {
+			return err
+		}
+		if timer.Stopping != nil {
+			if now.Sub(timer.Stopping.Since) <= timerStopStaleAfter {
+				return &TempooError{Message: fmt.Sprintf("Timer for %s is already being stopped by process %d. If that stop was interrupted, stop the timer again after %s",
+					timer.IssueKey, timer.Stopping.PID, timer.Stopping.Since.Add(timerStopStaleAfter).Format("15:04:05"))}
+			}
+			log.Warnf("Taking over the interrupted stop of the timer for %s by process %d. Check its worklogs in case that stop logged it already",
+				timer.IssueKey, timer.Stopping.PID)
+		}
+
+		timer.Stopping = mark
+		stopped = *timer
+		stopped.Accumulated = timer.Elapsed(now)
+		stopped.ResumedAt = nil
+		stopped.Stopping = nil
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	commitErr := commit(&stopped)
+	err = s.update(func(timers map[string]*Timer) error {
+		timer, ok := timers[stopped.IssueKey]
+		if !ok || !timer.StartedAt.Equal(stopped.StartedAt) {
+			return nil
+		}
+		if commitErr != nil {
+			// leave the mark of a stop that took this one over
+			if timer.Stopping != nil && timer.Stopping.PID == mark.PID && timer.Stopping.Since.Equal(mark.Since) {
+				timer.Stopping = nil
+			}
+			return nil
+		}
+		delete(timers, timer.IssueKey)
+		return nil
+	})
+	if commitErr != nil {
+		return nil, commitErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stopped, nil
+}
+
+// Cancel discards the timer for issueKey, or the only timer if issueKey is empty, without logging it
+func (s *TimerStore) Cancel(issueKey string) (*Timer, error) {
+	var timer *Timer
+	err := s.update(func(timers map[string]*Timer) error {
+		var err error
+		if timer, err = selectTimer(timers, issueKey); err != nil {
+			return err
+		}
+		delete(timers, timer.IssueKey)
+		return nil
+	})
+	return timer, err
+}
+
+// List returns all timers ordered by start time
+func (s *TimerStore) List() ([]*Timer, error) {
+	var list []*Timer
+	err := withFileLock(s.path, func() error {
+		timers, err := s.read()
+		if err != nil {
+			return err
+		}
+		for _, timer := range timers {
+			list = append(list, timer)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].StartedAt.Before(list[j].StartedAt) })
+	return list, nil
+}
+
+// update loads the timers, applies fn and writes them back, all while holding the lock
+func (s *TimerStore) update(fn func(map[string]*Timer) error) error {
+	return withFileLock(s.path, func() error {
+		timers, err := s.read()
+		if err != nil {
+			return err
+		}
+
+		if err := fn(timers); err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(timers, "", "  ")
+		if err != nil {
+			return &TempooError{Message: "Failed to encode timers", Cause: err}
+		}
+		log.Debugf("Saving %d timer(s) to %s", len(timers), s.path)
+		return writeFileAtomic(s.path, data)
+	})
+}
+
+// read loads the timers file, returning an empty set if it does not exist yet
+func (s *TimerStore) read() (map[string]*Timer, error) {
+	timers := map[string]*Timer{}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return timers, nil
+	}
+	if err != nil {
+		return nil, &TempooError{Message: "Failed to read timers", Cause: err}
+	}
+
+	if err := json.Unmarshal(data, &timers); err != nil {
+		return nil, &TempooError{Message: fmt.Sprintf("Failed to parse timers file %s", s.path), Cause: err}
+	}
+	return timers, nil
+}
+
+// selectTimer picks the timer for issueKey, or the only timer when issueKey is empty
+func selectTimer(timers map[string]*Timer, issueKey string) (*Timer, error) {
+	if issueKey != "" {
+		timer, ok := timers[issueKey]
+		if !ok {
+			return nil, &TempooError{Message: fmt.Sprintf("No timer running for %s", issueKey)}
+		}
+		return timer, nil
+	}
+
+	switch len(timers) {
+	case 0:
+		return nil, &TempooError{Message: "No timers running"}
+	case 1:
+		for _, timer := range timers {
+			return timer, nil
+		}
+	}
+	return nil, &TempooError{Message: fmt.Sprintf("%d timers running, specify an issue key", len(timers))}
+}
@@ -0,0 +1,228 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTimer_Elapsed(t *testing.T) {
+	start := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	resumed := start.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		timer    Timer
+		now      time.Time
+		expected time.Duration
+	}{
+		{
+			name:     "running timer",
+			timer:    Timer{IssueKey: "TEST-1", StartedAt: start, ResumedAt: &start},
+			now:      start.Add(45 * time.Minute),
+			expected: 45 * time.Minute,
+		},
+		{
+			name:     "paused timer ignores now",
+			timer:    Timer{IssueKey: "TEST-1", StartedAt: start, Accumulated: 20 * time.Minute},
+			now:      start.Add(5 * time.Hour),
+			expected: 20 * time.Minute,
+		},
+		{
+			name:     "resumed timer adds accumulated time",
+			timer:    Timer{IssueKey: "TEST-1", StartedAt: start, ResumedAt: &resumed, Accumulated: 30 * time.Minute},
+			now:      resumed.Add(15 * time.Minute),
+			expected: 45 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.timer.Elapsed(tt.now); got != tt.expected {
+				t.Errorf("Elapsed() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTimerStore_Lifecycle(t *testing.T) {
+	store := NewTimerStore(t.TempDir())
+	start := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	if _, err := store.Start("TEST-1", start); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// starting the same issue twice is rejected
+	if _, err := store.Start("TEST-1", start); err == nil {
+		t.Error("Expected error starting a second timer for the same issue")
+	}
+
+	if _, err := store.Pause("", start.Add(30*time.Minute)); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if _, err := store.Pause("", start.Add(40*time.Minute)); err == nil {
+		t.Error("Expected error pausing a paused timer")
+	}
+	if _, err := store.Resume("TEST-1", start.Add(time.Hour)); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+
+	var committed *Timer
+	stopped, err := store.Stop("", start.Add(90*time.Minute), func(timer *Timer) error {
+		committed = timer
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if committed == nil || committed.Accumulated != time.Hour {
+		t.Errorf("Expected one hour committed, got %+v", committed)
+	}
+	if !stopped.StartedAt.Equal(start) {
+		t.Errorf("Expected stopped timer to keep start %s, got %s", start, stopped.StartedAt)
+	}
+
+	timers, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(timers) != 0 {
+		t.Errorf("Expected no timers after stop, got %d", len(timers))
+	}
+}
+
+func TestTimerStore_StopKeepsTimerOnCommitFailure(t *testing.T) {
+	store := NewTimerStore(t.TempDir())
+	start := time.Now()
+
+	if _, err := store.Start("TEST-1", start); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	commitErr := errors.New("api unavailable")
+	_, err := store.Stop("TEST-1", start.Add(time.Hour), func(*Timer) error { return commitErr })
+	if !errors.Is(err, commitErr) {
+		t.Errorf("Expected commit error, got %v", err)
+	}
+
+	timers, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(timers) != 1 || timers[0].Paused() || timers[0].Stopping != nil {
+		t.Errorf("Expected the running timer to survive a failed stop, got %+v", timers)
+	}
+}
+
+func TestTimerStore_StopCommitsWithoutLock(t *testing.T) {
+	store := NewTimerStore(t.TempDir())
+	start := time.Now()
+	if _, err := store.Start("TEST-1", start); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	_, err := store.Stop("TEST-1", start.Add(time.Hour), func(*Timer) error {
+		// other invocations can use the store while the worklog is being logged
+		timers, err := store.List()
+		if err != nil || len(timers) != 1 || timers[0].Stopping == nil {
+			t.Errorf("Expected the timer to be listed as stopping, got %+v, %v", timers, err)
+		}
+		if _, err := store.Stop("TEST-1", start.Add(time.Hour), func(*Timer) error { return nil }); err == nil {
+			t.Error("Expected a second stop to be refused")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if timers, _ := store.List(); len(timers) != 0 {
+		t.Errorf("Expected the timer to be removed, got %+v", timers)
+	}
+}
+
+func TestTimerStore_StopTakesOverInterruptedStop(t *testing.T) {
+	store := NewTimerStore(t.TempDir())
+	start := time.Now()
+	if _, err := store.Start("TEST-1", start); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// a stop killed while logging leaves its mark behind
+	store.update(func(timers map[string]*Timer) error {
+		timers["TEST-1"].Stopping = &TimerStop{PID: 1, Since: start.Add(time.Hour)}
+		return nil
+	})
+
+	if _, err := store.Stop("TEST-1", start.Add(time.Hour+time.Minute), func(*Timer) error { return nil }); err == nil {
+		t.Error("Expected a stop to be refused while the other one may still be running")
+	}
+
+	logged := 0
+	_, err := store.Stop("TEST-1", start.Add(time.Hour+timerStopStaleAfter+time.Minute), func(*Timer) error {
+		logged++
+		return nil
+	})
+	if err != nil || logged != 1 {
+		t.Fatalf("Stop() = %v, logged %d times, want the stale stop taken over", err, logged)
+	}
+	if timers, _ := store.List(); len(timers) != 0 {
+		t.Errorf("Expected the timer to be removed, got %+v", timers)
+	}
+}
+
+func TestTimerStore_SelectTimer(t *testing.T) {
+	store := NewTimerStore(t.TempDir())
+
+	// nothing to select
+	if _, err := store.Cancel(""); err == nil {
+		t.Error("Expected error cancelling with no timers")
+	}
+
+	store.Start("TEST-1", time.Now())
+	store.Start("TEST-2", time.Now())
+
+	// ambiguous without an issue key
+	if _, err := store.Pause("", time.Now()); err == nil {
+		t.Error("Expected error pausing with several timers and no issue key")
+	}
+	if _, err := store.Cancel("TEST-3"); err == nil {
+		t.Error("Expected error cancelling an unknown timer")
+	}
+
+	timer, err := store.Cancel("TEST-2")
+	if err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if timer.IssueKey != "TEST-2" {
+		t.Errorf("Expected TEST-2 to be cancelled, got %s", timer.IssueKey)
+	}
+}
+
+func TestTimerStore_ConcurrentStarts(t *testing.T) {
+	store := NewTimerStore(t.TempDir())
+
+	// every concurrent start must survive the read-modify-write cycle
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := store.Start(fmt.Sprintf("TEST-%d", i), time.Now()); err != nil {
+				t.Errorf("Start() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	timers, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(timers) != 20 {
+		t.Errorf("Expected 20 timers, got %d", len(timers))
+	}
+}
@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timesheet is the approval state of a user's timesheet for one Tempo approval period
+type Timesheet struct {
+	// From and To are the first and last day of the period, as YYYY-MM-DD
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Status is open, in_review or approved
+	Status          string `json:"status"`
+	RequiredSeconds int    `json:"required_seconds"`
+	LoggedSeconds   int    `json:"logged_seconds"`
+	// ReviewerAccountID and Reviewer identify who approves the timesheet, if anyone is assigned
+	ReviewerAccountID string `json:"reviewer_account_id"`
+	Reviewer          string `json:"reviewer"`
+}
+
+// MissingSeconds returns how much time is still to be logged to meet the required time
+func (t *Timesheet) MissingSeconds() int {
+	if t.LoggedSeconds >= t.RequiredSeconds {
+		return 0
+	}
+	return t.RequiredSeconds - t.LoggedSeconds
+}
+
+// ParsePeriodDate parses a day identifying an approval period, either a DD.MM.YYYY date or a
+// YYYY-MM month, meaning its first day. An empty value means today.
+func ParsePeriodDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	if month, err := time.ParseInLocation("2006-01", value, time.Local); err == nil {
+		return month, nil
+	}
+	date, err := parseDateString(value)
+	if err != nil {
+		return time.Time{}, &TempooError{Message: fmt.Sprintf("Invalid period '%s'. Expected a DD.MM.YYYY date or a YYYY-MM month", value)}
+	}
+	return date, nil
+}
+
+// tempoTimesheetApproval is a timesheet approval as returned by the Tempo API
+type tempoTimesheetApproval struct {
+	Period struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"period"`
+	RequiredSeconds  int `json:"requiredSeconds"`
+	TimeSpentSeconds int `json:"timeSpentSeconds"`
+	Status           struct {
+		Key string `json:"key"`
+	} `json:"status"`
+	Reviewer *struct {
+		AccountID string `json:"accountId"`
+	} `json:"reviewer"`
+}
+
+// tempoApprovalPeriod is a Tempo timesheet approval period
+type tempoApprovalPeriod struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Timesheet returns the current user's timesheet for the approval period containing date
+func (c *TempoClient) Timesheet(ctx context.Context, date time.Time) (*Timesheet, error) {
+	path, period, err := c.timesheetPath(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.request(ctx).
+		SetQueryParams(map[string]string{"from": period.From, "to": period.To}).
+		Get(path)
+	if err != nil {
+		c.log().Errorf("Request failed: %v", err)
+		return nil, &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() != 200 {
+		return nil, newAPIError("Failed to get timesheet", resp)
+	}
+	return c.parseTimesheet(ctx, resp.Body())
+}
+
+// SubmitTimesheet submits the current user's timesheet for the period containing date for approval.
+// Without a reviewer account ID, Tempo routes it to the reviewer already assigned.
+func (c *TempoClient) SubmitTimesheet(ctx context.Context, date time.Time, comment, reviewerAccountID string) (*Timesheet, error) {
+	body := map[string]string{}
+	if comment != "" {
+		body["comment"] = comment
+	}
+	if reviewerAccountID != "" {
+		body["reviewerAccountId"] = reviewerAccountID
+	}
+	return c.timesheetAction(ctx, date, "submit", body)
+}
+
+// ReopenTimesheet reopens the current user's submitted or approved timesheet for the period containing date
+func (c *TempoClient) ReopenTimesheet(ctx context.Context, date time.Time, comment string) (*Timesheet, error) {
+	body := map[string]string{}
+	if comment != "" {
+		body["comment"] = comment
+	}
+	return c.timesheetAction(ctx, date, "reopen", body)
+}
+
+// timesheetAction applies a timesheet approval action and returns the resulting timesheet
+func (c *TempoClient) timesheetAction(ctx context.Context, date time.Time, action string, body map[string]string) (*Timesheet, error) {
+	path, period, err := c.timesheetPath(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.request(ctx).
+		SetQueryParams(map[string]string{"from": period.From, "to": period.To}).
+		SetBody(body).
+		Post(path + "/" + action)
+	if err != nil {
+		c.log().Errorf("Request failed: %v", err)
+		return nil, &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() != 200 {
+		return nil, newAPIError(fmt.Sprintf("Failed to %s timesheet", action), resp)
+	}
+
+	return c.parseTimesheet(ctx, resp.Body())
+}
+
+// timesheetPath returns the approval URL of the current user's timesheet and the approval period containing date
+func (c *TempoClient) timesheetPath(ctx context.Context, date time.Time) (string, tempoApprovalPeriod, error) {
+	day := date.Format(tempoDateFormat)
+	resp, err := c.request(ctx).
+		SetQueryParams(map[string]string{"from": day, "to": day}).
+		Get(fmt.Sprintf("%s/timesheet-approvals/periods", c.apiRoot()))
+	if err != nil {
+		c.log().Errorf("Request failed: %v", err)
+		return "", tempoApprovalPeriod{}, &TempooError{Message: "API request failed", Cause: err}
+	}
+	if resp.StatusCode() != 200 {
+		return "", tempoApprovalPeriod{}, newAPIError("Failed to get approval periods", resp)
+	}
+
+	var periods struct {
+		Periods []tempoApprovalPeriod `json:"periods"`
+	}
+	if err := json.Unmarshal(resp.Body(), &periods); err != nil {
+		return "", tempoApprovalPeriod{}, &TempooError{Message: "Failed to parse approval periods", Cause: err}
+	}
+	if len(periods.Periods) == 0 {
+		return "", tempoApprovalPeriod{}, &TempooError{Message: fmt.Sprintf("No approval period contains %s", date.Format("02.01.2006"))}
+	}
+	period := periods.Periods[0]
+	c.log().Debugf("Approval period for %s is %s to %s", day, period.From, period.To)
+
+	accountID, err := c.GetUserAccountID(ctx)
+	if err != nil {
+		return "", tempoApprovalPeriod{}, err
+	}
+	return fmt.Sprintf("%s/timesheet-approvals/user/%s", c.apiRoot(), accountID), period, nil
+}
+
+// parseTimesheet converts a Tempo timesheet approval into a Timesheet, looking up the reviewer's name
+func (c *TempoClient) parseTimesheet(ctx context.Context, body []byte) (*Timesheet, error) {
+	var approval tempoTimesheetApproval
+	if err := json.Unmarshal(body, &approval); err != nil {
+		return nil, &TempooError{Message: "Failed to parse timesheet", Cause: err}
+	}
+
+	timesheet := &Timesheet{
+		From:            approval.Period.From,
+		To:              approval.Period.To,
+		Status:          strings.ToLower(approval.Status.Key),
+		RequiredSeconds: approval.RequiredSeconds,
+		LoggedSeconds:   approval.TimeSpentSeconds,
+	}
+	if approval.Reviewer != nil {
+		timesheet.ReviewerAccountID = approval.Reviewer.AccountID
+		timesheet.Reviewer = c.jira.displayName(ctx, approval.Reviewer.AccountID)
+	}
+	return timesheet, nil
+}
+
+// displayName returns the display name of a Jira user, falling back to the account ID if it cannot be looked up
+func (t *Tempoo) displayName(ctx context.Context, accountID string) string {
+	resp, err := t.request(ctx).
+		SetQueryParam("accountId", accountID).
+		Get(fmt.Sprintf("%s/user", t.apiRoot()))
+	if err != nil || resp.StatusCode() != 200 {
+		t.log().Debugf("Could not look up user %s", accountID)
+		return accountID
+	}
+
+	var user struct {
+		DisplayName string `json:"displayName"`
+	}
+	if err := json.Unmarshal(resp.Body(), &user); err != nil || user.DisplayName == "" {
+		return accountID
+	}
+	return user.DisplayName
+}
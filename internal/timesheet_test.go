@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTimesheetMux serves a monthly approval period for July 2025 on top of the Tempo test endpoints
+func newTimesheetMux(t *testing.T) *http.ServeMux {
+	mux := newTempoMux()
+	mux.HandleFunc("GET /4/timesheet-approvals/periods", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("from") != "2025-07-15" {
+			t.Errorf("Expected period lookup for 2025-07-15, got %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"periods":[{"from":"2025-07-01","to":"2025-07-31"}]}`))
+	})
+	mux.HandleFunc("GET /rest/api/3/user", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"accountId": r.URL.Query().Get("accountId"), "displayName": "Jane Reviewer"})
+	})
+	return mux
+}
+
+func TestTempoClient_Timesheet(t *testing.T) {
+	mux := newTimesheetMux(t)
+	mux.HandleFunc("GET /4/timesheet-approvals/user/user-1", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("from") != "2025-07-01" || r.URL.Query().Get("to") != "2025-07-31" {
+			t.Errorf("Unexpected period %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"period":{"from":"2025-07-01","to":"2025-07-31"},"requiredSeconds":576000,"timeSpentSeconds":540000,
+			"status":{"key":"OPEN"},"reviewer":{"accountId":"reviewer-1"}}`))
+	})
+	client := newTestTempoClient(t, mux)
+
+	timesheet, err := client.Timesheet(t.Context(), time.Date(2025, 7, 15, 0, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("Timesheet() error = %v", err)
+	}
+
+	expected := Timesheet{From: "2025-07-01", To: "2025-07-31", Status: "open", RequiredSeconds: 576000, LoggedSeconds: 540000, ReviewerAccountID: "reviewer-1", Reviewer: "Jane Reviewer"}
+	if *timesheet != expected {
+		t.Errorf("Timesheet() = %+v, want %+v", *timesheet, expected)
+	}
+	if timesheet.MissingSeconds() != 36000 {
+		t.Errorf("MissingSeconds() = %d, want 36000", timesheet.MissingSeconds())
+	}
+	if text := timesheet.Text(); text != "Timesheet 2025-07-01 to 2025-07-31: open\nLogged 150h of 160h required (10h missing)\nReviewer: Jane Reviewer" {
+		t.Errorf("Unexpected text %q", text)
+	}
+}
+
+func TestTempoClient_SubmitTimesheet(t *testing.T) {
+	var body map[string]string
+	mux := newTimesheetMux(t)
+	mux.HandleFunc("POST /4/timesheet-approvals/user/user-1/submit", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"period":{"from":"2025-07-01","to":"2025-07-31"},"status":{"key":"IN_REVIEW"}}`))
+	})
+	client := newTestTempoClient(t, mux)
+
+	timesheet, err := client.SubmitTimesheet(t.Context(), time.Date(2025, 7, 15, 0, 0, 0, 0, time.Local), "All done", "reviewer-2")
+	if err != nil {
+		t.Fatalf("SubmitTimesheet() error = %v", err)
+	}
+	if timesheet.Status != "in_review" {
+		t.Errorf("Status = %q, want in_review", timesheet.Status)
+	}
+	if body["comment"] != "All done" || body["reviewerAccountId"] != "reviewer-2" {
+		t.Errorf("Unexpected submit body %+v", body)
+	}
+}
+
+func TestTempoClient_ReopenTimesheet_Rejected(t *testing.T) {
+	mux := newTimesheetMux(t)
+	mux.HandleFunc("POST /4/timesheet-approvals/user/user-1/reopen", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errors":[{"message":"Timesheet is already open"}]}`))
+	})
+	client := newTestTempoClient(t, mux)
+
+	_, err := client.ReopenTimesheet(t.Context(), time.Date(2025, 7, 15, 0, 0, 0, 0, time.Local), "")
+	if _, ok := err.(*ValidationError); !ok {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+}
+
+func TestParsePeriodDate(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    time.Time
+		expectError bool
+	}{
+		{value: "2025-07", expected: time.Date(2025, 7, 1, 0, 0, 0, 0, time.Local)},
+		{value: "15.07.2025", expected: time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)},
+		{value: "July", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParsePeriodDate(tt.value)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil || !got.Equal(tt.expected) {
+				t.Errorf("ParsePeriodDate() = %v, %v, want %v", got, err, tt.expected)
+			}
+		})
+	}
+}
@@ -1,14 +1,67 @@
 package internal
 
-import "github.com/go-resty/resty/v2"
+import (
+	"time"
+
+	"github.com/apex/log"
+	"github.com/go-resty/resty/v2"
+)
 
 // type aliases for better readability
 type JiraResponse map[string]interface{}
 type WorklogData map[string]interface{}
 
+// worklog operations, as recorded in the offline queue and the history journal
+const (
+	OpAddWorklog    = "add"
+	OpEditWorklog   = "edit"
+	OpDeleteWorklog = "delete"
+)
+
 // tempoo client struct
 type Tempoo struct {
 	email    string
 	apiToken string
 	client   *resty.Client // resty client for making HTTP requests to the Jira API
+	queue    *Queue        // offline queue for changes made while Jira is unreachable, nil when disabled
+	history  *History      // journal of performed changes for undo, nil when disabled
+	cache    *Cache        // cache of the current user and known issue keys, nil when disabled
+	baseURL  string        // root URL of the Jira API, JiraAPIRootURL when empty
+	logger   log.Interface // logger for the client's messages, the global apex/log logger when nil
+	pace     func()        // called before each request to keep to a rate limit, nil when unlimited
+}
+
+// Worklog is a worklog entry on a Jira issue, in the shape tempoo outputs it
+type Worklog struct {
+	ID               string    `json:"id"`
+	IssueKey         string    `json:"issue_key"`
+	AuthorAccountID  string    `json:"author_account_id"`
+	AuthorName       string    `json:"author"`
+	Started          time.Time `json:"started"`
+	TimeSpentSeconds int       `json:"time_spent_seconds"`
+	TimeSpent        string    `json:"time_spent"`
+	Comment          string    `json:"comment,omitempty"`
+	// Queued is set when the change was queued because Jira was unreachable, in which case ID is empty
+	Queued bool `json:"queued,omitempty"`
+}
+
+// Worklogs is a list of worklogs
+type Worklogs []Worklog
+
+// User is a Jira user, in the shape tempoo outputs it
+type User struct {
+	AccountID   string `json:"account_id"`
+	DisplayName string `json:"display_name"`
+	Email       string `json:"email"`
+	TimeZone    string `json:"time_zone,omitempty"`
 }
+
+// Issue is a Jira issue found by a search, in the shape tempoo outputs it
+type Issue struct {
+	Key     string `json:"key"`
+	Summary string `json:"summary"`
+	Status  string `json:"status"`
+}
+
+// Issues is a list of issues
+type Issues []Issue
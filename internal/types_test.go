@@ -105,6 +105,12 @@ func TestTempooStruct(t *testing.T) {
 		{"email", "string"},
 		{"apiToken", "string"},
 		{"client", "*resty.Client"},
+		{"queue", "*internal.Queue"},
+		{"history", "*internal.History"},
+		{"cache", "*internal.Cache"},
+		{"baseURL", "string"},
+		{"logger", "log.Interface"},
+		{"pace", "func()"},
 	}
 
 	if tempooType.NumField() != len(expectedFields) {
@@ -0,0 +1,33 @@
+// Package tempoo is a Go client for logging work to Jira issues, directly in Jira or through Tempo.
+//
+// It shares its implementation with the tempoo CLI. Create one with New and functional options:
+//
+//	client, err := tempoo.New(
+//		tempoo.WithBaseURL("https://example.atlassian.net"),
+//		tempoo.WithBasicAuth(email, apiToken),
+//		tempoo.WithTimeout(30*time.Second),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	worklog, err := client.AddWorklog(ctx, "PROJ-123", time.Now(), 90*time.Minute)
+//
+// Worklogs go to Jira unless a Tempo token is given with WithTempoToken, in which case they
+// are written to Tempo and the timesheet methods become available.
+//
+// # Errors
+//
+// Errors are *TempooError values, which wrap their cause such as context.Canceled, or types
+// built on it. Failed API calls return one of the following, each wrapping an *APIError with the status code, the messages the
+// server returned and a hint on how to fix the problem where one is known:
+//
+//   - *AuthError for 401, when the credentials are missing or invalid
+//   - *PermissionError for 403
+//   - *NotFoundError for 404
+//   - *RateLimitError for 429, with how long to wait before retrying
+//   - *ValidationError for other 4xx responses, when the request was rejected
+//   - *ServerError for 5xx responses
+//
+// An unknown issue key yields an *InvalidIssueKeyError, and timesheet methods on a client
+// without Tempo return ErrTempoRequired. Use errors.As and errors.Is to tell them apart.
+package tempoo
@@ -0,0 +1,150 @@
+package tempoo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"tempoo/internal"
+)
+
+// TempooError is the base of every error the client returns, carrying a message and its cause
+type TempooError struct {
+	Message string
+	Cause   error
+}
+
+// Error returns the message, followed by the cause if there is one
+func (e *TempooError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Cause)
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause, such as a context cancellation or a network error
+func (e *TempooError) Unwrap() error {
+	return e.Cause
+}
+
+// InvalidIssueKeyError is returned when an issue key does not exist or is not visible to the user
+type InvalidIssueKeyError struct {
+	IssueKey string
+}
+
+// Error returns the error message
+func (e *InvalidIssueKeyError) Error() string {
+	return fmt.Sprintf("Issue key %s is not valid", e.IssueKey)
+}
+
+// APIError is a failed API call, with the status code, the server's messages and a hint if one is known.
+// The typed errors below wrap it.
+type APIError struct {
+	TempooError
+	StatusCode int
+	// ErrorMessages are the general messages of the error response
+	ErrorMessages []string
+	// FieldErrors are the per-field messages of the error response
+	FieldErrors map[string]string
+	// Hint is advice on how to fix the failure, if one is known
+	Hint string
+}
+
+// Unwrap returns the underlying TempooError
+func (e *APIError) Unwrap() error {
+	return &e.TempooError
+}
+
+// AuthError is returned for 401 responses
+type AuthError struct{ APIError }
+
+// PermissionError is returned for 403 responses
+type PermissionError struct{ APIError }
+
+// NotFoundError is returned for 404 responses
+type NotFoundError struct{ APIError }
+
+// RateLimitError is returned for 429 responses
+type RateLimitError struct {
+	APIError
+	// RetryAfter is how long the server asked to wait, or zero if it did not say
+	RetryAfter time.Duration
+}
+
+// ValidationError is returned for 4xx responses rejecting the request, other than the ones above
+type ValidationError struct{ APIError }
+
+// ServerError is returned for 5xx responses
+type ServerError struct{ APIError }
+
+// Unwrap returns the underlying APIError
+func (e *AuthError) Unwrap() error { return &e.APIError }
+
+// Unwrap returns the underlying APIError
+func (e *PermissionError) Unwrap() error { return &e.APIError }
+
+// Unwrap returns the underlying APIError
+func (e *NotFoundError) Unwrap() error { return &e.APIError }
+
+// Unwrap returns the underlying APIError
+func (e *RateLimitError) Unwrap() error { return &e.APIError }
+
+// Unwrap returns the underlying APIError
+func (e *ValidationError) Unwrap() error { return &e.APIError }
+
+// Unwrap returns the underlying APIError
+func (e *ServerError) Unwrap() error { return &e.APIError }
+
+// ErrTempoRequired is returned by methods only Tempo supports, when the client has no Tempo token
+var ErrTempoRequired error = &TempooError{Message: "This operation needs a Tempo API token, set with WithTempoToken"}
+
+// newError converts an error of the internal services to the errors of this package, keeping its cause
+func newError(err error) error {
+	var (
+		authErr       *internal.AuthError
+		permissionErr *internal.PermissionError
+		notFoundErr   *internal.NotFoundError
+		rateLimitErr  *internal.RateLimitError
+		validationErr *internal.ValidationError
+		serverErr     *internal.ServerError
+		apiErr        *internal.APIError
+		invalidKeyErr *internal.InvalidIssueKeyError
+		tempooErr     *internal.TempooError
+	)
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &authErr):
+		return &AuthError{newAPIError(&authErr.APIError)}
+	case errors.As(err, &permissionErr):
+		return &PermissionError{newAPIError(&permissionErr.APIError)}
+	case errors.As(err, &notFoundErr):
+		return &NotFoundError{newAPIError(&notFoundErr.APIError)}
+	case errors.As(err, &rateLimitErr):
+		return &RateLimitError{APIError: newAPIError(&rateLimitErr.APIError), RetryAfter: rateLimitErr.RetryAfter}
+	case errors.As(err, &validationErr):
+		return &ValidationError{newAPIError(&validationErr.APIError)}
+	case errors.As(err, &serverErr):
+		return &ServerError{newAPIError(&serverErr.APIError)}
+	case errors.As(err, &apiErr):
+		converted := newAPIError(apiErr)
+		return &converted
+	case errors.As(err, &invalidKeyErr):
+		return &InvalidIssueKeyError{IssueKey: invalidKeyErr.IssueKey}
+	case errors.As(err, &tempooErr):
+		return &TempooError{Message: tempooErr.Message, Cause: tempooErr.Cause}
+	}
+	return &TempooError{Message: err.Error()}
+}
+
+// newAPIError converts a failed API call of the internal services
+func newAPIError(e *internal.APIError) APIError {
+	return APIError{
+		TempooError:   TempooError{Message: e.Message, Cause: e.Cause},
+		StatusCode:    e.StatusCode,
+		ErrorMessages: e.ErrorMessages,
+		FieldErrors:   e.FieldErrors,
+		Hint:          e.Hint,
+	}
+}
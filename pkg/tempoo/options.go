@@ -0,0 +1,77 @@
+package tempoo
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"tempoo/internal"
+
+	"github.com/apex/log"
+)
+
+// options collects the settings applied by Option values
+type options struct {
+	baseURL      string
+	email        string
+	apiToken     string
+	tempoToken   string
+	tempoBaseURL string
+	httpClient   *http.Client
+	logger       log.Interface
+	timeout      time.Duration
+}
+
+// Option configures a Client
+type Option func(*options)
+
+// WithBaseURL sets the URL of the Jira site, such as https://example.atlassian.net
+func WithBaseURL(url string) Option {
+	return func(o *options) {
+		o.baseURL = strings.TrimSuffix(url, "/") + internal.JiraAPIPath
+	}
+}
+
+// WithBasicAuth authenticates to Jira with an account's email and API token. It is required.
+func WithBasicAuth(email, apiToken string) Option {
+	return func(o *options) {
+		o.email = email
+		o.apiToken = apiToken
+	}
+}
+
+// WithTempoToken makes the client write worklogs to Tempo, authenticating with a Tempo API token
+func WithTempoToken(token string) Option {
+	return func(o *options) {
+		o.tempoToken = token
+	}
+}
+
+// WithTempoBaseURL sets the root URL of the Tempo REST API, https://api.tempo.io/4 by default
+func WithTempoBaseURL(url string) Option {
+	return func(o *options) {
+		o.tempoBaseURL = url
+	}
+}
+
+// WithHTTPClient sends requests through httpClient instead of a new client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithLogger sends the client's log messages to logger instead of the global apex/log logger
+func WithLogger(logger log.Interface) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithTimeout bounds how long each request may take, 10 seconds by default.
+// Deadlines on the contexts passed to the client's methods apply as well.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
@@ -0,0 +1,144 @@
+package tempoo
+
+import (
+	"context"
+	"time"
+
+	"tempoo/internal"
+)
+
+// Client logs work to Jira issues, in Jira or in Tempo. It is safe for concurrent use.
+type Client struct {
+	service internal.WorklogService
+	// tempo is the service when the client writes to Tempo, and nil otherwise
+	tempo *internal.TempoClient
+}
+
+// New creates a client. WithBasicAuth is required; everything else has defaults.
+func New(opts ...Option) (*Client, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	jira, err := internal.NewClient(internal.ClientOptions{
+		BaseURL:    o.baseURL,
+		Email:      o.email,
+		APIToken:   o.apiToken,
+		HTTPClient: o.httpClient,
+		Logger:     o.logger,
+		Timeout:    o.timeout,
+	})
+	if err != nil {
+		return nil, newError(err)
+	}
+	if o.tempoToken == "" {
+		return newClient(jira), nil
+	}
+
+	tempo, err := internal.NewTempoClientWithOptions(jira, internal.TempoOptions{
+		BaseURL:    o.tempoBaseURL,
+		APIToken:   o.tempoToken,
+		HTTPClient: o.httpClient,
+		Logger:     o.logger,
+		Timeout:    o.timeout,
+	})
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newClient(tempo), nil
+}
+
+// newClient creates a client sending worklogs to service
+func newClient(service internal.WorklogService) *Client {
+	tempo, _ := service.(*internal.TempoClient)
+	return &Client{service: service, tempo: tempo}
+}
+
+// WhoAmI returns the user the client is authenticated as
+func (c *Client) WhoAmI(ctx context.Context) (*User, error) {
+	user, err := c.service.WhoAmI(ctx)
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newUser(user), nil
+}
+
+// SearchIssues returns up to max issues matching a JQL query
+func (c *Client) SearchIssues(ctx context.Context, jql string, max int) (Issues, error) {
+	issues, err := c.service.SearchIssues(ctx, jql, max)
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newIssues(issues), nil
+}
+
+// AddWorklog logs a duration of at least a minute to an issue, starting at the given time
+func (c *Client) AddWorklog(ctx context.Context, issueKey string, started time.Time, duration time.Duration) (*Worklog, error) {
+	worklog, err := c.service.AddWorklogAt(ctx, issueKey, started, duration)
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newWorklog(worklog), nil
+}
+
+// ListWorklogs returns the current user's worklogs on an issue
+func (c *Client) ListWorklogs(ctx context.Context, issueKey string) (Worklogs, error) {
+	worklogs, err := c.service.ListWorklogs(ctx, issueKey)
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newWorklogs(worklogs), nil
+}
+
+// UpdateWorklog changes the duration and/or day of a worklog. A zero duration or date leaves
+// that value as it is, and a new date keeps the original start time of day.
+func (c *Client) UpdateWorklog(ctx context.Context, issueKey, worklogID string, duration time.Duration, date time.Time) (*Worklog, error) {
+	worklog, err := c.service.UpdateWorklogDuration(ctx, issueKey, worklogID, duration, date)
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newWorklog(worklog), nil
+}
+
+// DeleteWorklog deletes a worklog from an issue
+func (c *Client) DeleteWorklog(ctx context.Context, issueKey, worklogID string) error {
+	return newError(c.service.DeleteWorklog(ctx, issueKey, worklogID))
+}
+
+// Timesheet returns the current user's Tempo timesheet for the approval period containing date
+func (c *Client) Timesheet(ctx context.Context, date time.Time) (*Timesheet, error) {
+	if c.tempo == nil {
+		return nil, ErrTempoRequired
+	}
+	timesheet, err := c.tempo.Timesheet(ctx, date)
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newTimesheet(timesheet), nil
+}
+
+// SubmitTimesheet submits the current user's Tempo timesheet for the period containing date for
+// approval. Without a reviewer account ID, Tempo routes it to the reviewer already assigned.
+func (c *Client) SubmitTimesheet(ctx context.Context, date time.Time, comment, reviewerAccountID string) (*Timesheet, error) {
+	if c.tempo == nil {
+		return nil, ErrTempoRequired
+	}
+	timesheet, err := c.tempo.SubmitTimesheet(ctx, date, comment, reviewerAccountID)
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newTimesheet(timesheet), nil
+}
+
+// ReopenTimesheet reopens the current user's submitted or approved Tempo timesheet for the period containing date
+func (c *Client) ReopenTimesheet(ctx context.Context, date time.Time, comment string) (*Timesheet, error) {
+	if c.tempo == nil {
+		return nil, ErrTempoRequired
+	}
+	timesheet, err := c.tempo.ReopenTimesheet(ctx, date, comment)
+	if err != nil {
+		return nil, newError(err)
+	}
+	return newTimesheet(timesheet), nil
+}
@@ -0,0 +1,205 @@
+package tempoo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tempoo/internal/fakejira"
+)
+
+// newTestServer serves handler and returns its URL, closed when the test ends
+func newTestServer(t *testing.T, handler http.Handler) string {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestNew_RequiresBasicAuth(t *testing.T) {
+	_, err := New(WithBaseURL("https://example.atlassian.net"))
+	var tempooErr *TempooError
+	if !errors.As(err, &tempooErr) {
+		t.Fatalf("New() error = %v, want a *TempooError", err)
+	}
+}
+
+func TestClient_Jira(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/3/myself", func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "me@example.com" || token != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"accountId": "user-1", "displayName": "Test User"})
+	})
+	mux.HandleFunc("GET /rest/api/3/issue/TEST-1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"10001","key":"TEST-1"}`))
+	})
+	mux.HandleFunc("GET /rest/api/3/issue/TEST-1/worklog", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"worklogs":[
+			{"id":"1","author":{"accountId":"user-1","displayName":"Test User"},"started":"2025-07-01T08:30:00.000+0000","timeSpent":"1h","timeSpentSeconds":3600},
+			{"id":"2","author":{"accountId":"user-2","displayName":"Someone Else"},"started":"2025-07-01T09:30:00.000+0000","timeSpent":"2h","timeSpentSeconds":7200}
+		]}`))
+	})
+
+	client, err := New(
+		WithBaseURL(newTestServer(t, mux)+"/"),
+		WithBasicAuth("me@example.com", "secret"),
+		WithTimeout(time.Second),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	user, err := client.WhoAmI(context.Background())
+	if err != nil || user.AccountID != "user-1" || user.DisplayName != "Test User" {
+		t.Errorf("WhoAmI() = %+v, %v", user, err)
+	}
+
+	worklogs, err := client.ListWorklogs(context.Background(), "TEST-1")
+	if err != nil || len(worklogs) != 1 || worklogs[0].ID != "1" || worklogs[0].TimeSpentSeconds != 3600 {
+		t.Errorf("ListWorklogs() = %+v, %v", worklogs, err)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/3/myself", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	client, err := New(WithBaseURL(newTestServer(t, mux)), WithBasicAuth("me@example.com", "wrong"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = client.WhoAmI(context.Background())
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || authErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("WhoAmI() error = %v, want an *AuthError", err)
+	}
+
+	_, err = client.Timesheet(context.Background(), time.Now())
+	if !errors.Is(err, ErrTempoRequired) {
+		t.Errorf("Timesheet() error = %v, want ErrTempoRequired", err)
+	}
+}
+
+func TestClient_ContextCanceled(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/3/myself", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request with a canceled context")
+	})
+	client, err := New(WithBaseURL(newTestServer(t, mux)), WithBasicAuth("me@example.com", "secret"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.WhoAmI(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WhoAmI() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestClient_Tempo(t *testing.T) {
+	var myself, workAttributes atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/3/myself", func(w http.ResponseWriter, r *http.Request) {
+		myself.Add(1)
+		json.NewEncoder(w).Encode(map[string]string{"accountId": "user-1"})
+	})
+	mux.HandleFunc("GET /rest/api/3/issue/TEST-1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"10001","key":"TEST-1","fields":{}}`))
+	})
+	mux.HandleFunc("GET /rest/api/3/field", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /tempo/work-attributes", func(w http.ResponseWriter, r *http.Request) {
+		workAttributes.Add(1)
+		w.Write([]byte(`{"results":[]}`))
+	})
+	mux.HandleFunc("POST /tempo/worklogs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tempo-token" {
+			t.Errorf("Authorization = %q, want the Tempo token", r.Header.Get("Authorization"))
+		}
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tempoWorklogId":   42,
+			"issue":            map[string]interface{}{"id": payload["issueId"]},
+			"timeSpentSeconds": payload["timeSpentSeconds"],
+			"startDate":        payload["startDate"],
+			"startTime":        payload["startTime"],
+			"author":           map[string]interface{}{"accountId": payload["authorAccountId"]},
+		})
+	})
+	url := newTestServer(t, mux)
+
+	client, err := New(
+		WithBaseURL(url),
+		WithBasicAuth("me@example.com", "secret"),
+		WithTempoToken("tempo-token"),
+		WithTempoBaseURL(url+"/tempo"),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	started := time.Date(2025, 7, 1, 9, 0, 0, 0, time.Local)
+	worklog, err := client.AddWorklog(context.Background(), "TEST-1", started, 90*time.Minute)
+	if err != nil {
+		t.Fatalf("AddWorklog() error = %v", err)
+	}
+	if worklog.ID != "42" || worklog.TimeSpentSeconds != 5400 || !worklog.Started.Equal(started) {
+		t.Errorf("AddWorklog() = %+v", worklog)
+	}
+
+	// the current user and the work attributes are looked up once per client
+	if _, err := client.AddWorklog(context.Background(), "TEST-1", started, time.Hour); err != nil {
+		t.Fatalf("AddWorklog() error = %v", err)
+	}
+	if myself.Load() != 1 || workAttributes.Load() != 1 {
+		t.Errorf("Looked up the user %d and the work attributes %d times, want once each", myself.Load(), workAttributes.Load())
+	}
+}
+
+func TestClient_Worklogs(t *testing.T) {
+	server := fakejira.New()
+	t.Cleanup(server.Close)
+	server.AddUser(fakejira.User{AccountID: "user-1", Email: "me@example.com", APIToken: "secret"})
+	server.AddIssue(fakejira.Issue{Key: "TEST-1"})
+
+	client, err := New(WithBaseURL(server.URL), WithBasicAuth("me@example.com", "secret"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	added, err := client.AddWorklog(ctx, "TEST-1", time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC), time.Hour)
+	if err != nil {
+		t.Fatalf("AddWorklog() error = %v", err)
+	}
+
+	// durations are not limited to the CLI's half hours up to a working day
+	for _, duration := range []time.Duration{45 * time.Minute, 9 * time.Hour} {
+		updated, err := client.UpdateWorklog(ctx, "TEST-1", added.ID, duration, time.Time{})
+		if err != nil || updated.TimeSpentSeconds != int(duration.Seconds()) {
+			t.Errorf("UpdateWorklog(%s) = %+v, %v", duration, updated, err)
+		}
+	}
+
+	var notFound *NotFoundError
+	if err := client.DeleteWorklog(ctx, "TEST-1", "404"); !errors.As(err, &notFound) || notFound.StatusCode != http.StatusNotFound {
+		t.Errorf("DeleteWorklog() error = %v, want a *NotFoundError", err)
+	}
+	var invalidKey *InvalidIssueKeyError
+	if _, err := client.ListWorklogs(ctx, "NOPE-1"); !errors.As(err, &invalidKey) || invalidKey.IssueKey != "NOPE-1" {
+		t.Errorf("ListWorklogs() error = %v, want an *InvalidIssueKeyError", err)
+	}
+}
@@ -0,0 +1,121 @@
+package tempoo
+
+import (
+	"time"
+
+	"tempoo/internal"
+)
+
+// Worklog is a worklog entry on a Jira issue
+type Worklog struct {
+	ID              string
+	IssueKey        string
+	AuthorAccountID string
+	AuthorName      string
+	Started         time.Time
+	// TimeSpent is the logged time as Jira formats it, such as "1h 30m"
+	TimeSpent        string
+	TimeSpentSeconds int
+	Comment          string
+}
+
+// Worklogs is a list of worklogs
+type Worklogs []Worklog
+
+// User is a Jira user
+type User struct {
+	AccountID   string
+	DisplayName string
+	Email       string
+	// TimeZone is the user's IANA time zone, such as Europe/Berlin, if Jira shares it
+	TimeZone string
+}
+
+// Issue is a Jira issue found by a search
+type Issue struct {
+	Key     string
+	Summary string
+	Status  string
+}
+
+// Issues is a list of issues
+type Issues []Issue
+
+// Timesheet is the approval state of a user's Tempo timesheet for one approval period
+type Timesheet struct {
+	// From and To are the first and last day of the period, as YYYY-MM-DD
+	From string
+	To   string
+	// Status is open, in_review or approved
+	Status          string
+	RequiredSeconds int
+	LoggedSeconds   int
+	// ReviewerAccountID and Reviewer identify who approves the timesheet, if anyone is assigned
+	ReviewerAccountID string
+	Reviewer          string
+}
+
+// newWorklog converts a worklog of the internal services
+func newWorklog(w *internal.Worklog) *Worklog {
+	if w == nil {
+		return nil
+	}
+	return &Worklog{
+		ID:               w.ID,
+		IssueKey:         w.IssueKey,
+		AuthorAccountID:  w.AuthorAccountID,
+		AuthorName:       w.AuthorName,
+		Started:          w.Started,
+		TimeSpent:        w.TimeSpent,
+		TimeSpentSeconds: w.TimeSpentSeconds,
+		Comment:          w.Comment,
+	}
+}
+
+// newWorklogs converts a list of worklogs of the internal services
+func newWorklogs(worklogs internal.Worklogs) Worklogs {
+	if worklogs == nil {
+		return nil
+	}
+	converted := make(Worklogs, len(worklogs))
+	for i := range worklogs {
+		converted[i] = *newWorklog(&worklogs[i])
+	}
+	return converted
+}
+
+// newUser converts a user of the internal services
+func newUser(u *internal.User) *User {
+	if u == nil {
+		return nil
+	}
+	return &User{AccountID: u.AccountID, DisplayName: u.DisplayName, Email: u.Email, TimeZone: u.TimeZone}
+}
+
+// newIssues converts a list of issues of the internal services
+func newIssues(issues internal.Issues) Issues {
+	if issues == nil {
+		return nil
+	}
+	converted := make(Issues, len(issues))
+	for i, issue := range issues {
+		converted[i] = Issue{Key: issue.Key, Summary: issue.Summary, Status: issue.Status}
+	}
+	return converted
+}
+
+// newTimesheet converts a timesheet of the internal Tempo client
+func newTimesheet(t *internal.Timesheet) *Timesheet {
+	if t == nil {
+		return nil
+	}
+	return &Timesheet{
+		From:              t.From,
+		To:                t.To,
+		Status:            t.Status,
+		RequiredSeconds:   t.RequiredSeconds,
+		LoggedSeconds:     t.LoggedSeconds,
+		ReviewerAccountID: t.ReviewerAccountID,
+		Reviewer:          t.Reviewer,
+	}
+}